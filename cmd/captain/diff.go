@@ -0,0 +1,79 @@
+package main
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rwx-research/captain-cli/internal/cli"
+	"github.com/rwx-research/captain-cli/internal/errors"
+)
+
+type diffArgs struct {
+	format             string
+	identityComponents []string
+	slowdownFactor     float64
+	slowdownThreshold  time.Duration
+}
+
+func configureDiffCmd(rootCmd *cobra.Command, cliArgs *CliArgs) {
+	var dArgs diffArgs
+
+	diffCmd := &cobra.Command{
+		Use:   "diff [flags] <base-test-results> <head-test-results>",
+		Short: "Compares the test results of two runs",
+		Long: "'captain diff' parses the test results of two runs and reports the tests that are newly failing, newly " +
+			"passing, newly skipped, added, removed or significantly slower in the second one. Both arguments can be " +
+			"globs matching several test results files.",
+		Example: `  captain diff nightly/rspec.json rspec.json
+  captain diff --format markdown "nightly/**/*.xml" "**/*.xml"`,
+		Args:    cobra.ExactArgs(2),
+		PreRunE: unsafeInitParsingOnly(cliArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			args := cliArgs.RootCliArgs.positionalArgs
+
+			captain, err := cli.GetService(cmd)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			err = captain.Diff(cmd.Context(), cli.DiffConfig{
+				BaseTestResultsFileGlob: args[0],
+				HeadTestResultsFileGlob: args[1],
+				Format:                  dArgs.format,
+				IdentityComponents:      dArgs.identityComponents,
+				SlowdownFactor:          dArgs.slowdownFactor,
+				SlowdownThreshold:       dArgs.slowdownThreshold,
+			})
+			if _, ok := errors.AsConfigurationError(err); !ok {
+				cmd.SilenceUsage = true
+			}
+
+			return errors.WithStack(err)
+		},
+	}
+
+	diffCmd.Flags().StringVar(&dArgs.format, "format", "text", "the format of the diff: 'text' or 'markdown'")
+	diffCmd.Flags().StringSliceVar(
+		&dArgs.identityComponents,
+		"identity",
+		nil,
+		"the components that identify a test across runs, e.g. 'description,file'.\n"+
+			"By default, tests need to have the same ID, name, lineage and location.",
+	)
+	diffCmd.Flags().Float64Var(
+		&dArgs.slowdownFactor,
+		"slowdown-factor",
+		2,
+		"how many times as long a test needs to take to be reported as slower",
+	)
+	diffCmd.Flags().DurationVar(
+		&dArgs.slowdownThreshold,
+		"slowdown-threshold",
+		time.Second,
+		"how much longer a test needs to take to be reported as slower",
+	)
+	addFrameworkFlags(diffCmd, &cliArgs.frameworkParams)
+
+	rootCmd.AddCommand(diffCmd)
+}
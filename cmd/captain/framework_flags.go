@@ -10,8 +10,10 @@ import (
 )
 
 type frameworkParams struct {
-	kind     string
-	language string
+	kind                    string
+	language                string
+	bazelTestlogs           string
+	bazelBuildEventJSONFile string
 }
 
 func addFrameworkFlags(command *cobra.Command, frameworkParams *frameworkParams) {
@@ -40,6 +42,20 @@ func addFrameworkFlags(command *cobra.Command, frameworkParams *frameworkParams)
 			strings.Join(formattedKnownFrameworks, "\n"),
 		),
 	)
+	command.Flags().StringVar(
+		&frameworkParams.bazelTestlogs,
+		"bazel-testlogs",
+		"",
+		"The path to the Bazel test logs (usually 'bazel-testlogs'). If set, every test is attributed to the Bazel\n"+
+			"target that ran it. Test results default to all 'test.xml' files within the test logs.",
+	)
+	command.Flags().StringVar(
+		&frameworkParams.bazelBuildEventJSONFile,
+		"bazel-build-event-json-file",
+		"",
+		"The file written by Bazel's '--build_event_json_file' option. If set, the attempts of flaky targets\n"+
+			"(see '--flaky_test_attempts') are recovered from it (requires --bazel-testlogs).",
+	)
 }
 
 func bindFrameworkFlags(cfg Config, frameworkParams frameworkParams, suiteID string) Config {
@@ -52,6 +68,14 @@ func bindFrameworkFlags(cfg Config, frameworkParams frameworkParams, suiteID str
 			suiteConfig.Results.Language = frameworkParams.language
 		}
 
+		if frameworkParams.bazelTestlogs != "" {
+			suiteConfig.Results.Bazel.Testlogs = frameworkParams.bazelTestlogs
+		}
+
+		if frameworkParams.bazelBuildEventJSONFile != "" {
+			suiteConfig.Results.Bazel.BuildEventJSONFile = frameworkParams.bazelBuildEventJSONFile
+		}
+
 		cfg.TestSuites[suiteID] = suiteConfig
 	}
 
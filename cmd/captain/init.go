@@ -1,9 +1,12 @@
 package main
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
 
+	"github.com/mattn/go-shellwords"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 
@@ -21,9 +24,12 @@ import (
 )
 
 var mutuallyExclusiveParsers []parsing.Parser = []parsing.Parser{
+	parsing.CppGoogleTestParser{},
+	parsing.DartTestParser{},
 	parsing.DotNetxUnitParser{},
 	parsing.GoGinkgoParser{},
 	parsing.GoTestParser{},
+	parsing.JavaJUnitParser{},
 	parsing.JavaScriptCypressParser{},
 	parsing.JavaScriptJestParser{},
 	parsing.JavaScriptVitestParser{}, // Vitest MUST be after Jest as Jest _looks like_ a superset of Vitest
@@ -31,14 +37,20 @@ var mutuallyExclusiveParsers []parsing.Parser = []parsing.Parser{
 	parsing.JavaScriptMochaParser{},
 	parsing.JavaScriptPlaywrightParser{},
 	parsing.PythonPytestParser{},
+	parsing.PythonPytestJUnitParser{},
 	parsing.RubyRSpecParser{},
+	parsing.RustNextestParser{},
+	parsing.SwiftXCTestParser{},
 }
 
 var frameworkParsers map[v1.Framework][]parsing.Parser = map[v1.Framework][]parsing.Parser{
+	v1.CppGoogleTestFramework:        {parsing.CppGoogleTestParser{}},
+	v1.DartTestFramework:             {parsing.DartTestParser{}},
 	v1.DotNetxUnitFramework:          {parsing.DotNetxUnitParser{}},
 	v1.ElixirExUnitFramework:         {parsing.ElixirExUnitParser{}},
 	v1.GoGinkgoFramework:             {parsing.GoGinkgoParser{}},
 	v1.GoTestFramework:               {parsing.GoTestParser{}},
+	v1.JavaJUnitFramework:            {parsing.JavaJUnitParser{}},
 	v1.JavaScriptCucumberFramework:   {parsing.JavaScriptCucumberJSONParser{}},
 	v1.JavaScriptCypressFramework:    {parsing.JavaScriptCypressParser{}},
 	v1.JavaScriptJestFramework:       {parsing.JavaScriptJestParser{}},
@@ -47,17 +59,66 @@ var frameworkParsers map[v1.Framework][]parsing.Parser = map[v1.Framework][]pars
 	v1.JavaScriptPlaywrightFramework: {parsing.JavaScriptPlaywrightParser{}},
 	v1.JavaScriptVitestFramework:     {parsing.JavaScriptVitestParser{}},
 	v1.PHPUnitFramework:              {parsing.PHPUnitParser{}},
-	v1.PythonPytestFramework:         {parsing.PythonPytestParser{}},
+	v1.PythonPytestFramework:         {parsing.PythonPytestParser{}, parsing.PythonPytestJUnitParser{AssumePytest: true}},
 	v1.PythonUnitTestFramework:       {parsing.PythonUnitTestParser{}},
 	v1.RubyCucumberFramework:         {parsing.RubyCucumberParser{}},
 	v1.RubyMinitestFramework:         {parsing.RubyMinitestParser{}},
 	v1.RubyRSpecFramework:            {parsing.RubyRSpecParser{}},
+	v1.RustNextestFramework:          {parsing.RustNextestParser{}},
+	v1.SwiftXCTestFramework:          {parsing.SwiftXCTestParser{}},
 }
 
 var genericParsers []parsing.Parser = []parsing.Parser{
 	parsing.RWXParser{},
+	parsing.CTRFParser{},
 	parsing.JUnitTestsuitesParser{},
 	parsing.JUnitTestsuiteParser{},
+	parsing.TAPParser{},
+}
+
+// genericParsersFor puts the external parsers of a test suite in front of the built-in generic parsers. External
+// parsers are executables that read a test results file on stdin and print RWX v1 JSON. Their probe decides which files
+// they read.
+func genericParsersFor(suiteConfig cli.SuiteConfig) ([]parsing.Parser, error) {
+	parsers := make([]parsing.Parser, 0, len(suiteConfig.Results.Parsers)+len(genericParsers))
+
+	for _, parserConfig := range suiteConfig.Results.Parsers {
+		command, err := shellwords.Parse(os.ExpandEnv(parserConfig.Command))
+		if err != nil || len(command) == 0 {
+			return nil, errors.NewConfigurationError(
+				fmt.Sprintf("Invalid command for parser %q", parserConfig.Name),
+				fmt.Sprintf("Captain is unable to parse %q into shell arguments.", parserConfig.Command),
+				"External parsers need a command that reads a test results file on stdin and prints RWX v1 JSON to "+
+					"stdout.",
+			)
+		}
+
+		probeCommand, err := shellwords.Parse(os.ExpandEnv(parserConfig.Probe))
+		if err != nil || len(probeCommand) == 0 {
+			return nil, errors.NewConfigurationError(
+				fmt.Sprintf("Invalid probe for parser %q", parserConfig.Name),
+				fmt.Sprintf("Captain is unable to parse %q into shell arguments.", parserConfig.Probe),
+				"External parsers need a probe so that they are only run for the files they are able to parse. It "+
+					"receives the start of a test results file on stdin and should exit successfully if the parser is "+
+					"able to parse that file.",
+			)
+		}
+
+		name := parserConfig.Name
+		if name == "" {
+			name = command[0]
+		}
+
+		parsers = append(parsers, parsing.ExternalParser{
+			Name:         name,
+			Command:      command,
+			ProbeCommand: probeCommand,
+			TaskRunner:   exec.Local{},
+			Timeout:      parserConfig.Timeout,
+		})
+	}
+
+	return append(parsers, genericParsers...), nil
 }
 
 var invalidSuiteIDRegexp = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
@@ -111,12 +172,19 @@ func initCliServiceWithConfig(
 
 		var parseConfig parsing.Config
 		if suiteConfig, ok := cfg.TestSuites[suiteID]; ok {
+			suiteGenericParsers, err := genericParsersFor(suiteConfig)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
 			parseConfig = parsing.Config{
 				ProvidedFrameworkKind:     suiteConfig.Results.Framework,
 				ProvidedFrameworkLanguage: suiteConfig.Results.Language,
 				MutuallyExclusiveParsers:  mutuallyExclusiveParsers,
 				FrameworkParsers:          frameworkParsers,
-				GenericParsers:            genericParsers,
+				BazelTestlogsPath:         os.ExpandEnv(suiteConfig.Results.Bazel.Testlogs),
+				BazelBuildEventJSONPath:   os.ExpandEnv(suiteConfig.Results.Bazel.BuildEventJSONFile),
+				GenericParsers:            suiteGenericParsers,
 				Logger:                    logger,
 			}
 		}
@@ -169,12 +237,19 @@ func unsafeInitParsingOnly(cliArgs *CliArgs) func(*cobra.Command, []string) erro
 
 			var parseConfig parsing.Config
 			if suiteConfig, ok := cfg.TestSuites[cliArgs.RootCliArgs.suiteID]; ok {
+				suiteGenericParsers, err := genericParsersFor(suiteConfig)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+
 				parseConfig = parsing.Config{
 					ProvidedFrameworkKind:     suiteConfig.Results.Framework,
 					ProvidedFrameworkLanguage: suiteConfig.Results.Language,
 					MutuallyExclusiveParsers:  mutuallyExclusiveParsers,
 					FrameworkParsers:          frameworkParsers,
-					GenericParsers:            genericParsers,
+					BazelTestlogsPath:         os.ExpandEnv(suiteConfig.Results.Bazel.Testlogs),
+					BazelBuildEventJSONPath:   os.ExpandEnv(suiteConfig.Results.Bazel.BuildEventJSONFile),
+					GenericParsers:            suiteGenericParsers,
 					Logger:                    logger,
 				}
 			}
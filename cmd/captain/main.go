@@ -34,6 +34,9 @@ func main() {
 	// quarantine
 	AddQuarantineFlags(rootCmd, &cliArgs)
 
+	// diff
+	configureDiffCmd(rootCmd, &cliArgs)
+
 	// parse
 	if err := configureParseCmd(rootCmd, &cliArgs); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -66,6 +69,9 @@ func main() {
 		os.Exit(1)
 	}
 
+	// validate
+	configureValidateCmd(rootCmd, &cliArgs)
+
 	// Logging is expected to take place in `internal/cli`, as text output is the primary way of communicating
 	// to a user on the terminal and is therefore one of our main concerns.
 	// This error here is mainly used to communicate any necessary exit Code.
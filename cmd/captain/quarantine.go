@@ -42,8 +42,12 @@ func AddQuarantineFlags(rootCmd *cobra.Command, cliArgs *CliArgs) {
 						switch name {
 						case "rwx-v1-json":
 							reporterFuncs[path] = reporting.WriteJSONSummary
+						case "rwx-v2-json":
+							reporterFuncs[path] = reporting.WriteV2JSONSummary
 						case "junit-xml":
 							reporterFuncs[path] = reporting.WriteJUnitSummary
+						case "ctrf-json":
+							reporterFuncs[path] = reporting.WriteCTRFSummary
 						case "markdown-summary":
 							reporterFuncs[path] = reporting.WriteMarkdownSummary
 						case "github-step-summary":
@@ -60,7 +64,8 @@ func AddQuarantineFlags(rootCmd *cobra.Command, cliArgs *CliArgs) {
 						default:
 							return errors.NewConfigurationError(
 								fmt.Sprintf("Unknown reporter %q", name),
-								"Available reporters are 'rwx-v1-json', 'junit-xml', 'markdown-summary', and 'github-step-summary'.",
+								"Available reporters are 'rwx-v1-json', 'rwx-v2-json', 'junit-xml', 'ctrf-json', 'markdown-summary', "+
+									"and 'github-step-summary'.",
 								"",
 							)
 						}
@@ -133,7 +138,8 @@ func AddQuarantineFlags(rootCmd *cobra.Command, cliArgs *CliArgs) {
 		"reporter",
 		[]string{},
 		"one or more `type=output_path` pairs to enable different reporting options.\n"+
-			"Available reporters are 'rwx-v1-json', 'junit-xml', 'markdown-summary', and 'github-step-summary'.",
+			"Available reporters are 'rwx-v1-json', 'rwx-v2-json', 'junit-xml', 'ctrf-json', 'markdown-summary', and "+
+			"'github-step-summary'.",
 	)
 
 	quarantineCmd.Flags().BoolVar(
@@ -3,8 +3,10 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/rwx-research/captain-cli/internal/reporting"
 	"github.com/rwx-research/captain-cli/internal/runpartition"
 	"github.com/rwx-research/captain-cli/internal/targetedretries"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
 )
 
 type CliArgs struct {
@@ -24,6 +27,7 @@ type CliArgs struct {
 	failRetriesFast           bool
 	flakyRetries              int
 	intermediateArtifactsPath string
+	isolateRetries            bool
 	maxTestsToRetry           string
 	postRetryCommands         []string
 	preRetryCommands          []string
@@ -31,7 +35,16 @@ type CliArgs struct {
 	quiet                     bool
 	reporters                 []string
 	Retries                   int
+	retryBackoff              string
+	retryBackoffDelay         time.Duration
+	retryBackoffJitter        float64
+	retryBackoffMaxDelay      time.Duration
 	retryCommandTemplate      string
+	retryCommandTimeout       time.Duration
+	retryParallelism          int
+	sharedProcessGroup        bool
+	timeout                   time.Duration
+	timeoutGracePeriod        time.Duration
 	updateStoredResults       bool
 	GenericProvider           providers.GenericEnv
 	frameworkParams           frameworkParams
@@ -73,8 +86,12 @@ func createRunCmd(cliArgs *CliArgs) *cobra.Command {
 						switch name {
 						case "rwx-v1-json":
 							reporterFuncs[path] = reporting.WriteJSONSummary
+						case "rwx-v2-json":
+							reporterFuncs[path] = reporting.WriteV2JSONSummary
 						case "junit-xml":
 							reporterFuncs[path] = reporting.WriteJUnitSummary
+						case "ctrf-json":
+							reporterFuncs[path] = reporting.WriteCTRFSummary
 						case "markdown-summary":
 							reporterFuncs[path] = reporting.WriteMarkdownSummary
 						case "github-step-summary":
@@ -91,7 +108,8 @@ func createRunCmd(cliArgs *CliArgs) *cobra.Command {
 						default:
 							return errors.NewConfigurationError(
 								fmt.Sprintf("Unknown reporter %q", name),
-								"Available reporters are 'rwx-v1-json', 'junit-xml', 'markdown-summary', and 'github-step-summary'.",
+								"Available reporters are 'rwx-v1-json', 'rwx-v2-json', 'junit-xml', 'ctrf-json', 'markdown-summary', "+
+									"and 'github-step-summary'.",
 								"",
 							)
 						}
@@ -112,27 +130,53 @@ func createRunCmd(cliArgs *CliArgs) *cobra.Command {
 						partitionTotal = provider.PartitionNodes.Total
 					}
 
+					// Bazel writes one test.xml per target (and shard)
+					testResultsFileGlob := expandEnvExceptRetryCommandID(suiteConfig.Results.Path)
+					if testResultsFileGlob == "" && suiteConfig.Results.Bazel.Testlogs != "" {
+						testResultsFileGlob = filepath.Join(os.ExpandEnv(suiteConfig.Results.Bazel.Testlogs), "**", "test.xml")
+					}
+
+					retryCommandTemplatesByFramework, err := retryCommandTemplatesByFramework(
+						suiteConfig.Retries.FrameworkCommands,
+					)
+					if err != nil {
+						return errors.WithStack(err)
+					}
+
 					runConfig = cli.RunConfig{
-						Args:                      args,
-						Command:                   suiteConfig.Command,
-						FailOnUploadError:         suiteConfig.FailOnUploadError,
-						FailRetriesFast:           suiteConfig.Retries.FailFast,
-						FlakyRetries:              suiteConfig.Retries.FlakyAttempts,
-						IntermediateArtifactsPath: suiteConfig.Retries.IntermediateArtifactsPath,
-						MaxTestsToRetry:           suiteConfig.Retries.MaxTests,
-						PostRetryCommands:         suiteConfig.Retries.PostRetryCommands,
-						PreRetryCommands:          suiteConfig.Retries.PreRetryCommands,
-						PrintSummary:              suiteConfig.Output.PrintSummary,
-						Quiet:                     suiteConfig.Output.Quiet,
-						Reporters:                 reporterFuncs,
-						Retries:                   suiteConfig.Retries.Attempts,
-						RetryCommandTemplate:      suiteConfig.Retries.Command,
-						SubstitutionsByFramework:  targetedretries.SubstitutionsByFramework,
-						SuiteID:                   cliArgs.RootCliArgs.suiteID,
-						TestResultsFileGlob:       os.ExpandEnv(suiteConfig.Results.Path),
-						UpdateStoredResults:       cliArgs.updateStoredResults,
-						UploadResults:             true,
-						PartitionCommandTemplate:  suiteConfig.Partition.Command,
+						Args:                             args,
+						Command:                          suiteConfig.Command,
+						FailOnUploadError:                suiteConfig.FailOnUploadError,
+						FailRetriesFast:                  suiteConfig.Retries.FailFast,
+						FlakyRetries:                     suiteConfig.Retries.FlakyAttempts,
+						IntermediateArtifactsPath:        suiteConfig.Retries.IntermediateArtifactsPath,
+						IsolateRetries:                   suiteConfig.Retries.Isolate,
+						MaxTestsToRetry:                  suiteConfig.Retries.MaxTests,
+						PostRetryCommands:                suiteConfig.Retries.PostRetryCommands,
+						PreRetryCommands:                 suiteConfig.Retries.PreRetryCommands,
+						PrintSummary:                     suiteConfig.Output.PrintSummary,
+						Quiet:                            suiteConfig.Output.Quiet,
+						Reporters:                        reporterFuncs,
+						Retries:                          suiteConfig.Retries.Attempts,
+						RetryCommandTemplate:             suiteConfig.Retries.Command,
+						RetryCommandTemplatesByFramework: retryCommandTemplatesByFramework,
+						RetryCommandTimeout:              suiteConfig.Retries.CommandTimeout,
+						RetryParallelism:                 suiteConfig.Retries.Parallelism,
+						SubstitutionsByFramework:         targetedretries.SubstitutionsByFramework,
+						SharedProcessGroup:               suiteConfig.SharedProcessGroup,
+						SuiteID:                          cliArgs.RootCliArgs.suiteID,
+						TestResultsFileGlob:              testResultsFileGlob,
+						Timeout:                          suiteConfig.Timeout,
+						TimeoutGracePeriod:               suiteConfig.TimeoutGrace,
+						UpdateStoredResults:              cliArgs.updateStoredResults,
+						UploadResults:                    true,
+						PartitionCommandTemplate:         suiteConfig.Partition.Command,
+						RetryBackoff: cli.RetryBackoff{
+							Delay:    suiteConfig.Retries.Backoff.Delay,
+							Jitter:   suiteConfig.Retries.Backoff.Jitter,
+							MaxDelay: suiteConfig.Retries.Backoff.MaxDelay,
+							Strategy: suiteConfig.Retries.Backoff.Strategy,
+						},
 						PartitionConfig: cli.PartitionConfig{
 							SuiteID:       cliArgs.RootCliArgs.suiteID,
 							TestFilePaths: suiteConfig.Partition.Globs,
@@ -160,6 +204,55 @@ func createRunCmd(cliArgs *CliArgs) *cobra.Command {
 	}
 }
 
+// retryCommandTemplatesByFramework resolves the framework names used in the config file. Names are matched against
+// the kinds of the known frameworks, so "cucumber" applies to both Cucumber frameworks.
+func retryCommandTemplatesByFramework(frameworkCommands map[string]string) (map[v1.Framework]string, error) {
+	templatesByFramework := make(map[v1.Framework]string)
+
+	for name, command := range frameworkCommands {
+		found := false
+		for _, framework := range v1.KnownFrameworks {
+			if strings.EqualFold(string(framework.Kind), strings.TrimSpace(name)) {
+				templatesByFramework[framework] = command
+				found = true
+			}
+		}
+
+		if !found {
+			formattedKnownFrameworks := make([]string, 0, len(v1.KnownFrameworks))
+			seenKinds := make(map[v1.FrameworkKind]struct{})
+			for _, framework := range v1.KnownFrameworks {
+				if _, ok := seenKinds[framework.Kind]; ok {
+					continue
+				}
+
+				seenKinds[framework.Kind] = struct{}{}
+				formattedKnownFrameworks = append(formattedKnownFrameworks, fmt.Sprintf("'%v'", framework.Kind))
+			}
+
+			return nil, errors.NewConfigurationError(
+				fmt.Sprintf("Unknown framework %q", name),
+				"Captain is unable to match the retries' framework-commands to a framework it knows about.",
+				fmt.Sprintf("Available frameworks are %v.", strings.Join(formattedKnownFrameworks, ", ")),
+			)
+		}
+	}
+
+	return templatesByFramework, nil
+}
+
+// expandEnvExceptRetryCommandID expands environment variables like `os.ExpandEnv`, except for the retry command ID,
+// which is only known once a command runs.
+func expandEnvExceptRetryCommandID(s string) string {
+	return os.Expand(s, func(name string) string {
+		if name == cli.RetryCommandIDEnvVar {
+			return fmt.Sprintf("${%s}", name)
+		}
+
+		return os.Getenv(name)
+	})
+}
+
 func AddFlags(runCmd *cobra.Command, cliArgs *CliArgs) error {
 	runCmd.Flags().StringVarP(
 		&cliArgs.command,
@@ -216,7 +309,8 @@ func AddFlags(runCmd *cobra.Command, cliArgs *CliArgs) error {
 		"reporter",
 		[]string{},
 		"one or more `type=output_path` pairs to enable different reporting options.\n"+
-			"Available reporters are 'rwx-v1-json', 'junit-xml', 'markdown-summary', and 'github-step-summary'.",
+			"Available reporters are 'rwx-v1-json', 'rwx-v2-json', 'junit-xml', 'ctrf-json', 'markdown-summary', and "+
+			"'github-step-summary'.",
 	)
 
 	runCmd.Flags().IntVar(
@@ -254,6 +348,87 @@ func AddFlags(runCmd *cobra.Command, cliArgs *CliArgs) error {
 			"them)",
 	)
 
+	runCmd.Flags().BoolVar(
+		&cliArgs.isolateRetries,
+		"isolate-retries",
+		false,
+		"if set, every failed test is retried by a command of its own instead of together with the other failures. "+
+			"Use --retry-parallelism to run several of them at the same time.",
+	)
+
+	runCmd.Flags().IntVar(
+		&cliArgs.retryParallelism,
+		"retry-parallelism",
+		0,
+		"the number of retry commands to run at the same time, if a retry is split across several commands (e.g. one "+
+			"per Go package). The test results path needs to include ${"+cli.RetryCommandIDEnvVar+"} to keep their "+
+			"results apart.",
+	)
+
+	runCmd.Flags().StringVar(
+		&cliArgs.retryBackoff,
+		"retry-backoff",
+		"",
+		"how to wait before each retry, either 'fixed' (the default) or 'exponential' (doubling the delay every retry)",
+	)
+
+	runCmd.Flags().DurationVar(
+		&cliArgs.retryBackoffDelay,
+		"retry-backoff-delay",
+		0,
+		"how long to wait before the first retry (e.g. --retry-backoff-delay 30s)",
+	)
+
+	runCmd.Flags().DurationVar(
+		&cliArgs.retryBackoffMaxDelay,
+		"retry-backoff-max-delay",
+		0,
+		"the longest an exponential backoff waits before a retry",
+	)
+
+	runCmd.Flags().Float64Var(
+		&cliArgs.retryBackoffJitter,
+		"retry-backoff-jitter",
+		0,
+		"randomly waits up to this fraction of the delay more or less before each retry "+
+			"(e.g. --retry-backoff-jitter 0.2 for up to 20% more or less)",
+	)
+
+	runCmd.Flags().DurationVar(
+		&cliArgs.retryCommandTimeout,
+		"retry-command-timeout",
+		0,
+		"if set, retry commands are stopped (along with any processes they started) once they run longer than this. "+
+			"Tests that didn't finish are reported as timed out.",
+	)
+
+	runCmd.Flags().BoolVar(
+		&cliArgs.sharedProcessGroup,
+		"shared-process-group",
+		false,
+		"if set, the test suite and retry commands run in captain's process group instead of one of their own. This "+
+			"lets them use the terminal, but any processes they leave running aren't stopped when they exit, and "+
+			"captain doesn't forward the signals it receives to them.",
+	)
+
+	runCmd.Flags().DurationVar(
+		&cliArgs.timeout,
+		"timeout",
+		0,
+		"if set, captain stops the test suite once the original run and any retries took longer than this "+
+			"(e.g. --timeout 30m). Retried tests that didn't finish are reported as canceled, as are tests that the "+
+			"test results show as still running (e.g. go test, nextest or Dart JSON). Captain exits with code "+
+			fmt.Sprint(cli.RunTimeoutExitCode)+".",
+	)
+
+	runCmd.Flags().DurationVar(
+		&cliArgs.timeoutGracePeriod,
+		"timeout-grace-period",
+		0,
+		"how long the test suite has to shut down once a timeout is reached or captain is interrupted before it is "+
+			"killed (default 10s)",
+	)
+
 	runCmd.Flags().IntVar(
 		&cliArgs.partitionIndex,
 		"partition-index",
@@ -408,6 +583,46 @@ func bindRunCmdFlags(cfg Config, cliArgs CliArgs) Config {
 			suiteConfig.Retries.Command = cliArgs.retryCommandTemplate
 		}
 
+		if cliArgs.isolateRetries {
+			suiteConfig.Retries.Isolate = true
+		}
+
+		if cliArgs.sharedProcessGroup {
+			suiteConfig.SharedProcessGroup = true
+		}
+
+		if cliArgs.timeout != 0 {
+			suiteConfig.Timeout = cliArgs.timeout
+		}
+
+		if cliArgs.timeoutGracePeriod != 0 {
+			suiteConfig.TimeoutGrace = cliArgs.timeoutGracePeriod
+		}
+
+		if cliArgs.retryBackoff != "" {
+			suiteConfig.Retries.Backoff.Strategy = cliArgs.retryBackoff
+		}
+
+		if cliArgs.retryBackoffDelay != 0 {
+			suiteConfig.Retries.Backoff.Delay = cliArgs.retryBackoffDelay
+		}
+
+		if cliArgs.retryBackoffJitter != 0 {
+			suiteConfig.Retries.Backoff.Jitter = cliArgs.retryBackoffJitter
+		}
+
+		if cliArgs.retryBackoffMaxDelay != 0 {
+			suiteConfig.Retries.Backoff.MaxDelay = cliArgs.retryBackoffMaxDelay
+		}
+
+		if cliArgs.retryCommandTimeout != 0 {
+			suiteConfig.Retries.CommandTimeout = cliArgs.retryCommandTimeout
+		}
+
+		if cliArgs.retryParallelism != 0 {
+			suiteConfig.Retries.Parallelism = cliArgs.retryParallelism
+		}
+
 		if cliArgs.intermediateArtifactsPath != "" {
 			suiteConfig.Retries.IntermediateArtifactsPath = cliArgs.intermediateArtifactsPath
 		}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/rwx-research/captain-cli/internal/cli"
+	"github.com/rwx-research/captain-cli/internal/errors"
+)
+
+func configureValidateCmd(rootCmd *cobra.Command, cliArgs *CliArgs) {
+	validateCmd := &cobra.Command{
+		Use:   "validate [flags] <rwx-v1-json-files>",
+		Short: "Validates files against the RWX v1 JSON schema",
+		Long: "'captain validate' checks that files match the RWX v1 JSON schema and that their summaries match " +
+			"their tests. Every problem is reported with a JSON pointer to the offending value.",
+		Example: `captain validate results.json`,
+		Args:    cobra.MinimumNArgs(1),
+		PreRunE: unsafeInitParsingOnly(cliArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			captain, err := cli.GetService(cmd)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			// Invalid files aren't a usage error
+			cmd.SilenceUsage = true
+
+			err = captain.Validate(cmd.Context(), cliArgs.RootCliArgs.positionalArgs)
+			return errors.WithStack(err)
+		},
+	}
+
+	rootCmd.AddCommand(validateCmd)
+}
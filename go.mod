@@ -22,6 +22,7 @@ require (
 	github.com/acarl005/stripansi v0.0.0-20180116102854-5a71ef0e047d
 	github.com/blang/semver/v4 v4.0.0
 	github.com/mitchellh/go-wordwrap v1.0.1
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 )
 
 require (
@@ -80,12 +80,16 @@ func (s Service) setAbqExitCode(ctx context.Context, captainErr error) error {
 		exitCode = 1
 	}
 
-	_, err = s.runCommand(ctx, []string{
-		state.AbqExecutable,
-		"set-exit-code",
-		"--run-id", state.RunID,
-		"--exit-code", fmt.Sprint(exitCode),
-	}, os.Stdout, false)
+	err = s.runCommand(ctx, runCommandConfig{
+		args: []string{
+			state.AbqExecutable,
+			"set-exit-code",
+			"--run-id", state.RunID,
+			"--exit-code", fmt.Sprint(exitCode),
+		},
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	})
 	if err != nil {
 		err = errors.Wrap(err, "Error setting ABQ exit code")
 	}
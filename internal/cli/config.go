@@ -2,8 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"regexp"
 	"strconv"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -15,35 +18,52 @@ import (
 
 // RunConfig holds the configuration for running a test suite (used by `RunSuite`)
 type RunConfig struct {
-	Args                      []string
-	Command                   string
-	TestResultsFileGlob       string
-	FailOnUploadError         bool
-	FailRetriesFast           bool
-	FlakyRetries              int
-	IntermediateArtifactsPath string
-	MaxTestsToRetry           string
-	PostRetryCommands         []string
-	PreRetryCommands          []string
-	PrintSummary              bool
-	Quiet                     bool
-	Reporters                 map[string]Reporter
-	Retries                   int
-	RetryCommandTemplate      string
-	SuiteID                   string
-	SubstitutionsByFramework  map[v1.Framework]targetedretries.Substitution
-	UpdateStoredResults       bool
-	UploadResults             bool
-	PartitionCommandTemplate  string
-	PartitionConfig           PartitionConfig
+	Args                             []string
+	Command                          string
+	TestResultsFileGlob              string
+	FailOnUploadError                bool
+	FailRetriesFast                  bool
+	FlakyRetries                     int
+	IntermediateArtifactsPath        string
+	IsolateRetries                   bool
+	MaxTestsToRetry                  string
+	PostRetryCommands                []string
+	PreRetryCommands                 []string
+	PrintSummary                     bool
+	Quiet                            bool
+	Reporters                        map[string]Reporter
+	Retries                          int
+	RetryCommandTemplate             string
+	RetryBackoff                     RetryBackoff
+	RetryCommandTemplatesByFramework map[v1.Framework]string
+	RetryCommandTimeout              time.Duration
+	RetryParallelism                 int
+	SharedProcessGroup               bool
+	SuiteID                          string
+	SubstitutionsByFramework         map[v1.Framework]targetedretries.Substitution
+	Timeout                          time.Duration
+	TimeoutGracePeriod               time.Duration
+	UpdateStoredResults              bool
+	UploadResults                    bool
+	PartitionCommandTemplate         string
+	PartitionConfig                  PartitionConfig
 }
 
+const (
+	// RunTimeoutExitCode is the exit code of a run that exceeded its timeout, the same as the one of `timeout(1)`
+	RunTimeoutExitCode = 124
+
+	defaultTimeoutGracePeriod = 10 * time.Second
+)
+
 var maxTestsToRetryRegexp = regexp.MustCompile(
 	`^\s*(?P<failureCount>\d+)\s*$|^\s*(?:(?P<failurePercentage>\d+(?:\.\d+)?)%)\s*$`,
 )
 
 func (rc RunConfig) Validate(log *zap.SugaredLogger) error {
-	if rc.RetryCommandTemplate == "" && (rc.Retries > 0 || rc.FlakyRetries > 0) {
+	hasRetryCommandTemplate := rc.RetryCommandTemplate != "" || len(rc.RetryCommandTemplatesByFramework) > 0
+
+	if !hasRetryCommandTemplate && (rc.Retries > 0 || rc.FlakyRetries > 0) {
 		return errors.NewConfigurationError(
 			"Missing retry command",
 			"You seem to have retries enabled, but there is no retry command template configured.",
@@ -53,7 +73,7 @@ func (rc RunConfig) Validate(log *zap.SugaredLogger) error {
 		)
 	}
 
-	if rc.RetryCommandTemplate != "" && !(rc.Retries > 0 || rc.FlakyRetries > 0) {
+	if hasRetryCommandTemplate && !(rc.Retries > 0 || rc.FlakyRetries > 0) {
 		log.Warn("There is a retry command configured for this test suite, however the retry count is set to 0.")
 		log.Warn("Retries are disabled.")
 	}
@@ -74,6 +94,70 @@ func (rc RunConfig) Validate(log *zap.SugaredLogger) error {
 		log.Warn("The --max-tests-to-retry flag has no effect as no retries are otherwise configured.")
 	}
 
+	if rc.RetryParallelism < 0 {
+		return errors.NewConfigurationError(
+			"Unsupported --retry-parallelism value",
+			fmt.Sprintf(
+				"The --retry-parallelism option is currently set to %d. However, at least one retry command needs to "+
+					"run at a time.",
+				rc.RetryParallelism,
+			),
+			"Please set --retry-parallelism to a positive integer. Retry commands run one after another by default.",
+		)
+	}
+
+	if rc.RetryParallelism > 1 && rc.TestResultsFileGlob != "" &&
+		!retryCommandIDRegexp.MatchString(rc.TestResultsFileGlob) {
+		return errors.NewConfigurationError(
+			"Test results path is shared between parallel retries",
+			fmt.Sprintf(
+				"You configured up to %d retry commands to run at the same time. However, the test results path %q is "+
+					"the same for all of them, so they would overwrite each other's test results.",
+				rc.RetryParallelism,
+				rc.TestResultsFileGlob,
+			),
+			fmt.Sprintf(
+				"Please include ${%s} in the test results path (and where your retry command writes its test results). "+
+					"Captain sets it to a different value for the original command and every retry command.",
+				RetryCommandIDEnvVar,
+			),
+		)
+	}
+
+	if rc.RetryParallelism > 1 && !(rc.Retries > 0 || rc.FlakyRetries > 0) {
+		log.Warn("The --retry-parallelism flag has no effect as no retries are otherwise configured.")
+	}
+
+	if rc.Timeout < 0 || rc.TimeoutGracePeriod < 0 {
+		return errors.NewConfigurationError(
+			"Unsupported --timeout value",
+			fmt.Sprintf(
+				"The timeout is currently set to %v with a grace period of %v. Neither of them can be negative.",
+				rc.Timeout,
+				rc.TimeoutGracePeriod,
+			),
+			"Please set --timeout to a positive duration (e.g. 30m), or to 0 to let the test suite run for as long as it "+
+				"needs.",
+		)
+	}
+
+	if err := rc.RetryBackoff.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if rc.RetryCommandTimeout < 0 {
+		return errors.NewConfigurationError(
+			"Unsupported --retry-command-timeout value",
+			fmt.Sprintf("The retry command timeout is currently set to %v, which is negative.", rc.RetryCommandTimeout),
+			"Please set --retry-command-timeout to a positive duration (e.g. 10m), or to 0 to let retry commands run "+
+				"for as long as they need.",
+		)
+	}
+
+	if rc.IsolateRetries && !(rc.Retries > 0 || rc.FlakyRetries > 0) {
+		log.Warn("The --isolate-retries flag has no effect as no retries are otherwise configured.")
+	}
+
 	if rc.PartitionCommandTemplate != "" && rc.PartitionConfig.PartitionNodes.Total <= 1 {
 		log.Warnf("There is a partition command configured for this test suite, but partitioning is disabled.")
 	}
@@ -88,6 +172,98 @@ func (rc RunConfig) Validate(log *zap.SugaredLogger) error {
 	return nil
 }
 
+const (
+	RetryBackoffFixed       = "fixed"
+	RetryBackoffExponential = "exponential"
+)
+
+// RetryBackoff configures how long to wait before each retry. A fixed backoff waits `Delay` every time, an
+// exponential one doubles it with every retry up to `MaxDelay` (if set). `Jitter` randomly shortens or lengthens each
+// delay by up to that fraction of it.
+type RetryBackoff struct {
+	Delay    time.Duration
+	Jitter   float64
+	MaxDelay time.Duration
+	Strategy string
+}
+
+func (rb RetryBackoff) Validate() error {
+	if rb.Strategy != "" && rb.Strategy != RetryBackoffFixed && rb.Strategy != RetryBackoffExponential {
+		return errors.NewConfigurationError(
+			"Unsupported retry backoff",
+			fmt.Sprintf("Captain doesn't know about a retry backoff called %q.", rb.Strategy),
+			fmt.Sprintf(
+				"Please set the retry backoff to either %q or %q.",
+				RetryBackoffFixed,
+				RetryBackoffExponential,
+			),
+		)
+	}
+
+	if rb.Delay < 0 || rb.MaxDelay < 0 {
+		return errors.NewConfigurationError(
+			"Negative retry backoff delay",
+			fmt.Sprintf(
+				"The retry backoff is currently set to a delay of %v and a maximum delay of %v.",
+				rb.Delay,
+				rb.MaxDelay,
+			),
+			"Please make sure that both delays are positive durations (e.g. 30s).",
+		)
+	}
+
+	if rb.Jitter < 0 || rb.Jitter > 1 {
+		return errors.NewConfigurationError(
+			"Unsupported retry backoff jitter",
+			fmt.Sprintf("The retry backoff jitter is currently set to %v.", rb.Jitter),
+			"The jitter is a fraction of the delay, so it needs to be between 0 and 1 (e.g. 0.2 for up to 20% more or "+
+				"less than the delay).",
+		)
+	}
+
+	return nil
+}
+
+// DelayBefore returns how long to wait before the given (1-indexed) retry
+func (rb RetryBackoff) DelayBefore(retry int) time.Duration {
+	delay := rb.Delay
+
+	if rb.Strategy == RetryBackoffExponential {
+		for i := 1; i < retry && (rb.MaxDelay == 0 || delay < rb.MaxDelay) && delay <= math.MaxInt64/2; i++ {
+			delay *= 2
+		}
+	}
+
+	if rb.MaxDelay > 0 && delay > rb.MaxDelay {
+		delay = rb.MaxDelay
+	}
+
+	if rb.Jitter > 0 {
+		//nolint:gosec // The jitter only spreads out retries, it doesn't need to be unpredictable
+		delay += time.Duration((rand.Float64()*2 - 1) * rb.Jitter * float64(delay))
+	}
+
+	return delay
+}
+
+// gracePeriod returns how long commands have to terminate once they timed out or captain was interrupted
+func (rc RunConfig) gracePeriod() time.Duration {
+	if rc.TimeoutGracePeriod == 0 {
+		return defaultTimeoutGracePeriod
+	}
+
+	return rc.TimeoutGracePeriod
+}
+
+// RetryCommandTemplateFor returns the retry command template for the tests of the given framework
+func (rc RunConfig) RetryCommandTemplateFor(framework v1.Framework) string {
+	if retryCommandTemplate, ok := rc.RetryCommandTemplatesByFramework[framework]; ok {
+		return retryCommandTemplate
+	}
+
+	return rc.RetryCommandTemplate
+}
+
 func (rc RunConfig) MaxTestsToRetryCount() (*int, error) {
 	if rc.MaxTestsToRetry == "" {
 		return nil, nil
@@ -217,3 +393,33 @@ type QuarantineConfig struct {
 	SuiteID             string
 	UpdateStoredResults bool
 }
+
+// DiffConfig holds the configuration for comparing the test results of two runs (used by `Diff`)
+type DiffConfig struct {
+	BaseTestResultsFileGlob string
+	HeadTestResultsFileGlob string
+	Format                  string
+	IdentityComponents      []string
+	SlowdownFactor          float64
+	SlowdownThreshold       time.Duration
+}
+
+func (dc DiffConfig) Validate() error {
+	if dc.Format != "text" && dc.Format != "markdown" {
+		return errors.NewConfigurationError(
+			"Unsupported diff format",
+			fmt.Sprintf("Captain is unable to write a diff as %q.", dc.Format),
+			"Please set the format to either 'text' or 'markdown' using the --format flag.",
+		)
+	}
+
+	if dc.SlowdownFactor < 1 {
+		return errors.NewConfigurationError(
+			"Invalid slowdown factor",
+			fmt.Sprintf("A slowdown factor of %v would report tests that got faster as slower.", dc.SlowdownFactor),
+			"Please set the slowdown factor to 1 or more using the --slowdown-factor flag.",
+		)
+	}
+
+	return nil
+}
@@ -1,5 +1,7 @@
 package cli
 
+import "time"
+
 // configFile holds all options that can be set over the config file
 type ConfigFile struct {
 	Cloud struct {
@@ -20,18 +22,44 @@ type SuiteConfigOutput struct {
 	Quiet        bool
 }
 
+type SuiteConfigBazel struct {
+	BuildEventJSONFile string `yaml:"build-event-json-file"`
+	Testlogs           string
+}
+
+type SuiteConfigParser struct {
+	Name    string
+	Command string
+	Probe   string
+	Timeout time.Duration
+}
+
 type SuiteConfigResults struct {
+	Bazel     SuiteConfigBazel
 	Framework string
 	Language  string
+	Parsers   []SuiteConfigParser
 	Path      string
 }
 
+type SuiteConfigRetriesBackoff struct {
+	Delay    time.Duration
+	Jitter   float64
+	MaxDelay time.Duration `yaml:"max-delay"`
+	Strategy string
+}
+
 type SuiteConfigRetries struct {
 	Attempts                  int
+	Backoff                   SuiteConfigRetriesBackoff
 	Command                   string
-	FailFast                  bool `yaml:"fail-fast"`
-	FlakyAttempts             int  `yaml:"flaky-attempts"`
+	CommandTimeout            time.Duration     `yaml:"command-timeout"`
+	FailFast                  bool              `yaml:"fail-fast"`
+	FlakyAttempts             int               `yaml:"flaky-attempts"`
+	FrameworkCommands         map[string]string `yaml:"framework-commands"`
+	Isolate                   bool
 	MaxTests                  string
+	Parallelism               int
 	PostRetryCommands         []string `yaml:"post-retry-commands"`
 	PreRetryCommands          []string `yaml:"pre-retry-commands"`
 	IntermediateArtifactsPath string   `yaml:"intermediate-artifacts-path"`
@@ -45,10 +73,13 @@ type SuiteConfigPartition struct {
 
 // SuiteConfig holds options that can be customized per suite
 type SuiteConfig struct {
-	Command           string
-	FailOnUploadError bool `yaml:"fail-on-upload-error"`
-	Output            SuiteConfigOutput
-	Results           SuiteConfigResults
-	Retries           SuiteConfigRetries
-	Partition         SuiteConfigPartition
+	Command            string
+	FailOnUploadError  bool `yaml:"fail-on-upload-error"`
+	Output             SuiteConfigOutput
+	Results            SuiteConfigResults
+	Retries            SuiteConfigRetries
+	Partition          SuiteConfigPartition
+	SharedProcessGroup bool `yaml:"shared-process-group"`
+	Timeout            time.Duration
+	TimeoutGrace       time.Duration `yaml:"timeout-grace-period"`
 }
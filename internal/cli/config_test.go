@@ -1,6 +1,8 @@
 package cli_test
 
 import (
+	"time"
+
 	"go.uber.org/zap/zaptest"
 
 	"github.com/rwx-research/captain-cli/internal/cli"
@@ -177,6 +179,45 @@ var _ = Describe("RunConfig", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("errs when the retry backoff is invalid", func() {
+			var err error
+
+			err = cli.RunConfig{RetryBackoff: cli.RetryBackoff{Strategy: "linear"}}.Validate(logger)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unsupported retry backoff"))
+
+			err = cli.RunConfig{RetryBackoff: cli.RetryBackoff{Delay: -time.Second}}.Validate(logger)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Negative retry backoff delay"))
+
+			err = cli.RunConfig{RetryBackoff: cli.RetryBackoff{Delay: time.Second, Jitter: 1.5}}.Validate(logger)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unsupported retry backoff jitter"))
+
+			err = cli.RunConfig{RetryBackoff: cli.RetryBackoff{
+				Strategy: cli.RetryBackoffExponential,
+				Delay:    time.Second,
+				Jitter:   0.5,
+			}}.Validate(logger)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("errs when the retry command timeout is negative", func() {
+			err := cli.RunConfig{RetryCommandTimeout: -time.Second}.Validate(logger)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unsupported --retry-command-timeout value"))
+		})
+
+		It("errs when the timeout or its grace period is negative", func() {
+			err := cli.RunConfig{Timeout: -time.Second}.Validate(logger)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unsupported --timeout value"))
+
+			err = cli.RunConfig{Timeout: time.Minute, TimeoutGracePeriod: -time.Second}.Validate(logger)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unsupported --timeout value"))
+		})
+
 		It("is valid when partitioning and partition config has command and globs", func() {
 			err := cli.RunConfig{
 				PartitionCommandTemplate: "something {{ testFiles }}",
@@ -233,6 +274,39 @@ var _ = Describe("RunConfig", func() {
 		})
 	})
 
+	Describe("RetryBackoff.DelayBefore", func() {
+		It("waits the same amount of time before every retry with a fixed backoff", func() {
+			backoff := cli.RetryBackoff{Strategy: cli.RetryBackoffFixed, Delay: time.Second}
+			Expect(backoff.DelayBefore(1)).To(Equal(time.Second))
+			Expect(backoff.DelayBefore(3)).To(Equal(time.Second))
+		})
+
+		It("doubles the delay with every retry with an exponential backoff", func() {
+			backoff := cli.RetryBackoff{Strategy: cli.RetryBackoffExponential, Delay: time.Second}
+			Expect(backoff.DelayBefore(1)).To(Equal(time.Second))
+			Expect(backoff.DelayBefore(2)).To(Equal(2 * time.Second))
+			Expect(backoff.DelayBefore(4)).To(Equal(8 * time.Second))
+		})
+
+		It("caps the delay at the maximum delay", func() {
+			backoff := cli.RetryBackoff{Strategy: cli.RetryBackoffExponential, Delay: time.Second, MaxDelay: 5 * time.Second}
+			Expect(backoff.DelayBefore(3)).To(Equal(4 * time.Second))
+			Expect(backoff.DelayBefore(4)).To(Equal(5 * time.Second))
+			Expect(backoff.DelayBefore(100)).To(Equal(5 * time.Second))
+		})
+
+		It("keeps the jitter within the configured fraction of the delay", func() {
+			backoff := cli.RetryBackoff{Strategy: cli.RetryBackoffFixed, Delay: time.Second, Jitter: 0.2}
+			for i := 0; i < 100; i++ {
+				Expect(backoff.DelayBefore(1)).To(BeNumerically("~", time.Second, 200*time.Millisecond))
+			}
+		})
+
+		It("doesn't wait without a delay", func() {
+			Expect(cli.RetryBackoff{}.DelayBefore(1)).To(Equal(time.Duration(0)))
+		})
+	})
+
 	Describe("IsRunningPartition", func() {
 		It("returns false when partition command template is not set", func() {
 			Expect(cli.RunConfig{}.IsRunningPartition()).To(Equal(false))
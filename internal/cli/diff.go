@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	"github.com/rwx-research/captain-cli/internal/reporting"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// Diff parses the test results of two runs and prints how their tests changed.
+func (s Service) Diff(_ context.Context, cfg DiffConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	base, err := s.parseGlob(cfg.BaseTestResultsFileGlob)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	head, err := s.parseGlob(cfg.HeadTestResultsFileGlob)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	diffs := v1.Diff(base, head, v1.DiffConfig{
+		IdentityComponents: cfg.IdentityComponents,
+		SlowdownFactor:     cfg.SlowdownFactor,
+		SlowdownThreshold:  cfg.SlowdownThreshold,
+	})
+
+	output := new(strings.Builder)
+	switch cfg.Format {
+	case "markdown":
+		err = reporting.WriteMarkdownDiff(output, diffs)
+	default:
+		err = reporting.WriteTextDiff(output, diffs)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	s.Log.Infoln(strings.TrimSpace(output.String()))
+	return nil
+}
+
+func (s Service) parseGlob(glob string) ([]v1.TestResults, error) {
+	filepaths, err := s.FileSystem.GlobMany([]string{glob})
+	if err != nil {
+		return nil, errors.NewSystemError("unable to expand filepath glob: %s", err)
+	}
+
+	if len(filepaths) == 0 {
+		return nil, errors.NewInputError("No test results files match %q", glob)
+	}
+
+	return s.parse(filepaths, 1)
+}
@@ -10,7 +10,8 @@ import (
 )
 
 // Reporter is a function that writes test results to a file. Different reporters implement different encodings.
-type Reporter func(fs.File, v1.TestResults, reporting.Configuration) error
+// Results of several frameworks are passed in together so that reporters can show them side by side.
+type Reporter func(fs.File, []v1.TestResults, reporting.Configuration) error
 
 // TaskRunner is an abstraction over various task-runners / execution environments.
 // They are expected to implement the `taskRunner.Command` interface in turn, which is mapped to the Command type from
@@ -18,4 +19,5 @@ type Reporter func(fs.File, v1.TestResults, reporting.Configuration) error
 type TaskRunner interface {
 	NewCommand(ctx context.Context, cfg exec.CommandConfig) (exec.Command, error)
 	GetExitStatusFromError(error) (int, error)
+	NotifyContext(ctx context.Context) (context.Context, context.CancelFunc)
 }
@@ -3,6 +3,10 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
 
 	"github.com/rwx-research/captain-cli/internal/errors"
 	"github.com/rwx-research/captain-cli/internal/parsing"
@@ -11,11 +15,21 @@ import (
 
 // Parse parses the files supplied in `filepaths` and prints them as formatted JSON to stdout.
 func (s Service) Parse(_ context.Context, filepaths []string) error {
-	results, err := s.parse(filepaths, 1)
+	allResults, err := s.parse(filepaths, 1)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	// Results of a single framework are printed as they are, several frameworks are printed side by side
+	var results any
+	switch len(allResults) {
+	case 0:
+	case 1:
+		results = allResults[0]
+	default:
+		results = allResults
+	}
+
 	newOutput, err := json.MarshalIndent(results, "", "  ")
 	if err != nil {
 		return errors.NewInternalError("Unable to output test results as JSON: %s", err)
@@ -25,39 +39,228 @@ func (s Service) Parse(_ context.Context, filepaths []string) error {
 	return nil
 }
 
-func (s Service) parse(filepaths []string, group int) (*v1.TestResults, error) {
-	var framework *v1.Framework
-	allResults := make([]v1.TestResults, 0)
+// parse parses the files supplied in `filepaths` and merges them into one set of test results per framework. The
+// frameworks are ordered by the first file they were detected in.
+func (s Service) parse(filepaths []string, group int) ([]v1.TestResults, error) {
+	if s.ParseConfig.BazelTestlogsPath != "" {
+		return s.parseBazel(filepaths, group)
+	}
+
+	allResults, err := s.parseConcurrently(filepaths, func(testResultsFilePath string) ([]v1.TestResults, error) {
+		return s.parseFile(testResultsFilePath, group)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeByFramework(nil, allResults), nil
+}
+
+// mergeByFramework merges each of `newResults` into the results in `allResults` that share its framework. Frameworks
+// that aren't part of `allResults` yet are appended in the order they appear in.
+func mergeByFramework(allResults []v1.TestResults, newResults []v1.TestResults) []v1.TestResults {
+	newResultsByFramework := make(map[string][]v1.TestResults)
+	knownFrameworks := make(map[string]struct{})
+	frameworks := make([]string, 0, len(allResults))
+	for _, results := range allResults {
+		framework := results.Framework.String()
+		knownFrameworks[framework] = struct{}{}
+		frameworks = append(frameworks, framework)
+	}
+
+	for _, results := range newResults {
+		framework := results.Framework.String()
+		if _, ok := knownFrameworks[framework]; !ok {
+			knownFrameworks[framework] = struct{}{}
+			frameworks = append(frameworks, framework)
+		}
+
+		newResultsByFramework[framework] = append(newResultsByFramework[framework], results)
+	}
+
+	mergedResults := make([]v1.TestResults, 0, len(frameworks))
+	for i, framework := range frameworks {
+		switch {
+		case i >= len(allResults):
+			mergedResults = append(mergedResults, v1.Merge(newResultsByFramework[framework]))
+		case len(newResultsByFramework[framework]) == 0:
+			mergedResults = append(mergedResults, allResults[i])
+		default:
+			mergedResults = append(
+				mergedResults,
+				v1.Merge([]v1.TestResults{allResults[i]}, newResultsByFramework[framework]),
+			)
+		}
+	}
+
+	return mergedResults
+}
+
+func (s Service) parseFile(testResultsFilePath string, group int) ([]v1.TestResults, error) {
+	s.Log.Debugf("Attempting to parse %q", testResultsFilePath)
+
+	fd, err := s.FileSystem.Open(testResultsFilePath)
+	if err != nil {
+		return nil, errors.NewSystemError("unable to open file: %s", err)
+	}
+	defer fd.Close()
+
+	results, err := parsing.ParseAll(fd, group, s.ParseConfig)
+	if err != nil {
+		return nil, errors.NewInputError("Unable to parse %q with the available parsers", testResultsFilePath)
+	}
+
+	return results, nil
+}
+
+// parseConcurrently calls `parseFile` for every file on a bounded number of goroutines. The results are returned in
+// the order of `filepaths`, regardless of which file finished parsing first, and all errors are reported together.
+// A single file might hold the results of several frameworks.
+func (s Service) parseConcurrently(
+	filepaths []string,
+	parseFile func(string) ([]v1.TestResults, error),
+) ([]v1.TestResults, error) {
+	results := make([][]v1.TestResults, len(filepaths))
+	parseErrors := make([]error, len(filepaths))
 
-	for _, testResultsFilePath := range filepaths {
-		s.Log.Debugf("Attempting to parse %q", testResultsFilePath)
+	var eg errgroup.Group
+	eg.SetLimit(runtime.GOMAXPROCS(0))
+	for i, testResultsFilePath := range filepaths {
+		i, testResultsFilePath := i, testResultsFilePath
+		eg.Go(func() error {
+			results[i], parseErrors[i] = parseFile(testResultsFilePath)
+			return nil
+		})
+	}
+	_ = eg.Wait()
 
-		fd, err := s.FileSystem.Open(testResultsFilePath)
+	failures := make([]string, 0)
+	var firstErr error
+	for _, err := range parseErrors {
+		if err == nil {
+			continue
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+		failures = append(failures, err.Error())
+	}
+
+	switch len(failures) {
+	case 0:
+	case 1:
+		return nil, firstErr
+	default:
+		return nil, errors.NewInputError(
+			"Unable to parse %d of the test results files:\n  %s",
+			len(failures),
+			strings.Join(failures, "\n  "),
+		)
+	}
+
+	allResults := make([]v1.TestResults, 0, len(results))
+	for _, result := range results {
+		allResults = append(allResults, result...)
+	}
+
+	return allResults, nil
+}
+
+// parseBazel parses the `test.xml` files from `bazel-testlogs`. If a build event file is configured, the attempts of
+// flaky targets are recovered from it and merged into the final attempt.
+func (s Service) parseBazel(filepaths []string, group int) ([]v1.TestResults, error) {
+	attemptsByTarget := make(map[parsing.BazelTarget][]parsing.BazelTestAttempt)
+	if s.ParseConfig.BazelBuildEventJSONPath != "" {
+		attempts, err := s.parseBazelBuildEvents(s.ParseConfig.BazelBuildEventJSONPath)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		for _, attempt := range attempts {
+			attemptsByTarget[attempt.Target] = append(attemptsByTarget[attempt.Target], attempt)
+		}
+	}
+
+	allResults, err := s.parseConcurrently(filepaths, func(testResultsFilePath string) ([]v1.TestResults, error) {
+		target, err := parsing.NewBazelTarget(s.ParseConfig.BazelTestlogsPath, testResultsFilePath)
 		if err != nil {
-			return nil, errors.NewSystemError("unable to open file: %s", err)
+			return nil, errors.WithStack(err)
 		}
-		defer fd.Close()
 
-		results, err := parsing.Parse(fd, group, s.ParseConfig)
+		results, err := s.parseBazelTestXML(testResultsFilePath, group, target)
 		if err != nil {
-			return nil, errors.NewInputError("Unable to parse %q with the available parsers", testResultsFilePath)
+			return nil, errors.WithStack(err)
 		}
 
-		if framework == nil {
-			framework = &results.Framework
-		} else if *framework != results.Framework {
-			return nil, errors.NewInputError(
-				"Multiple frameworks detected. The captain CLI only works with one framework at a time",
-			)
+		// The last attempt is the one Bazel wrote to `test.xml`; earlier ones are kept under `test_attempts`
+		attempts := attemptsByTarget[target]
+		resultsByAttempt := make([][]v1.TestResults, 0, len(attempts))
+		for i, attempt := range attempts {
+			if i == len(attempts)-1 {
+				break
+			}
+
+			if attempt.TestXMLPath == "" {
+				s.Log.Debugf("Skipping attempt %v of %v since its test.xml is not available locally", attempt.Attempt, target.Label)
+				continue
+			}
+
+			attemptResults, err := s.parseBazelTestXML(attempt.TestXMLPath, group, target)
+			if err != nil {
+				s.Log.Warnf("Unable to parse attempt %v of %v: %s", attempt.Attempt, target.Label, err.Error())
+				continue
+			}
+
+			resultsByAttempt = append(resultsByAttempt, []v1.TestResults{*attemptResults})
 		}
+		resultsByAttempt = append(resultsByAttempt, []v1.TestResults{*results})
 
-		allResults = append(allResults, *results)
+		return []v1.TestResults{v1.Merge(resultsByAttempt...)}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if len(allResults) == 0 {
 		return nil, nil
 	}
 
-	mergedResults := v1.Merge(allResults)
-	return &mergedResults, nil
+	return []v1.TestResults{v1.Merge(allResults)}, nil
+}
+
+func (s Service) parseBazelTestXML(
+	testResultsFilePath string,
+	group int,
+	target parsing.BazelTarget,
+) (*v1.TestResults, error) {
+	s.Log.Debugf("Attempting to parse %q as the results of %v", testResultsFilePath, target.Label)
+
+	fd, err := s.FileSystem.Open(testResultsFilePath)
+	if err != nil {
+		return nil, errors.NewSystemError("unable to open file: %s", err)
+	}
+	defer fd.Close()
+
+	results, err := parsing.ParseBazel(fd, group, target, s.ParseConfig)
+	if err != nil {
+		return nil, errors.NewInputError("Unable to parse %q as a Bazel test.xml", testResultsFilePath)
+	}
+
+	return results, nil
+}
+
+func (s Service) parseBazelBuildEvents(buildEventJSONPath string) ([]parsing.BazelTestAttempt, error) {
+	fd, err := s.FileSystem.Open(buildEventJSONPath)
+	if err != nil {
+		return nil, errors.NewSystemError("unable to open file: %s", err)
+	}
+	defer fd.Close()
+
+	attempts, err := parsing.ParseBazelBuildEvents(fd)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return attempts, nil
 }
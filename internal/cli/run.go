@@ -1,12 +1,16 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mattn/go-shellwords"
 	"golang.org/x/sync/errgroup"
@@ -34,6 +38,7 @@ func (s Service) RunSuite(ctx context.Context, cfg RunConfig) (finalErr error) {
 	var apiConfiguration backend.RunConfiguration
 	eg, egCtx := errgroup.WithContext(ctx)
 	eg.Go(func() error {
+		var err error
 		apiConfiguration, err = s.API.GetRunConfiguration(egCtx, cfg.SuiteID)
 		if err != nil {
 			return errors.WithStack(err)
@@ -69,8 +74,32 @@ func (s Service) RunSuite(ctx context.Context, cfg RunConfig) (finalErr error) {
 		os.Exit(0)
 	}
 
+	// Timeouts and interrupts only stop running the tests; reporting and uploading still needs to happen afterwards
+	ctx, abqEnviron := s.applyAbqEnvironment(ctx)
+	runCtx, stopNotifying := s.TaskRunner.NotifyContext(ctx)
+	defer stopNotifying()
+	if cfg.Timeout > 0 {
+		var cancelRun context.CancelFunc
+		runCtx, cancelRun = context.WithTimeout(runCtx, cfg.Timeout)
+		defer cancelRun()
+	}
+
+	// The original command sees the same retry command ID in its environment as in its arguments, just like retry
+	// commands do
+	args := make([]string, len(runCommand.commandArgs))
+	for i, arg := range runCommand.commandArgs {
+		args[i] = expandRetryCommandID(arg, originalCommandID)
+	}
+
 	// Run sub-command
-	ctx, cmdErr := s.runCommand(ctx, runCommand.commandArgs, stdout, true)
+	cmdErr := s.runCommand(runCtx, runCommandConfig{
+		args:            args,
+		env:             append(abqEnviron, fmt.Sprintf("%s=%s", RetryCommandIDEnvVar, originalCommandID)),
+		gracePeriod:     cfg.gracePeriod(),
+		newProcessGroup: !cfg.SharedProcessGroup,
+		stdout:          stdout,
+		stderr:          os.Stderr,
+	})
 	defer func() {
 		if abqErr := s.setAbqExitCode(ctx, finalErr); abqErr != nil {
 			finalErr = errors.Wrap(finalErr, abqErr.Error())
@@ -80,9 +109,14 @@ func (s Service) RunSuite(ctx context.Context, cfg RunConfig) (finalErr error) {
 			s.Log.Errorf("Error setting ABQ exit code: %v", finalErr)
 		}
 	}()
-	testResults, testResultsFiles, runErr, err := s.handleCommandOutcome(cfg, cmdErr, 1)
+	testResults, testResultsFiles, runErr, err := s.handleCommandOutcome(cfg, cmdErr, 1, originalCommandID)
 	if err != nil {
-		return err
+		if _, interrupted := wasInterrupted(ctx, runCtx); !interrupted && !hasTimedOut(ctx, runCtx) {
+			return err
+		}
+
+		// Result files might only have been written partially when the tests were interrupted
+		s.Log.Warnf("Unable to parse the test results of the interrupted test suite: %s", err.Error())
 	}
 
 	// Wait until run configuration was fetched. Ignore any errors.
@@ -90,7 +124,7 @@ func (s Service) RunSuite(ctx context.Context, cfg RunConfig) (finalErr error) {
 		s.Log.Warnf("Unable to fetch run configuration from Captain: %s", err)
 	}
 
-	testResults, didRetry, err := s.attemptRetries(ctx, testResults, testResultsFiles, cfg, apiConfiguration)
+	testResults, didRetry, err := s.attemptRetries(runCtx, testResults, testResultsFiles, cfg, apiConfiguration)
 	if err != nil {
 		s.Log.Warnf("An issue occurred while retrying your tests: %v", err)
 	}
@@ -99,17 +133,18 @@ func (s Service) RunSuite(ctx context.Context, cfg RunConfig) (finalErr error) {
 	unquarantinedFailedTests := make([]v1.Test, 0)
 	otherErrorCount := 0
 
-	if testResults != nil {
-		otherErrorCount = testResults.Summary.OtherErrors
+	quarantinedTests := make([]backend.Test, len(apiConfiguration.QuarantinedTests))
+	for i, quarantinedTest := range apiConfiguration.QuarantinedTests {
+		quarantinedTests[i] = quarantinedTest.Test
+	}
+	quarantinedTestIndex := newTestIndex(quarantinedTests)
 
-		quarantinedTests := make([]backend.Test, len(apiConfiguration.QuarantinedTests))
-		for i, quarantinedTest := range apiConfiguration.QuarantinedTests {
-			quarantinedTests[i] = quarantinedTest.Test
-		}
+	for i := range testResults {
+		otherErrorCount += testResults[i].Summary.OtherErrors
 
-		for i, test := range testResults.Tests {
-			if s.isIdentifiedIn(test, quarantinedTests) && test.Attempt.Status.PotentiallyFlaky() {
-				testResults.Tests[i] = test.Quarantine()
+		for j, test := range testResults[i].Tests {
+			if s.isIdentifiedIn(test, quarantinedTestIndex) && test.Attempt.Status.PotentiallyFlaky() {
+				testResults[i].Tests[j] = test.Quarantine()
 				s.Log.Debugf("quarantined %v test: %v", test.Attempt.Status, test)
 				quarantinedFailedTests = append(quarantinedFailedTests, test)
 			} else if test.Attempt.Status.ImpliesFailure() {
@@ -117,7 +152,7 @@ func (s Service) RunSuite(ctx context.Context, cfg RunConfig) (finalErr error) {
 				unquarantinedFailedTests = append(unquarantinedFailedTests, test)
 			}
 		}
-		testResults.Summary = v1.NewSummary(testResults.Tests, testResults.OtherErrors)
+		testResults[i].Summary = v1.NewSummary(testResults[i].Tests, testResults[i].OtherErrors)
 	}
 
 	var uploadResults []backend.TestResultsUploadResult
@@ -131,8 +166,8 @@ func (s Service) RunSuite(ctx context.Context, cfg RunConfig) (finalErr error) {
 
 	// We ignore the error here since `UploadTestResults` will already log any errors. Furthermore, any errors here will
 	// not affect the exit code.
-	if testResults != nil {
-		uploadResults, uploadError = s.reportTestResults(ctx, cfg, *testResults)
+	if len(testResults) > 0 {
+		uploadResults, uploadError = s.reportTestResults(ctx, cfg, testResults)
 	} else {
 		s.Log.Debugf("No test results were parsed. Globbed files: %v", testResultsFiles)
 	}
@@ -215,6 +250,22 @@ func (s Service) RunSuite(ctx context.Context, cfg RunConfig) (finalErr error) {
 		)
 	}
 
+	if interrupt, interrupted := wasInterrupted(ctx, runCtx); interrupted {
+		return errors.NewExecutionError(
+			interrupt.ExitCode(),
+			"Captain stopped running the test suite after receiving %v",
+			interrupt.Signal,
+		)
+	}
+
+	if hasTimedOut(ctx, runCtx) {
+		return errors.NewExecutionError(
+			RunTimeoutExitCode,
+			"Captain stopped running the test suite after the configured timeout of %v",
+			cfg.Timeout,
+		)
+	}
+
 	// Return the original exit code if there was a non-test error
 	if runErr != nil && otherErrorCount > 0 {
 		return errors.WithStack(runErr)
@@ -240,11 +291,11 @@ func (s Service) RunSuite(ctx context.Context, cfg RunConfig) (finalErr error) {
 
 func (s Service) attemptRetries(
 	ctx context.Context,
-	originalTestResults *v1.TestResults,
+	originalTestResults []v1.TestResults,
 	originalTestResultsFiles []string,
 	cfg RunConfig,
 	apiConfiguration backend.RunConfiguration,
-) (*v1.TestResults, bool, error) {
+) ([]v1.TestResults, bool, error) {
 	nonFlakyRetries := cfg.Retries
 	flakyRetries := cfg.FlakyRetries
 
@@ -281,28 +332,23 @@ func (s Service) attemptRetries(
 		return originalTestResults, false, errors.NewInputError("Captain retries cannot be used with ABQ")
 	}
 
-	if originalTestResults == nil {
+	if len(originalTestResults) == 0 {
 		return originalTestResults, false, errors.NewInternalError("No test results detected")
 	}
 
-	compiledRetryTemplate, err := templating.CompileTemplate(cfg.RetryCommandTemplate)
-	if err != nil {
-		return originalTestResults, false, errors.WithStack(err)
-	}
-
-	framework := originalTestResults.Framework
-	var substitution targetedretries.Substitution = targetedretries.JSONSubstitution{FileSystem: s.FileSystem}
-	if err := substitution.ValidateTemplate(compiledRetryTemplate); err != nil {
-		frameworkSubstitution, ok := cfg.SubstitutionsByFramework[framework]
-		if !ok {
-			return originalTestResults, false, errors.NewInternalError("Unable to retry %q", framework)
+	retryGroupsByFramework := make(map[string]*retryGroup)
+	for _, testResults := range originalTestResults {
+		group, err := s.retryGroupFor(testResults.Framework, cfg)
+		if err != nil {
+			return originalTestResults, false, errors.WithStack(err)
 		}
 
-		if err := frameworkSubstitution.ValidateTemplate(compiledRetryTemplate); err != nil {
-			return originalTestResults, false, errors.WithStack(err)
+		if group == nil {
+			s.Log.Debugf("Not retrying %v tests since there is no retry command for them", testResults.Framework)
+			continue
 		}
 
-		substitution = frameworkSubstitution
+		retryGroupsByFramework[testResults.Framework.String()] = group
 	}
 
 	flattenedTestResults := originalTestResults
@@ -325,6 +371,8 @@ func (s Service) attemptRetries(
 	if flakyRetries > maxRetries {
 		maxRetries = flakyRetries
 	}
+	flakyTestIndex := newTestIndex(apiConfiguration.FlakyTests)
+
 	formattedRetryTotal := fmt.Sprintf(" of %v", maxRetries)
 	if flakyRetries > 0 && nonFlakyRetries > 0 && nonFlakyRetries != flakyRetries {
 		formattedRetryTotal = ""
@@ -333,18 +381,27 @@ func (s Service) attemptRetries(
 	for retries := 0; retries < maxRetries; retries++ {
 		remainingFlakyFailures := make([]v1.Test, 0)
 		remainingNonFlakyFailures := make([]v1.Test, 0)
+		testCount := 0.0
 
 		ias.setRetryID(retries + 1)
 
-		for _, test := range flattenedTestResults.Tests {
-			if !test.Attempt.Status.ImpliesFailure() {
+		for _, testResults := range flattenedTestResults {
+			if _, ok := retryGroupsByFramework[testResults.Framework.String()]; !ok {
 				continue
 			}
 
-			if s.isIdentifiedIn(test, apiConfiguration.FlakyTests) {
-				remainingFlakyFailures = append(remainingFlakyFailures, test)
-			} else {
-				remainingNonFlakyFailures = append(remainingNonFlakyFailures, test)
+			testCount += float64(testResults.Summary.Tests)
+
+			for _, test := range testResults.Tests {
+				if !test.Attempt.Status.ImpliesFailure() {
+					continue
+				}
+
+				if s.isIdentifiedIn(test, flakyTestIndex) {
+					remainingFlakyFailures = append(remainingFlakyFailures, test)
+				} else {
+					remainingNonFlakyFailures = append(remainingNonFlakyFailures, test)
+				}
 			}
 		}
 
@@ -365,7 +422,6 @@ func (s Service) attemptRetries(
 		}
 
 		// bail early if there are too many failed tests
-		testCount := float64(flattenedTestResults.Summary.Tests)
 		if maxTestsToRetryPercentage != nil &&
 			float64(testsRemaining) > testCount**maxTestsToRetryPercentage/100 {
 			break
@@ -387,6 +443,11 @@ func (s Service) attemptRetries(
 			break
 		}
 
+		// the run timed out or was interrupted, possibly while waiting
+		if err := s.waitBeforeRetry(ctx, cfg, retries+1); err != nil {
+			break
+		}
+
 		filter := func(test v1.Test) bool {
 			testIsFlaky := false
 			for _, remainingFlakyFailure := range remainingFlakyFailures {
@@ -409,102 +470,536 @@ func (s Service) attemptRetries(
 			return true
 		}
 
-		allNewTestResults := make([]v1.TestResults, 0)
-		allSubstitutions, err := substitution.SubstitutionsFor(compiledRetryTemplate, *flattenedTestResults, filter)
-		if err != nil {
-			return flattenedTestResults, true, errors.Wrap(err, "Unable construct retry substitutions")
-		}
-		for i, substitutions := range allSubstitutions {
-			command := compiledRetryTemplate.Substitute(substitutions)
-			args, err := shellwords.Parse(command)
-			if err != nil {
-				return flattenedTestResults, true, errors.Wrapf(err, "Unable to parse %q into shell arguments", command)
+		// Every framework is retried with its own command, but they all count towards the same retry
+		retryCommands := make([]retryCommand, 0)
+		for _, testResults := range flattenedTestResults {
+			group, ok := retryGroupsByFramework[testResults.Framework.String()]
+			if !ok || !hasTestsToRetry(testResults, filter) {
+				continue
 			}
 
-			ias.setCommandID(i + 1)
-
-			s.Log.Infoln()
-			s.Log.Infoln(strings.Repeat("-", 80))
-			if len(allSubstitutions) == 1 {
-				s.Log.Infoln(fmt.Sprintf("- Retry %v%v", retries+1, formattedRetryTotal))
-			} else {
-				s.Log.Infoln(fmt.Sprintf(
-					"- Retry %v%v, command %v of %v",
-					retries+1,
-					formattedRetryTotal,
-					i+1,
-					len(allSubstitutions),
-				))
+			testResultsToRetry := []v1.TestResults{testResults}
+			if cfg.IsolateRetries {
+				testResultsToRetry = isolateFailures(testResults, filter)
 			}
-			for keyword, value := range substitutions {
-				s.Log.Infoln(fmt.Sprintf("-   %v: %v", keyword, value))
-			}
-			s.Log.Infoln(strings.Repeat("-", 80))
-			s.Log.Infoln()
 
-			stdout := os.Stdout
-			if cfg.Quiet {
-				stdout, err = os.OpenFile(os.DevNull, os.O_APPEND|os.O_WRONLY, 0o666)
+			for _, testResults := range testResultsToRetry {
+				allSubstitutions, err := group.substitution.SubstitutionsFor(group.retryTemplate, testResults, filter)
 				if err != nil {
-					s.Log.Warnf("Could not open %s for writing", os.DevNull)
+					return flattenedTestResults, true, errors.Wrap(err, "Unable construct retry substitutions")
 				}
-			}
 
-			for _, preRetryCommand := range cfg.PreRetryCommands {
-				preRetryArgs, err := shellwords.Parse(preRetryCommand)
-				if err != nil {
-					return flattenedTestResults, true, errors.Wrapf(err, "Unable to parse %q into shell arguments", preRetryCommand)
+				testsToRetry := make([]v1.Test, 0)
+				for _, test := range testResults.Tests {
+					if test.Attempt.Status.ImpliesFailure() && filter(test) {
+						testsToRetry = append(testsToRetry, test)
+					}
 				}
 
-				if _, err := s.runCommand(ctx, preRetryArgs, stdout, false); err != nil {
-					return flattenedTestResults, true, errors.Wrapf(err, "Error while executing %q", preRetryCommand)
+				group.substitutions = append(group.substitutions, allSubstitutions...)
+				for _, substitutions := range allSubstitutions {
+					retryCommands = append(retryCommands, retryCommand{
+						framework:     testResults.Framework,
+						group:         group,
+						substitutions: substitutions,
+						tests:         testsToRetry,
+					})
 				}
 			}
+		}
 
-			_, cmdErr := s.runCommand(ctx, args, stdout, false)
+		allNewTestResults, err := s.runRetryCommands(ctx, cfg, ias, retryCommands, retries, formattedRetryTotal)
+		if err != nil {
+			return flattenedTestResults, true, err
+		}
 
-			for _, postRetryCommand := range cfg.PostRetryCommands {
-				postRetryArgs, err := shellwords.Parse(postRetryCommand)
-				if err != nil {
-					return flattenedTestResults, true, errors.Wrapf(err, "Unable to parse %q into shell arguments", postRetryCommand)
+		for _, group := range retryGroupsByFramework {
+			if jsonSubstitution, ok := group.substitution.(targetedretries.JSONSubstitution); ok {
+				if err := jsonSubstitution.CleanUp(group.substitutions); err != nil {
+					s.Log.Warn(err)
 				}
+			}
+			group.substitutions = nil
+		}
+		flattenedTestResults = mergeByFramework(flattenedTestResults, allNewTestResults)
+	}
 
-				if _, err := s.runCommand(ctx, postRetryArgs, stdout, false); err != nil {
-					return flattenedTestResults, true, errors.Wrapf(err, "Error while executing %q", postRetryCommand)
-				}
+	for _, testResults := range flattenedTestResults {
+		s.Log.Debugf("Retries complete, %v summary: %v\n", testResults.Framework, testResults.Summary)
+	}
+	return flattenedTestResults, true, nil
+}
+
+// waitBeforeRetry waits for as long as the retry backoff asks for. It errs if the context is done before that.
+func (s Service) waitBeforeRetry(ctx context.Context, cfg RunConfig, retry int) error {
+	if err := ctx.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	delay := cfg.RetryBackoff.DelayBefore(retry)
+	if delay > 0 {
+		s.Log.Infof("Waiting %v before retry %v", delay.Round(time.Millisecond), retry)
+	}
+
+	select {
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// retryGroup holds everything needed to retry the tests of a single framework
+type retryGroup struct {
+	retryTemplate templating.CompiledTemplate
+	substitution  targetedretries.Substitution
+	// substitutions are the substitutions of the current retry, kept around until they are cleaned up
+	substitutions []map[string]string
+}
+
+// retryCommand is a single command of a retry. `tests` are the tests it was meant to retry; when the command is split
+// up further by the substitution, it includes the tests of its sibling commands as well.
+type retryCommand struct {
+	framework     v1.Framework
+	group         *retryGroup
+	substitutions map[string]string
+	tests         []v1.Test
+}
+
+// isolatedTagKey is the key that retry attempts in isolation mode are tagged with
+const isolatedTagKey = "isolated"
+
+// RetryCommandIDEnvVar is set to a unique ID for every retry command (e.g. "retry-1-command-2") and to
+// `originalCommandID` for the original command. Referencing it in the test results path keeps retry commands that run
+// in parallel from overwriting each other's test results.
+const RetryCommandIDEnvVar = "CAPTAIN_RETRY_COMMAND_ID"
+
+// originalCommandID is what `RetryCommandIDEnvVar` is set to for the original command
+const originalCommandID = "original"
+
+var retryCommandIDRegexp = regexp.MustCompile(`\$(?:\{` + RetryCommandIDEnvVar + `\}|` + RetryCommandIDEnvVar + `\b)`)
+
+// expandRetryCommandID replaces references to `RetryCommandIDEnvVar` in `s`. Retry commands aren't run by a shell, so
+// captain has to take care of this itself.
+func expandRetryCommandID(s string, commandID string) string {
+	return retryCommandIDRegexp.ReplaceAllLiteralString(s, commandID)
+}
+
+// retryGroupFor compiles the retry command template for the given framework and picks the substitution that can fill
+// it in. No group is returned if there is no retry command template for the framework.
+func (s Service) retryGroupFor(framework v1.Framework, cfg RunConfig) (*retryGroup, error) {
+	retryCommandTemplate := cfg.RetryCommandTemplateFor(framework)
+	if retryCommandTemplate == "" {
+		return nil, nil
+	}
+
+	compiledRetryTemplate, err := templating.CompileTemplate(retryCommandTemplate)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var substitution targetedretries.Substitution = targetedretries.JSONSubstitution{FileSystem: s.FileSystem}
+	if err := substitution.ValidateTemplate(compiledRetryTemplate); err != nil {
+		frameworkSubstitution, ok := cfg.SubstitutionsByFramework[framework]
+		if !ok {
+			return nil, errors.NewInternalError("Unable to retry %q", framework)
+		}
+
+		if err := frameworkSubstitution.ValidateTemplate(compiledRetryTemplate); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		substitution = frameworkSubstitution
+	}
+
+	return &retryGroup{retryTemplate: compiledRetryTemplate, substitution: substitution}, nil
+}
+
+func hasTestsToRetry(testResults v1.TestResults, filter func(v1.Test) bool) bool {
+	for _, test := range testResults.Tests {
+		if test.Attempt.Status.ImpliesFailure() && filter(test) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runRetryCommands runs the retry commands of a single retry, up to `cfg.RetryParallelism` of them at the same time.
+// Commands running in parallel have their output buffered and printed once they finish. Their test results are
+// returned in the order of `retryCommands`, regardless of which command finished first.
+func (s Service) runRetryCommands(
+	ctx context.Context,
+	cfg RunConfig,
+	ias *intermediateArtifactStorage,
+	retryCommands []retryCommand,
+	retries int,
+	formattedRetryTotal string,
+) ([]v1.TestResults, error) {
+	parallelism := cfg.RetryParallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	runInParallel := parallelism > 1 && len(retryCommands) > 1
+
+	stdout := os.Stdout
+	if cfg.Quiet {
+		var err error
+		stdout, err = os.OpenFile(os.DevNull, os.O_APPEND|os.O_WRONLY, 0o666)
+		if err != nil {
+			s.Log.Warnf("Could not open %s for writing", os.DevNull)
+		}
+	}
+
+	var outputMutex sync.Mutex
+	newTestResultsByCommand := make([][]v1.TestResults, len(retryCommands))
+	unfinishedStatusByCommand := make([]*v1.TestStatus, len(retryCommands))
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(parallelism)
+
+	for i, retryCommand := range retryCommands {
+		i := i
+		retryCommand := retryCommand
+
+		eg.Go(func() error {
+			// Don't start any further commands once the run was stopped or one of them failed
+			if ctx.Err() != nil {
+				status := v1.NewCanceledTestStatus()
+				unfinishedStatusByCommand[i] = &status
+				return nil
+			}
+			if egCtx.Err() != nil {
+				return nil
+			}
+
+			commandIAS := *ias
+			commandIAS.setCommandID(i + 1)
+
+			if !runInParallel {
+				s.logRetryHeader(retryCommand, retries, formattedRetryTotal, i, len(retryCommands))
+
+				newTestResults, unfinishedStatus, err := s.runRetryCommand(
+					egCtx,
+					cfg,
+					&commandIAS,
+					retryCommand,
+					retries,
+					stdout,
+					os.Stderr,
+				)
+				newTestResultsByCommand[i] = newTestResults
+				unfinishedStatusByCommand[i] = unfinishedStatus
+				return err
+			}
+
+			commandStdout := new(bytes.Buffer)
+			commandStderr := new(bytes.Buffer)
+
+			newTestResults, unfinishedStatus, err := s.runRetryCommand(
+				egCtx,
+				cfg,
+				&commandIAS,
+				retryCommand,
+				retries,
+				commandStdout,
+				commandStderr,
+			)
+			newTestResultsByCommand[i] = newTestResults
+			unfinishedStatusByCommand[i] = unfinishedStatus
+
+			outputMutex.Lock()
+			defer outputMutex.Unlock()
+
+			s.logRetryHeader(retryCommand, retries, formattedRetryTotal, i, len(retryCommands))
+			if _, writeErr := io.Copy(stdout, commandStdout); writeErr != nil {
+				s.Log.Warnf("Unable to write the output of retry command %v: %s", i+1, writeErr.Error())
 			}
+			if _, writeErr := io.Copy(os.Stderr, commandStderr); writeErr != nil {
+				s.Log.Warnf("Unable to write the output of retry command %v: %s", i+1, writeErr.Error())
+			}
+
+			return err
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	allNewTestResults := make([]v1.TestResults, 0)
+	for _, newTestResults := range newTestResultsByCommand {
+		allNewTestResults = append(allNewTestResults, newTestResults...)
+	}
 
-			// +1 because it's 1-indexed, +1 because the original attempt was #1
-			newTestResults, newTestResultsFiles, _, err := s.handleCommandOutcome(cfg, cmdErr, retries+2)
-			if err != nil {
-				return flattenedTestResults, true, err
+	// Tests that an interrupted command didn't get to report on were most likely still running when it was stopped (or
+	// it was never started). Their results are only missing if none of the other commands reported on them either.
+	for i, retryCommand := range retryCommands {
+		unfinishedStatus := unfinishedStatusByCommand[i]
+		if unfinishedStatus == nil {
+			continue
+		}
+
+		unfinishedTests := make([]v1.Test, 0)
+		for _, test := range retryCommand.tests {
+			if !isReportedIn(test, retryCommand.framework, allNewTestResults) {
+				unfinishedTests = append(unfinishedTests, unfinishedAttemptOf(test, *unfinishedStatus))
 			}
+		}
+
+		if len(unfinishedTests) == 0 {
+			continue
+		}
+
+		unfinishedTestResults := []v1.TestResults{*v1.NewTestResults(retryCommand.framework, unfinishedTests, nil)}
+		if cfg.IsolateRetries {
+			tagIsolation(unfinishedTestResults)
+		}
+		allNewTestResults = append(allNewTestResults, unfinishedTestResults...)
+	}
+
+	return allNewTestResults, nil
+}
+
+// unfinishedAttemptOf returns `test` with a new attempt with the given status. The attempt keeps the metadata of the
+// previous one, as substitutions rely on it to retry the test again.
+func unfinishedAttemptOf(test v1.Test, status v1.TestStatus) v1.Test {
+	var meta map[string]any
+	for key, value := range test.Attempt.Meta {
+		// Tags only apply to the attempt they were added to
+		if key == "__rwx" {
+			continue
+		}
+
+		if meta == nil {
+			meta = make(map[string]any, len(test.Attempt.Meta))
+		}
+		meta[key] = value
+	}
+
+	return v1.Test{
+		Scope:    test.Scope,
+		ID:       test.ID,
+		Name:     test.Name,
+		Lineage:  test.Lineage,
+		Location: test.Location,
+		Attempt:  v1.TestAttempt{Status: status, Meta: meta},
+	}
+}
+
+func isReportedIn(test v1.Test, framework v1.Framework, allTestResults []v1.TestResults) bool {
+	for _, testResults := range allTestResults {
+		if testResults.Framework != framework {
+			continue
+		}
+
+		for _, reportedTest := range testResults.Tests {
+			if reportedTest.Matches(test) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (s Service) logRetryHeader(
+	retryCommand retryCommand,
+	retries int,
+	formattedRetryTotal string,
+	commandIndex int,
+	commandCount int,
+) {
+	s.Log.Infoln()
+	s.Log.Infoln(strings.Repeat("-", 80))
+	if commandCount == 1 {
+		s.Log.Infoln(fmt.Sprintf("- Retry %v%v", retries+1, formattedRetryTotal))
+	} else {
+		s.Log.Infoln(fmt.Sprintf(
+			"- Retry %v%v, command %v of %v",
+			retries+1,
+			formattedRetryTotal,
+			commandIndex+1,
+			commandCount,
+		))
+	}
+	for keyword, value := range retryCommand.substitutions {
+		s.Log.Infoln(fmt.Sprintf("-   %v: %v", keyword, value))
+	}
+	s.Log.Infoln(strings.Repeat("-", 80))
+	s.Log.Infoln()
+}
+
+// runRetryCommand runs a single retry command along with the pre- and post-retry commands, parses the test results it
+// produced, and moves them to the intermediate artifact storage. If the command was stopped before it finished, the
+// status of the tests it didn't get to is returned as well.
+func (s Service) runRetryCommand(
+	ctx context.Context,
+	cfg RunConfig,
+	ias *intermediateArtifactStorage,
+	retryCommand retryCommand,
+	retries int,
+	stdout io.Writer,
+	stderr io.Writer,
+) ([]v1.TestResults, *v1.TestStatus, error) {
+	commandID := ias.retryID + "-" + ias.commandID
+	env := []string{fmt.Sprintf("%s=%s", RetryCommandIDEnvVar, commandID)}
+
+	command := expandRetryCommandID(retryCommand.group.retryTemplate.Substitute(retryCommand.substitutions), commandID)
+	args, err := shellwords.Parse(command)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Unable to parse %q into shell arguments", command)
+	}
+
+	canceled := v1.NewCanceledTestStatus()
+
+	for _, preRetryCommand := range cfg.PreRetryCommands {
+		preRetryArgs, err := shellwords.Parse(preRetryCommand)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Unable to parse %q into shell arguments", preRetryCommand)
+		}
 
-			if newTestResults != nil {
-				allNewTestResults = append(allNewTestResults, *newTestResults)
+		if err := s.runCommand(ctx, runCommandConfig{
+			args:        preRetryArgs,
+			env:         env,
+			gracePeriod: cfg.gracePeriod(),
+			stdout:      stdout,
+			stderr:      stderr,
+		}); err != nil {
+			if ctx.Err() != nil {
+				return nil, &canceled, nil
 			}
-			if err := ias.moveTestResults(newTestResultsFiles); err != nil {
-				return flattenedTestResults, true, errors.WithStack(err)
+
+			return nil, nil, errors.Wrapf(err, "Error while executing %q", preRetryCommand)
+		}
+	}
+
+	commandCtx := ctx
+	if cfg.RetryCommandTimeout > 0 {
+		var cancel context.CancelFunc
+		commandCtx, cancel = context.WithTimeout(ctx, cfg.RetryCommandTimeout)
+		defer cancel()
+	}
+
+	cmdErr := s.runCommand(commandCtx, runCommandConfig{
+		args:            args,
+		env:             env,
+		gracePeriod:     cfg.gracePeriod(),
+		newProcessGroup: !cfg.SharedProcessGroup,
+		stdout:          stdout,
+		stderr:          stderr,
+	})
+
+	// The run timing out or being interrupted cancels the tests, the retry command timing out only times them out
+	var unfinishedStatus *v1.TestStatus
+	switch {
+	case ctx.Err() != nil:
+		unfinishedStatus = &canceled
+	case errors.Is(commandCtx.Err(), context.DeadlineExceeded):
+		s.Log.Warnf("Retry command %q timed out after %v", command, cfg.RetryCommandTimeout)
+		timedOut := v1.NewTimedOutTestStatus()
+		unfinishedStatus = &timedOut
+	}
+
+	// There's no time left for post-retry commands if the run timed out or was interrupted
+	for _, postRetryCommand := range cfg.PostRetryCommands {
+		if ctx.Err() != nil {
+			break
+		}
+
+		postRetryArgs, err := shellwords.Parse(postRetryCommand)
+		if err != nil {
+			return nil, unfinishedStatus, errors.Wrapf(err, "Unable to parse %q into shell arguments", postRetryCommand)
+		}
+
+		if err := s.runCommand(ctx, runCommandConfig{
+			args:        postRetryArgs,
+			env:         env,
+			gracePeriod: cfg.gracePeriod(),
+			stdout:      stdout,
+			stderr:      stderr,
+		}); err != nil && ctx.Err() == nil {
+			return nil, unfinishedStatus, errors.Wrapf(err, "Error while executing %q", postRetryCommand)
+		}
+	}
+
+	// +1 because it's 1-indexed, +1 because the original attempt was #1
+	newTestResults, newTestResultsFiles, _, err := s.handleCommandOutcome(cfg, cmdErr, retries+2, commandID)
+	if err != nil {
+		if unfinishedStatus == nil {
+			return nil, nil, err
+		}
+
+		// Result files might only have been written partially when the command was stopped
+		s.Log.Warnf("Unable to parse the test results of the interrupted retry command %q: %s", command, err.Error())
+		return nil, unfinishedStatus, nil
+	}
+
+	// Parsers report tests that never finished as canceled, which only holds if it wasn't the retry command that timed out
+	if unfinishedStatus != nil {
+		for i := range newTestResults {
+			for j, test := range newTestResults[i].Tests {
+				if test.Attempt.Status.Kind == v1.TestStatusCanceled {
+					newTestResults[i].Tests[j].Attempt.Status = *unfinishedStatus
+				}
 			}
+			newTestResults[i].Summary = v1.NewSummary(newTestResults[i].Tests, newTestResults[i].OtherErrors)
 		}
-		if jsonSubstitution, ok := substitution.(targetedretries.JSONSubstitution); ok {
-			if err := jsonSubstitution.CleanUp(allSubstitutions); err != nil {
-				s.Log.Warn(err)
+	}
+
+	if err := ias.moveTestResults(newTestResultsFiles); err != nil {
+		return nil, unfinishedStatus, errors.WithStack(err)
+	}
+
+	if cfg.IsolateRetries {
+		tagIsolation(newTestResults)
+	}
+
+	return newTestResults, unfinishedStatus, nil
+}
+
+// tagIsolation tags the attempts of retries in isolation mode with whether they ran on their own. Retry commands
+// targeting a single test may still end up running others, e.g. ones sharing the same name. Some frameworks (like Jest
+// or Cypress) report the tests they didn't select as skipped, which doesn't count as running them.
+func tagIsolation(testResults []v1.TestResults) {
+	testCount := 0
+	for _, results := range testResults {
+		for _, test := range results.Tests {
+			if !test.Attempt.Status.ImpliesSkipped() {
+				testCount++
 			}
 		}
-		mergedTestResults := v1.Merge([]v1.TestResults{*flattenedTestResults}, allNewTestResults)
-		flattenedTestResults = &mergedTestResults
 	}
 
-	s.Log.Debugf("Retries complete, summary: %v\n", flattenedTestResults.Summary)
-	return flattenedTestResults, true, nil
+	for i := range testResults {
+		for j, test := range testResults[i].Tests {
+			testResults[i].Tests[j] = test.Tag(isolatedTagKey, testCount == 1)
+		}
+	}
+}
+
+// isolateFailures splits the failures that should be retried into test results of their own, so that each of them is
+// retried by a separate command.
+func isolateFailures(testResults v1.TestResults, filter func(v1.Test) bool) []v1.TestResults {
+	isolatedTestResults := make([]v1.TestResults, 0)
+
+	for _, test := range testResults.Tests {
+		if !test.Attempt.Status.ImpliesFailure() || !filter(test) {
+			continue
+		}
+
+		isolatedTestResults = append(isolatedTestResults, *v1.NewTestResults(testResults.Framework, []v1.Test{test}, nil))
+	}
+
+	return isolatedTestResults
 }
 
 func (s Service) handleCommandOutcome(
 	cfg RunConfig,
 	cmdErr error,
 	groupNumber int,
-) (*v1.TestResults, []string, error, error) {
+	commandID string,
+) ([]v1.TestResults, []string, error, error) {
 	var runErr error
 	ok := true
 	if cmdErr != nil {
@@ -521,7 +1016,7 @@ func (s Service) handleCommandOutcome(
 		return nil, nil, errors.WithStack(runErr), errors.WithStack(runErr)
 	}
 
-	testResultsFiles, err := s.FileSystem.Glob(cfg.TestResultsFileGlob)
+	testResultsFiles, err := s.FileSystem.Glob(expandRetryCommandID(cfg.TestResultsFileGlob, commandID))
 	if err != nil {
 		return nil,
 			testResultsFiles,
@@ -541,63 +1036,86 @@ func (s Service) handleCommandOutcome(
 	return testResults, testResultsFiles, errors.WithStack(runErr), nil
 }
 
-func (s Service) runCommand(
-	ctx context.Context,
-	args []string,
-	stdout io.Writer,
-	setAbqEnviron bool,
-) (context.Context, error) {
-	var environ []string
+// runCommandConfig configures a single sub-process. `env` is added on top of captain's own environment.
+type runCommandConfig struct {
+	args            []string
+	env             []string
+	gracePeriod     time.Duration
+	newProcessGroup bool
+	stderr          io.Writer
+	stdout          io.Writer
+}
+
+// hasTimedOut checks whether the run timed out, as opposed to captain as a whole being stopped
+func hasTimedOut(ctx context.Context, runCtx context.Context) bool {
+	return ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded)
+}
 
-	if setAbqEnviron {
-		ctx, environ = s.applyAbqEnvironment(ctx)
+// wasInterrupted checks whether the run was stopped because captain received a termination signal
+func wasInterrupted(ctx context.Context, runCtx context.Context) (exec.InterruptError, bool) {
+	if ctx.Err() != nil {
+		return exec.InterruptError{}, false
 	}
 
+	return exec.AsInterruptError(context.Cause(runCtx))
+}
+
+func (s Service) runCommand(ctx context.Context, cfg runCommandConfig) error {
+	args := cfg.args
+
 	cmd, err := s.TaskRunner.NewCommand(ctx, exec.CommandConfig{
-		Name:   args[0],
-		Args:   args[1:],
-		Env:    environ,
-		Stdout: stdout,
-		Stderr: os.Stderr,
+		Name:            args[0],
+		Args:            args[1:],
+		Env:             cfg.env,
+		GracePeriod:     cfg.gracePeriod,
+		NewProcessGroup: cfg.newProcessGroup,
+		Stdout:          cfg.stdout,
+		Stderr:          cfg.stderr,
 	})
 	if err != nil {
-		return ctx, errors.NewSystemError("unable to spawn sub-process: %s", err)
+		return errors.NewSystemError("unable to spawn sub-process: %s", err)
 	}
 
 	s.Log.Debugf("Executing %q", strings.Join(args, " "))
 	if err := cmd.Start(); err != nil {
-		return ctx, errors.NewSystemError("unable to execute sub-command: %s", err)
+		return errors.NewSystemError("unable to execute sub-command: %s", err)
 	}
 	defer s.Log.Debugf("Finished executing %q", strings.Join(args, " "))
 
 	if err := cmd.Wait(); err != nil {
 		if code, e := s.TaskRunner.GetExitStatusFromError(err); e == nil {
-			return ctx, errors.NewExecutionError(code, "test suite exited with non-zero exit code")
+			return errors.NewExecutionError(code, "test suite exited with non-zero exit code")
 		}
 
-		return ctx, errors.NewSystemError("Error during program execution: %s", err)
+		return errors.NewSystemError("Error during program execution: %s", err)
 	}
 
-	return ctx, nil
+	return nil
 }
 
-func (s Service) isIdentifiedIn(test v1.Test, identifiedTests []backend.Test) bool {
-	for _, identifiedTest := range identifiedTests {
-		compositeIdentifier, err := test.Identify(
-			identifiedTest.IdentityComponents,
-			identifiedTest.StrictIdentity,
-		)
+func (s Service) isIdentifiedIn(test v1.Test, index testIndex) bool {
+	for _, group := range index.groups {
+		compositeIdentifier, err := test.Identify(group.identityComponents, group.strictIdentity)
 		if err != nil {
-			s.Log.Debugf("%v does not identify %v because %v", identifiedTest, test, err.Error())
+			s.Log.Debugf(
+				"tests identified by %v (strict: %v) do not identify %v because %v",
+				group.identityComponents,
+				group.strictIdentity,
+				test,
+				err.Error(),
+			)
 			continue
 		}
 
-		if compositeIdentifier != identifiedTest.CompositeIdentifier {
+		identifiedTest, ok := group.testsByCompositeIdentifiers[compositeIdentifier]
+		if !ok {
 			s.Log.Debugf(
-				"%v does not identify %v because they have different composite identifiers (%v != %v)",
-				identifiedTest,
+				"none of the %v tests identified by %v (strict: %v) identify %v because none of them have its composite "+
+					"identifier (%v)",
+				len(group.testsByCompositeIdentifiers),
+				group.identityComponents,
+				group.strictIdentity,
 				test,
-				identifiedTest.CompositeIdentifier,
 				compositeIdentifier,
 			)
 			continue
@@ -618,11 +1136,12 @@ func (s Service) isIdentifiedIn(test v1.Test, identifiedTests []backend.Test) bo
 func (s Service) reportTestResults(
 	ctx context.Context,
 	cfg RunConfig,
-	testResults v1.TestResults,
+	testResults []v1.TestResults,
 ) ([]backend.TestResultsUploadResult, error) {
 	reportingConfiguration := reporting.Configuration{
-		SuiteID:              cfg.SuiteID,
-		RetryCommandTemplate: cfg.RetryCommandTemplate,
+		SuiteID:                          cfg.SuiteID,
+		RetryCommandTemplate:             cfg.RetryCommandTemplate,
+		RetryCommandTemplatesByFramework: cfg.RetryCommandTemplatesByFramework,
 	}
 
 	if remoteClient, ok := s.API.(remote.Client); ok {
@@ -681,12 +1200,7 @@ func (s Service) reportTestResults(
 		return nil, nil
 	}
 
-	result, err := s.API.UpdateTestResults(ctx, cfg.SuiteID, testResults)
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to update test results")
-	}
-
-	return result, nil
+	return s.uploadTestResults(ctx, cfg.SuiteID, testResults)
 }
 
 func (s Service) printHeader() {
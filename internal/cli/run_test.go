@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -41,6 +43,7 @@ var _ = Describe("Run", func() {
 		core                zapcore.Core
 		recordedLogs        *observer.ObservedLogs
 		filesOpened         []string
+		filesOpenedMutex    sync.Mutex
 		mockAbqCommand      *mocks.Command
 		mockAbqCommandArgs  []string
 		abqStateJSON        string
@@ -112,9 +115,10 @@ var _ = Describe("Run", func() {
 			default:
 				Expect(cfg.Args).To(HaveLen(0))
 				Expect(cfg.Name).To(Equal(arg))
-				Expect(cfg.Env).To(HaveLen(2))
+				Expect(cfg.Env).To(HaveLen(3))
 				Expect(cfg.Env).To(ContainElement("ABQ_SET_EXIT_CODE=false"))
 				Expect(cfg.Env).To(ContainElement(ContainSubstring("ABQ_STATE_FILE=tmp/captain-abq-")))
+				Expect(cfg.Env).To(ContainElement(cli.RetryCommandIDEnvVar + "=original"))
 				return mockCommand, nil
 			}
 		}
@@ -141,7 +145,9 @@ var _ = Describe("Run", func() {
 				Equal(testResultsFilePath),
 				ContainSubstring(abqStateSubstring),
 			))
+			filesOpenedMutex.Lock()
 			filesOpened = append(filesOpened, name)
+			filesOpenedMutex.Unlock()
 
 			isAbqStateFile := strings.Contains(name, abqStateSubstring)
 			file := new(mocks.File)
@@ -885,6 +891,61 @@ var _ = Describe("Run", func() {
 			})
 		})
 
+		Context("tests quarantined by different identity components", func() {
+			BeforeEach(func() {
+				mockGetRunConfiguration := func(
+					_ context.Context,
+					_ string,
+				) (backend.RunConfiguration, error) {
+					return backend.RunConfiguration{
+						QuarantinedTests: []backend.QuarantinedTest{
+							{
+								Test: backend.Test{
+									CompositeIdentifier: "some-description -captain- huh",
+									IdentityComponents:  []string{"description", "huh"},
+									StrictIdentity:      true,
+								},
+							},
+							{
+								Test: backend.Test{
+									CompositeIdentifier: fmt.Sprintf("%v -captain- %v", firstFailedTestDescription, "/path/to/file.test"),
+									IdentityComponents:  []string{"description", "file"},
+									StrictIdentity:      true,
+								},
+							},
+							{
+								Test: backend.Test{
+									CompositeIdentifier: "some-description",
+									IdentityComponents:  []string{"description"},
+									StrictIdentity:      false,
+								},
+							},
+							{
+								Test: backend.Test{
+									CompositeIdentifier: secondFailedTestDescription,
+									IdentityComponents:  []string{"description"},
+									StrictIdentity:      false,
+								},
+							},
+						},
+					}, nil
+				}
+				service.API.(*mocks.API).MockGetRunConfiguration = mockGetRunConfiguration
+			})
+
+			It("doesn't return an error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("quarantines the tests identified by any of the identity components", func() {
+				Expect(uploadedTestResults).ToNot(BeNil())
+				Expect(uploadedTestResults.Summary.Quarantined).To(Equal(2))
+				Expect(uploadedTestResults.Tests[0].Attempt.Status.Kind).To(Equal(v1.TestStatusSuccessful))
+				Expect(uploadedTestResults.Tests[1].Attempt.Status.Kind).To(Equal(v1.TestStatusQuarantined))
+				Expect(uploadedTestResults.Tests[2].Attempt.Status.Kind).To(Equal(v1.TestStatusQuarantined))
+			})
+		})
+
 		Context("some quarantined tests successful", func() {
 			BeforeEach(func() {
 				mockGetRunConfiguration := func(
@@ -1096,6 +1157,74 @@ var _ = Describe("Run", func() {
 		})
 	})
 
+	Context("when the run times out", func() {
+		var uploadedTestResults *v1.TestResults
+
+		BeforeEach(func() {
+			uploadedTestResults = nil
+			runConfig.Timeout = 10 * time.Millisecond
+
+			// go test only reports tests once they finish, so the one that hangs was only ever started
+			service.ParseConfig.MutuallyExclusiveParsers = []parsing.Parser{parsing.GoTestParser{}}
+			originalOpen := service.FileSystem.(*mocks.FileSystem).MockOpen
+			service.FileSystem.(*mocks.FileSystem).MockOpen = func(name string) (fs.File, error) {
+				if name != testResultsFilePath {
+					return originalOpen(name)
+				}
+
+				file := new(mocks.File)
+				file.Reader = strings.NewReader(strings.Join([]string{
+					`{"Action":"run","Package":"example.com/pkg","Test":"TestPasses"}`,
+					`{"Action":"pass","Package":"example.com/pkg","Test":"TestPasses","Elapsed":0.001}`,
+					`{"Action":"run","Package":"example.com/pkg","Test":"TestHangs"}`,
+				}, "\n"))
+				return file, nil
+			}
+
+			service.TaskRunner.(*mocks.TaskRunner).MockNewCommand = func(
+				ctx context.Context,
+				_ exec.CommandConfig,
+			) (exec.Command, error) {
+				return &mocks.Command{
+					MockStart: func() error { return nil },
+					MockWait: func() error {
+						<-ctx.Done()
+						return errors.NewSystemError("signal: terminated")
+					},
+				}, nil
+			}
+			service.TaskRunner.(*mocks.TaskRunner).MockGetExitStatusFromError = func(error) (int, error) {
+				return 143, nil
+			}
+
+			service.API.(*mocks.API).MockUpdateTestResults = func(
+				_ context.Context,
+				_ string,
+				testResults v1.TestResults,
+			) ([]backend.TestResultsUploadResult, error) {
+				uploadedTestResults = &testResults
+				return []backend.TestResultsUploadResult{}, nil
+			}
+		})
+
+		It("exits with the timeout exit code", func() {
+			executionError, ok := errors.AsExecutionError(err)
+			Expect(ok).To(BeTrue(), "Error is an execution error")
+			Expect(executionError.Code).To(Equal(cli.RunTimeoutExitCode))
+		})
+
+		It("reports the tests that were still running as canceled", func() {
+			Expect(uploadedTestResults).ToNot(BeNil())
+			Expect(uploadedTestResults.Summary.Tests).To(Equal(2))
+			Expect(uploadedTestResults.Summary.Canceled).To(Equal(1))
+
+			Expect(uploadedTestResults.Tests[0].Name).To(Equal("TestHangs"))
+			Expect(uploadedTestResults.Tests[0].Attempt.Status.Kind).To(Equal(v1.TestStatusCanceled))
+			Expect(uploadedTestResults.Tests[1].Name).To(Equal("TestPasses"))
+			Expect(uploadedTestResults.Tests[1].Attempt.Status.Kind).To(Equal(v1.TestStatusSuccessful))
+		})
+	})
+
 	Describe("retries", func() {
 		var (
 			parseCount            int
@@ -1336,6 +1465,407 @@ var _ = Describe("Run", func() {
 			})
 		})
 
+		Context("when running commands in process groups", func() {
+			var newProcessGroupByCommand map[string]bool
+
+			BeforeEach(func() {
+				runConfig.Retries = 1
+				runConfig.PreRetryCommands = []string{"pre"}
+				runConfig.PostRetryCommands = []string{"post"}
+				newProcessGroupByCommand = make(map[string]bool)
+
+				service.TaskRunner.(*mocks.TaskRunner).MockNewCommand = func(
+					_ context.Context,
+					cfg exec.CommandConfig,
+				) (exec.Command, error) {
+					newProcessGroupByCommand[cfg.Name] = cfg.NewProcessGroup
+					return &mocks.Command{
+						MockStart: func() error { return nil },
+						MockWait:  func() error { return nil },
+					}, nil
+				}
+			})
+
+			It("only runs the test suite and the retries in process groups of their own", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newProcessGroupByCommand).To(HaveLen(4))
+
+				for name, newProcessGroup := range newProcessGroupByCommand {
+					Expect(newProcessGroup).To(Equal(name != "pre" && name != "post"), name)
+				}
+			})
+
+			Context("when the process group is shared", func() {
+				BeforeEach(func() {
+					runConfig.SharedProcessGroup = true
+				})
+
+				It("runs every command in captain's process group", func() {
+					Expect(err).ToNot(HaveOccurred())
+					Expect(newProcessGroupByCommand).To(HaveLen(4))
+					Expect(newProcessGroupByCommand).NotTo(ContainElement(true))
+				})
+			})
+		})
+
+		Context("when retrying in isolation", func() {
+			var (
+				retriedTests          []string
+				lastRetried           string
+				reportUnselectedTests bool
+			)
+
+			BeforeEach(func() {
+				runConfig.IsolateRetries = true
+				retriedTests = make([]string, 0)
+				lastRetried = ""
+				reportUnselectedTests = false
+
+				service.TaskRunner.(*mocks.TaskRunner).MockNewCommand = func(
+					_ context.Context,
+					cfg exec.CommandConfig,
+				) (exec.Command, error) {
+					if cfg.Name == "retry" {
+						Expect(cfg.Args).To(HaveLen(1))
+						retriedTests = append(retriedTests, cfg.Args[0])
+						lastRetried = cfg.Args[0]
+					}
+
+					return &mocks.Command{
+						MockStart: func() error { return nil },
+						MockWait:  func() error { return nil },
+					}, nil
+				}
+
+				originalParse := service.ParseConfig.MutuallyExclusiveParsers[0].(*mocks.Parser).MockParse
+				service.ParseConfig.MutuallyExclusiveParsers[0].(*mocks.Parser).MockParse = func(r io.Reader) (
+					*v1.TestResults,
+					error,
+				) {
+					if lastRetried == "" {
+						return originalParse(r)
+					}
+
+					// Every retry command only runs the test it was given
+					id := lastRetried
+					file := "/path/to/file.test"
+					if id == thirdTestDescription {
+						file = "/other/path/to/file.test"
+					}
+
+					tests := []v1.Test{
+						{
+							ID:       &id,
+							Name:     id,
+							Location: &v1.Location{File: file},
+							Attempt:  v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()},
+						},
+					}
+
+					if reportUnselectedTests {
+						unselected := "unselected"
+						tests = append(tests, v1.Test{
+							ID:       &unselected,
+							Name:     unselected,
+							Location: &v1.Location{File: "/path/to/file.test"},
+							Attempt:  v1.TestAttempt{Status: v1.NewSkippedTestStatus(nil)},
+						})
+					}
+
+					return &v1.TestResults{Framework: v1.RubyRSpecFramework, Tests: tests}, nil
+				}
+			})
+
+			It("retries every failed test with a command of its own", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retriedTests).To(Equal([]string{
+					firstTestDescription,
+					secondTestDescription,
+					thirdTestDescription,
+				}))
+			})
+
+			It("tags the retries as isolated", func() {
+				Expect(uploadedTestResults).ToNot(BeNil())
+				Expect(uploadedTestResults.Summary.Tests).To(Equal(3))
+				Expect(uploadedTestResults.Summary.Successful).To(Equal(3))
+
+				for _, test := range uploadedTestResults.Tests {
+					Expect(test.Attempt.Meta).To(HaveKeyWithValue("__rwx", map[string]any{"isolated": true}))
+					Expect(test.PastAttempts[0].Meta).NotTo(HaveKey("__rwx"))
+				}
+			})
+
+			Context("when the retries report the tests they didn't select as skipped", func() {
+				BeforeEach(func() {
+					reportUnselectedTests = true
+				})
+
+				It("still tags the retries as isolated", func() {
+					Expect(uploadedTestResults).ToNot(BeNil())
+
+					for _, test := range uploadedTestResults.Tests {
+						if test.Name == "unselected" {
+							continue
+						}
+
+						Expect(test.Attempt.Meta).To(HaveKeyWithValue("__rwx", map[string]any{"isolated": true}))
+					}
+				})
+			})
+		})
+
+		Context("when a retry command times out", func() {
+			var retryCommandConfig exec.CommandConfig
+
+			BeforeEach(func() {
+				runConfig.RetryCommandTimeout = 10 * time.Millisecond
+
+				service.TaskRunner.(*mocks.TaskRunner).MockNewCommand = func(
+					ctx context.Context,
+					cfg exec.CommandConfig,
+				) (exec.Command, error) {
+					if cfg.Name != "retry" {
+						return &mocks.Command{
+							MockStart: func() error { return nil },
+							MockWait:  func() error { return nil },
+						}, nil
+					}
+
+					retryCommandConfig = cfg
+					return &mocks.Command{
+						MockStart: func() error { return nil },
+						MockWait: func() error {
+							<-ctx.Done()
+							return errors.NewSystemError("signal: killed")
+						},
+					}, nil
+				}
+
+				originalParse := service.ParseConfig.MutuallyExclusiveParsers[0].(*mocks.Parser).MockParse
+				service.ParseConfig.MutuallyExclusiveParsers[0].(*mocks.Parser).MockParse = func(r io.Reader) (
+					*v1.TestResults,
+					error,
+				) {
+					testResults, err := originalParse(r)
+					if parseCount == 1 {
+						return testResults, err
+					}
+
+					// Only the first test finished before the retry command was killed
+					testResults.Tests = testResults.Tests[:1]
+					testResults.Tests[0].Attempt.Status = v1.NewSuccessfulTestStatus()
+					return testResults, err
+				}
+			})
+
+			It("gives the retry command a grace period to shut down", func() {
+				Expect(retryCommandConfig.GracePeriod).To(Equal(10 * time.Second))
+			})
+
+			It("reports the tests that were still running as timed out", func() {
+				Expect(uploadedTestResults).ToNot(BeNil())
+				Expect(uploadedTestResults.Summary.Tests).To(Equal(3))
+				Expect(uploadedTestResults.Summary.Successful).To(Equal(1))
+				Expect(uploadedTestResults.Summary.TimedOut).To(Equal(2))
+
+				Expect(uploadedTestResults.Tests[0].Attempt.Status.Kind).To(Equal(v1.TestStatusSuccessful))
+				Expect(uploadedTestResults.Tests[1].Attempt.Status.Kind).To(Equal(v1.TestStatusTimedOut))
+				Expect(uploadedTestResults.Tests[1].PastAttempts[0].Status.Kind).To(Equal(v1.TestStatusFailed))
+				Expect(uploadedTestResults.Tests[2].Attempt.Status.Kind).To(Equal(v1.TestStatusTimedOut))
+			})
+
+			It("logs the timeout", func() {
+				logMessages := make([]string, 0)
+				for _, log := range recordedLogs.All() {
+					logMessages = append(logMessages, log.Message)
+				}
+
+				Expect(logMessages).To(ContainElement(ContainSubstring("timed out after 10ms")))
+			})
+
+			Context("and its test results report the tests that were still running as canceled", func() {
+				BeforeEach(func() {
+					originalParse := service.ParseConfig.MutuallyExclusiveParsers[0].(*mocks.Parser).MockParse
+					service.ParseConfig.MutuallyExclusiveParsers[0].(*mocks.Parser).MockParse = func(r io.Reader) (
+						*v1.TestResults,
+						error,
+					) {
+						testResults, err := originalParse(r)
+						if parseCount == 1 {
+							return testResults, err
+						}
+
+						testResults.Tests = append(testResults.Tests, v1.Test{
+							ID:       &secondTestDescription,
+							Name:     secondTestDescription,
+							Location: &v1.Location{File: "/path/to/file.test"},
+							Attempt:  v1.TestAttempt{Status: v1.NewCanceledTestStatus()},
+						})
+						return testResults, err
+					}
+				})
+
+				It("reports them as timed out", func() {
+					Expect(uploadedTestResults).ToNot(BeNil())
+					Expect(uploadedTestResults.Summary.Canceled).To(Equal(0))
+					Expect(uploadedTestResults.Summary.TimedOut).To(Equal(2))
+					Expect(uploadedTestResults.Tests[1].Attempt.Status.Kind).To(Equal(v1.TestStatusTimedOut))
+				})
+			})
+		})
+
+		Context("when the run times out during a retry", func() {
+			var retryCommandConfig exec.CommandConfig
+
+			BeforeEach(func() {
+				runConfig.Timeout = 10 * time.Millisecond
+				runConfig.Retries = 2
+
+				service.TaskRunner.(*mocks.TaskRunner).MockNewCommand = func(
+					ctx context.Context,
+					cfg exec.CommandConfig,
+				) (exec.Command, error) {
+					if cfg.Name != "retry" {
+						return &mocks.Command{
+							MockStart: func() error { return nil },
+							MockWait:  func() error { return nil },
+						}, nil
+					}
+
+					retryCommandConfig = cfg
+					return &mocks.Command{
+						MockStart: func() error { return nil },
+						MockWait: func() error {
+							<-ctx.Done()
+							return errors.NewSystemError("signal: terminated")
+						},
+					}, nil
+				}
+
+				originalParse := service.ParseConfig.MutuallyExclusiveParsers[0].(*mocks.Parser).MockParse
+				service.ParseConfig.MutuallyExclusiveParsers[0].(*mocks.Parser).MockParse = func(r io.Reader) (
+					*v1.TestResults,
+					error,
+				) {
+					testResults, err := originalParse(r)
+					if parseCount == 1 {
+						return testResults, err
+					}
+
+					// Only the first test finished before the deadline
+					testResults.Tests = testResults.Tests[:1]
+					testResults.Tests[0].Attempt.Status = v1.NewSuccessfulTestStatus()
+					return testResults, err
+				}
+			})
+
+			It("exits with the timeout exit code", func() {
+				executionError, ok := errors.AsExecutionError(err)
+				Expect(ok).To(BeTrue(), "Error is an execution error")
+				Expect(executionError.Code).To(Equal(cli.RunTimeoutExitCode))
+			})
+
+			It("gives the retry command a grace period to shut down", func() {
+				Expect(retryCommandConfig.GracePeriod).To(Equal(10 * time.Second))
+			})
+
+			It("still uploads the test results and reports the unfinished tests as canceled", func() {
+				Expect(uploadedTestResults).ToNot(BeNil())
+				Expect(uploadedTestResults.Summary.Tests).To(Equal(3))
+				Expect(uploadedTestResults.Summary.Canceled).To(Equal(2))
+
+				Expect(uploadedTestResults.Tests[1].Attempt.Status.Kind).To(Equal(v1.TestStatusCanceled))
+				Expect(uploadedTestResults.Tests[1].PastAttempts[0].Status.Kind).To(Equal(v1.TestStatusFailed))
+				Expect(uploadedTestResults.Tests[2].Attempt.Status.Kind).To(Equal(v1.TestStatusCanceled))
+			})
+		})
+
+		Context("when captain is interrupted", func() {
+			var retried bool
+
+			BeforeEach(func() {
+				runConfig.Retries = 2
+				retried = false
+
+				var interrupt context.CancelCauseFunc
+				service.TaskRunner.(*mocks.TaskRunner).MockNotifyContext = func(ctx context.Context) (
+					context.Context,
+					context.CancelFunc,
+				) {
+					ctx, interrupt = context.WithCancelCause(ctx)
+					return ctx, func() { interrupt(nil) }
+				}
+
+				service.TaskRunner.(*mocks.TaskRunner).MockNewCommand = func(
+					_ context.Context,
+					cfg exec.CommandConfig,
+				) (exec.Command, error) {
+					if cfg.Name == "retry" {
+						retried = true
+					}
+
+					return &mocks.Command{
+						MockStart: func() error { return nil },
+						MockWait: func() error {
+							interrupt(exec.InterruptError{Signal: os.Interrupt})
+							return errors.NewSystemError("signal: interrupt")
+						},
+					}, nil
+				}
+			})
+
+			It("exits with the exit code of the signal", func() {
+				executionError, ok := errors.AsExecutionError(err)
+				Expect(ok).To(BeTrue(), "Error is an execution error")
+				Expect(executionError.Code).To(Equal(130))
+			})
+
+			It("doesn't retry the tests", func() {
+				Expect(retried).To(BeFalse())
+			})
+
+			It("still uploads the test results", func() {
+				Expect(uploadedTestResults).ToNot(BeNil())
+				Expect(uploadedTestResults.Summary.Tests).To(Equal(3))
+			})
+
+			Context("and a timeout is configured as well", func() {
+				BeforeEach(func() {
+					runConfig.Timeout = time.Minute
+				})
+
+				It("still stops the run", func() {
+					executionError, ok := errors.AsExecutionError(err)
+					Expect(ok).To(BeTrue(), "Error is an execution error")
+					Expect(executionError.Code).To(Equal(130))
+					Expect(retried).To(BeFalse())
+				})
+			})
+		})
+
+		Context("when there is a retry backoff", func() {
+			BeforeEach(func() {
+				runConfig.Retries = 2
+				runConfig.RetryBackoff = cli.RetryBackoff{
+					Strategy: cli.RetryBackoffExponential,
+					Delay:    time.Millisecond,
+				}
+			})
+
+			It("waits before every retry", func() {
+				Expect(err).NotTo(HaveOccurred())
+
+				logMessages := make([]string, 0)
+				for _, log := range recordedLogs.All() {
+					logMessages = append(logMessages, log.Message)
+				}
+
+				Expect(logMessages).To(ContainElement("Waiting 1ms before retry 1"))
+				Expect(logMessages).To(ContainElement("Waiting 2ms before retry 2"))
+			})
+		})
+
 		Context("when a intermediate artifacts path is defined", func() {
 			var (
 				intermediateTestResults []string
@@ -2081,44 +2611,384 @@ var _ = Describe("Run", func() {
 	})
 
 	Context("when there are multiple frameworks parsed", func() {
-		var parseCount int
+		var (
+			parseCounts         map[string]int
+			parseCountsMutex    sync.Mutex
+			writtenFiles        []string
+			retryArgs           [][]string
+			uploadedTestResults []v1.TestResults
+		)
 
 		BeforeEach(func() {
-			parseCount = 0
+			parseCounts = make(map[string]int)
+			writtenFiles = []string{"rspec.json", "jest.json"}
+			retryArgs = make([][]string, 0)
+			uploadedTestResults = make([]v1.TestResults, 0)
 
-			mockGetExitStatusFromError := func(error) (int, error) {
-				return 0, nil
+			service.FileSystem.(*mocks.FileSystem).MockGetwd = func() (string, error) {
+				return "/go/github.com/rwx-research/captain-cli", nil
 			}
-			service.TaskRunner.(*mocks.TaskRunner).MockGetExitStatusFromError = mockGetExitStatusFromError
+			service.FileSystem.(*mocks.FileSystem).MockMkdirAll = func(_ string, _ os.FileMode) error {
+				return nil
+			}
+			service.FileSystem.(*mocks.FileSystem).MockRename = func(_, _ string) error {
+				return nil
+			}
+			service.FileSystem.(*mocks.FileSystem).MockMkdirTemp = func(_, _ string) (string, error) {
+				return "/tmp/captain-test", nil
+			}
+			service.FileSystem.(*mocks.FileSystem).MockStat = func(string) (iofs.FileInfo, error) {
+				return nil, iofs.ErrNotExist
+			}
+			service.FileSystem.(*mocks.FileSystem).MockRemove = func(string) error {
+				return nil
+			}
+			service.FileSystem.(*mocks.FileSystem).MockGlob = func(_ string) ([]string, error) {
+				return writtenFiles, nil
+			}
+			service.FileSystem.(*mocks.FileSystem).MockOpen = func(name string) (fs.File, error) {
+				if strings.Contains(name, "tmp/captain-abq-") {
+					return nil, os.ErrNotExist
+				}
 
-			mockGlob := func(_ string) ([]string, error) {
-				return []string{testResultsFilePath, testResultsFilePath}, nil
+				file := new(mocks.File)
+				file.Reader = strings.NewReader(name)
+				file.MockName = func() string { return name }
+				return file, nil
 			}
-			service.FileSystem.(*mocks.FileSystem).MockGlob = mockGlob
 
-			service.ParseConfig.MutuallyExclusiveParsers[0].(*mocks.Parser).MockParse = func(_ io.Reader) (
+			service.TaskRunner.(*mocks.TaskRunner).MockGetExitStatusFromError = func(error) (int, error) {
+				return 1, nil
+			}
+			service.TaskRunner.(*mocks.TaskRunner).MockNewCommand = func(
+				_ context.Context,
+				cfg exec.CommandConfig,
+			) (exec.Command, error) {
+				if cfg.Name != "retry" {
+					return mockCommand, nil
+				}
+
+				// Each retry only writes the results of the framework it ran
+				retryArgs = append(retryArgs, cfg.Args)
+				writtenFiles = []string{fmt.Sprintf("%v.json", cfg.Args[0])}
+				return &mocks.Command{
+					MockStart: func() error { return nil },
+					MockWait:  func() error { return nil },
+				}, nil
+			}
+			mockCommand.MockWait = func() error {
+				return errors.NewExecutionError(1, "tests failed")
+			}
+
+			service.ParseConfig.MutuallyExclusiveParsers[0].(*mocks.Parser).MockParse = func(reader io.Reader) (
 				*v1.TestResults,
 				error,
 			) {
-				parseCount++
+				buf, err := io.ReadAll(reader)
+				Expect(err).NotTo(HaveOccurred())
+				name := string(buf)
+
+				// Files are parsed concurrently
+				parseCountsMutex.Lock()
+				defer parseCountsMutex.Unlock()
+				parseCounts[name]++
+
+				status := v1.NewSuccessfulTestStatus()
+				if parseCounts[name] == 1 {
+					status = v1.NewFailedTestStatus(nil, nil, nil)
+				}
 
 				framework := v1.RubyRSpecFramework
-				if parseCount == 2 {
+				if name == "jest.json" {
 					framework = v1.JavaScriptJestFramework
 				}
 
+				id := fmt.Sprintf("./%v[1:1]", name)
 				return &v1.TestResults{
 					Framework: framework,
-					Tests:     []v1.Test{},
+					Tests: []v1.Test{
+						{
+							ID:       &id,
+							Name:     name,
+							Location: &v1.Location{File: name},
+							Attempt:  v1.TestAttempt{Status: status},
+						},
+					},
+				}, nil
+			}
+
+			service.API.(*mocks.API).MockUpdateTestResults = func(
+				_ context.Context,
+				_ string,
+				testResults v1.TestResults,
+			) ([]backend.TestResultsUploadResult, error) {
+				uploadedTestResults = append(uploadedTestResults, testResults)
+				return []backend.TestResultsUploadResult{}, nil
+			}
+
+			runConfig = cli.RunConfig{
+				Args:                 []string{arg},
+				TestResultsFileGlob:  "*.json",
+				SuiteID:              "test",
+				Retries:              1,
+				RetryCommandTemplate: "retry rspec {{ tests }}",
+				RetryCommandTemplatesByFramework: map[v1.Framework]string{
+					v1.JavaScriptJestFramework: "retry jest --testNamePattern '{{ testNamePattern }}' " +
+						"--testPathPattern '{{ testPathPattern }}'",
+				},
+				SubstitutionsByFramework: map[v1.Framework]targetedretries.Substitution{
+					v1.RubyRSpecFramework:      new(targetedretries.RubyRSpecSubstitution),
+					v1.JavaScriptJestFramework: new(targetedretries.JavaScriptJestSubstitution),
+				},
+			}
+		})
+
+		It("keeps the results of each framework apart", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uploadedTestResults).To(HaveLen(2))
+
+			Expect(uploadedTestResults[0].Framework).To(Equal(v1.RubyRSpecFramework))
+			Expect(uploadedTestResults[0].Summary.Tests).To(Equal(1))
+			Expect(uploadedTestResults[0].Summary.Successful).To(Equal(1))
+			Expect(uploadedTestResults[0].Summary.Retries).To(Equal(1))
+
+			Expect(uploadedTestResults[1].Framework).To(Equal(v1.JavaScriptJestFramework))
+			Expect(uploadedTestResults[1].Summary.Tests).To(Equal(1))
+			Expect(uploadedTestResults[1].Summary.Successful).To(Equal(1))
+			Expect(uploadedTestResults[1].Summary.Retries).To(Equal(1))
+		})
+
+		It("retries each framework with its own command", func() {
+			Expect(retryArgs).To(HaveLen(2))
+			Expect(retryArgs[0]).To(Equal([]string{"rspec", "./rspec.json[1:1]"}))
+			Expect(retryArgs[1][0]).To(Equal("jest"))
+			Expect(retryArgs[1]).To(ContainElement("jest.json"))
+		})
+
+		Context("when a framework has no retry command", func() {
+			BeforeEach(func() {
+				runConfig.RetryCommandTemplate = ""
+			})
+
+			It("only retries the other frameworks", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(retryArgs).To(HaveLen(1))
+				Expect(retryArgs[0][0]).To(Equal("jest"))
+
+				Expect(uploadedTestResults).To(HaveLen(2))
+				Expect(uploadedTestResults[0].Summary.Failed).To(Equal(1))
+				Expect(uploadedTestResults[1].Summary.Successful).To(Equal(1))
+			})
+		})
+
+		Context("when retry commands run in parallel", func() {
+			var (
+				filesByCommandID      map[string]string
+				finishedCommands      []string
+				retryCommandsMutex    sync.Mutex
+				jestCommandFinished   chan struct{}
+				retryCommandIDsPassed []string
+				originalCommandEnv    []string
+				originalCommandArgs   []string
+			)
+
+			BeforeEach(func() {
+				filesByCommandID = make(map[string]string)
+				finishedCommands = make([]string, 0)
+				retryCommandIDsPassed = make([]string, 0)
+				jestCommandFinished = make(chan struct{})
+
+				runConfig.RetryParallelism = 2
+				runConfig.TestResultsFileGlob = fmt.Sprintf("${%s}*.json", cli.RetryCommandIDEnvVar)
+				runConfig.Args = []string{arg, fmt.Sprintf("--out=$%s.json", cli.RetryCommandIDEnvVar)}
+
+				service.FileSystem.(*mocks.FileSystem).MockGlob = func(pattern string) ([]string, error) {
+					if pattern == "original*.json" {
+						return writtenFiles, nil
+					}
+
+					retryCommandsMutex.Lock()
+					defer retryCommandsMutex.Unlock()
+					return []string{filesByCommandID[strings.TrimSuffix(pattern, "*.json")]}, nil
+				}
+
+				service.TaskRunner.(*mocks.TaskRunner).MockNewCommand = func(
+					_ context.Context,
+					cfg exec.CommandConfig,
+				) (exec.Command, error) {
+					if cfg.Name != "retry" {
+						originalCommandEnv = cfg.Env
+						originalCommandArgs = cfg.Args
+						return mockCommand, nil
+					}
+
+					framework := cfg.Args[0]
+					commandID := strings.TrimPrefix(cfg.Env[0], cli.RetryCommandIDEnvVar+"=")
+
+					retryCommandsMutex.Lock()
+					retryArgs = append(retryArgs, cfg.Args)
+					retryCommandIDsPassed = append(retryCommandIDsPassed, commandID)
+					filesByCommandID[commandID] = fmt.Sprintf("%v.json", framework)
+					retryCommandsMutex.Unlock()
+
+					return &mocks.Command{
+						MockStart: func() error { return nil },
+						MockWait: func() error {
+							// The RSpec command only finishes once the Jest command did
+							if framework == "rspec" {
+								select {
+								case <-jestCommandFinished:
+								case <-time.After(5 * time.Second):
+								}
+							}
+
+							retryCommandsMutex.Lock()
+							finishedCommands = append(finishedCommands, framework)
+							retryCommandsMutex.Unlock()
+
+							if framework == "jest" {
+								close(jestCommandFinished)
+							}
+							return nil
+						},
+					}, nil
+				}
+			})
+
+			It("runs the retry commands at the same time", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(finishedCommands).To(Equal([]string{"jest", "rspec"}))
+			})
+
+			It("passes each retry command its own ID", func() {
+				Expect(retryCommandIDsPassed).To(ConsistOf("retry-1-command-1", "retry-1-command-2"))
+			})
+
+			It("passes the original command an ID of its own", func() {
+				Expect(originalCommandEnv).To(ContainElement(cli.RetryCommandIDEnvVar + "=original"))
+				Expect(originalCommandArgs).To(ContainElement("--out=original.json"))
+			})
+
+			It("merges the test results in the order of the retry commands", func() {
+				Expect(uploadedTestResults).To(HaveLen(2))
+				Expect(uploadedTestResults[0].Framework).To(Equal(v1.RubyRSpecFramework))
+				Expect(uploadedTestResults[0].Summary.Successful).To(Equal(1))
+				Expect(uploadedTestResults[1].Framework).To(Equal(v1.JavaScriptJestFramework))
+				Expect(uploadedTestResults[1].Summary.Successful).To(Equal(1))
+			})
+
+			Context("when the test results path is the same for every retry command", func() {
+				BeforeEach(func() {
+					runConfig.TestResultsFileGlob = "*.json"
+				})
+
+				It("errs", func() {
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("Test results path is shared between parallel retries"))
+				})
+			})
+		})
+	})
+
+	Context("when there are multiple test results files", func() {
+		var (
+			testResultsFilePaths []string
+			unparsableFilePaths  []string
+			uploadedTestResults  *v1.TestResults
+		)
+
+		BeforeEach(func() {
+			testResultsFilePaths = []string{"one.json", "two.json", "three.json", "four.json"}
+			unparsableFilePaths = []string{}
+			uploadedTestResults = nil
+
+			service.FileSystem.(*mocks.FileSystem).MockGlob = func(_ string) ([]string, error) {
+				return testResultsFilePaths, nil
+			}
+			service.FileSystem.(*mocks.FileSystem).MockOpen = func(name string) (fs.File, error) {
+				if strings.Contains(name, "tmp/captain-abq-") {
+					return nil, os.ErrNotExist
+				}
+
+				file := new(mocks.File)
+				file.Reader = strings.NewReader(name)
+				file.MockName = func() string { return name }
+				return file, nil
+			}
+
+			service.ParseConfig.MutuallyExclusiveParsers[0].(*mocks.Parser).MockParse = func(reader io.Reader) (
+				*v1.TestResults,
+				error,
+			) {
+				buf, err := io.ReadAll(reader)
+				Expect(err).NotTo(HaveOccurred())
+				name := string(buf)
+
+				for _, unparsableFilePath := range unparsableFilePaths {
+					if name == unparsableFilePath {
+						return nil, errors.NewInputError("not a test results file")
+					}
+				}
+
+				// Files earlier in the list take longer to parse
+				for i, testResultsFilePath := range testResultsFilePaths {
+					if name == testResultsFilePath {
+						time.Sleep(time.Duration(len(testResultsFilePaths)-i) * time.Millisecond)
+					}
+				}
+
+				return &v1.TestResults{
+					Framework: v1.RubyRSpecFramework,
+					Tests: []v1.Test{
+						{Name: name, Attempt: v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()}},
+					},
 				}, nil
 			}
+
+			service.API.(*mocks.API).MockUpdateTestResults = func(
+				_ context.Context,
+				_ string,
+				testResults v1.TestResults,
+			) ([]backend.TestResultsUploadResult, error) {
+				uploadedTestResults = &testResults
+				return []backend.TestResultsUploadResult{}, nil
+			}
+
+			mockCommand.MockWait = func() error {
+				return nil
+			}
 		})
 
-		It("exits non-zero and logs an error", func() {
-			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring(
-				"Multiple frameworks detected. The captain CLI only works with one framework at a time",
-			))
+		It("merges the test results in the order of the files", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(uploadedTestResults).NotTo(BeNil())
+
+			names := make([]string, 0)
+			for _, test := range uploadedTestResults.Tests {
+				names = append(names, test.Name)
+			}
+			Expect(names).To(Equal(testResultsFilePaths))
+
+			originalFilePaths := make([]string, 0)
+			for _, originalTestResults := range uploadedTestResults.DerivedFrom {
+				originalFilePaths = append(originalFilePaths, originalTestResults.OriginalFilePath)
+			}
+			Expect(originalFilePaths).To(Equal(testResultsFilePaths))
+		})
+
+		Context("when some of them can't be parsed", func() {
+			BeforeEach(func() {
+				unparsableFilePaths = []string{"two.json", "four.json"}
+			})
+
+			It("reports all of them", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(
+					"Unable to parse 2 of the test results files:\n" +
+						"  Unable to parse \"two.json\" with the available parsers\n" +
+						"  Unable to parse \"four.json\" with the available parsers",
+				))
+			})
 		})
 	})
 })
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rwx-research/captain-cli/internal/backend"
+)
+
+// testIndex allows looking up whether a test is identified by one of a list of quarantined or flaky tests without
+// comparing it to every single one of them. The identified tests are grouped by how they identify tests, so a test
+// only needs to be identified once per group.
+type testIndex struct {
+	groups []testIndexGroup
+}
+
+type testIndexGroup struct {
+	identityComponents          []string
+	strictIdentity              bool
+	testsByCompositeIdentifiers map[string]backend.Test
+}
+
+func newTestIndex(identifiedTests []backend.Test) testIndex {
+	groups := make([]testIndexGroup, 0)
+	groupIndexesByKey := make(map[string]int)
+
+	for _, identifiedTest := range identifiedTests {
+		key := strconv.FormatBool(identifiedTest.StrictIdentity) + "\x00" +
+			strings.Join(identifiedTest.IdentityComponents, "\x00")
+
+		i, ok := groupIndexesByKey[key]
+		if !ok {
+			i = len(groups)
+			groupIndexesByKey[key] = i
+			groups = append(groups, testIndexGroup{
+				identityComponents:          identifiedTest.IdentityComponents,
+				strictIdentity:              identifiedTest.StrictIdentity,
+				testsByCompositeIdentifiers: make(map[string]backend.Test),
+			})
+		}
+
+		// Keep the first test in case the same composite identifier is listed more than once
+		if _, ok := groups[i].testsByCompositeIdentifiers[identifiedTest.CompositeIdentifier]; !ok {
+			groups[i].testsByCompositeIdentifiers[identifiedTest.CompositeIdentifier] = identifiedTest
+		}
+	}
+
+	return testIndex{groups: groups}
+}
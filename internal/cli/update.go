@@ -7,6 +7,7 @@ import (
 	"github.com/rwx-research/captain-cli/internal/backend"
 	"github.com/rwx-research/captain-cli/internal/backend/local"
 	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
 )
 
 func parseFlags(args []string) local.Map {
@@ -157,15 +158,29 @@ func (s Service) UpdateTestResults(
 		return nil, nil
 	}
 
-	parsedResults, err := s.parse(expandedFilepaths, 1)
+	allParsedResults, err := s.parse(expandedFilepaths, 1)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	result, err := s.API.UpdateTestResults(ctx, testSuiteID, *parsedResults)
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to update test results")
+	return s.uploadTestResults(ctx, testSuiteID, allParsedResults)
+}
+
+// uploadTestResults uploads the results of each framework separately
+func (s Service) uploadTestResults(
+	ctx context.Context,
+	testSuiteID string,
+	allTestResults []v1.TestResults,
+) ([]backend.TestResultsUploadResult, error) {
+	var uploadResults []backend.TestResultsUploadResult
+	for _, testResults := range allTestResults {
+		result, err := s.API.UpdateTestResults(ctx, testSuiteID, testResults)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to update test results")
+		}
+
+		uploadResults = append(uploadResults, result...)
 	}
 
-	return result, nil
+	return uploadResults, nil
 }
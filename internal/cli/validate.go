@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"context"
+	"io"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// Validate checks whether the files supplied in `filepaths` are valid RWX v1 JSON and prints every problem it finds.
+func (s Service) Validate(_ context.Context, filepaths []string) error {
+	invalidFiles := 0
+
+	for _, filepath := range filepaths {
+		valid, err := s.validateFile(filepath)
+		if err != nil {
+			return err
+		}
+
+		if !valid {
+			invalidFiles++
+		}
+	}
+
+	if invalidFiles > 0 {
+		return errors.NewInputError("%d of %d files are not valid RWX v1 JSON", invalidFiles, len(filepaths))
+	}
+
+	return nil
+}
+
+func (s Service) validateFile(filepath string) (bool, error) {
+	fd, err := s.FileSystem.Open(filepath)
+	if err != nil {
+		return false, errors.NewSystemError("unable to open file: %s", err)
+	}
+	defer fd.Close()
+
+	document, err := io.ReadAll(fd)
+	if err != nil {
+		return false, errors.NewSystemError("unable to read file %q: %s", filepath, err)
+	}
+
+	validationErrors, err := v1.Validate(document)
+	if err != nil {
+		s.Log.Infof("%s: %s", filepath, err)
+		return false, nil
+	}
+
+	if len(validationErrors) == 0 {
+		s.Log.Infof("%s: valid", filepath)
+		return true, nil
+	}
+
+	for _, validationError := range validationErrors {
+		s.Log.Infof("%s: %s", filepath, validationError)
+	}
+
+	return false, nil
+}
@@ -1,12 +1,26 @@
 package exec
 
-import "io"
+import (
+	"io"
+	"time"
+)
 
-// CommandConfig configures a command for execution
+// CommandConfig configures a command for execution. Once the context is done, the command is signalled. Without a
+// `GracePeriod`, it is killed right away; with one, it is asked to terminate first and only killed if it's still running
+// once the grace period is over. If the context was canceled because captain was interrupted, it receives the same
+// signal as captain instead.
+//
+// If `NewProcessGroup` is set, the command is started in a process group of its own. The whole group is signalled,
+// which takes care of any sub-processes the command spawned, and whatever is left of it is killed once the command
+// exited. Commands sharing captain's process group receive signals sent to the group (e.g. by pressing Ctrl-C in a
+// terminal) directly, so interrupts aren't forwarded to them.
 type CommandConfig struct {
-	Args   []string
-	Env    []string
-	Name   string
-	Stderr io.Writer
-	Stdout io.Writer
+	Args            []string
+	Env             []string
+	GracePeriod     time.Duration
+	Name            string
+	NewProcessGroup bool
+	Stderr          io.Writer
+	Stdin           io.Reader
+	Stdout          io.Writer
 }
@@ -0,0 +1,61 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+)
+
+// InterruptError is the cause of a context that was canceled because captain itself received a termination signal.
+// Commands running under such a context are sent the same signal.
+type InterruptError struct {
+	Signal os.Signal
+}
+
+func (e InterruptError) Error() string {
+	return fmt.Sprintf("received %v", e.Signal)
+}
+
+// ExitCode follows the convention of shells, which exit with 128 plus the number of the signal that terminated them.
+func (e InterruptError) ExitCode() int {
+	if signal, ok := e.Signal.(syscall.Signal); ok {
+		return 128 + int(signal)
+	}
+
+	return 1
+}
+
+// AsInterruptError checks whether the error is an interrupt error
+func AsInterruptError(err error) (InterruptError, bool) {
+	var e InterruptError
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// NotifyContext returns a copy of the context that is canceled with an `InterruptError` once captain receives SIGINT or
+// SIGTERM. Only the first of these signals is caught so that captain can still report any results; a second one
+// terminates captain right away, e.g. when the tests or the upload hang.
+func (l Local) NotifyContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			cancel(InterruptError{Signal: sig})
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
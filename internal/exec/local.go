@@ -19,13 +19,37 @@ func (l Local) NewCommand(ctx context.Context, cfg CommandConfig) (Command, erro
 	cmd := exec.CommandContext(ctx, cfg.Name, cfg.Args...)
 
 	cmd.Stderr = cfg.Stderr
+	cmd.Stdin = cfg.Stdin
 	cmd.Stdout = cfg.Stdout
 
 	for _, override := range cfg.Env {
 		cmd.Env = append(cmd.Environ(), override)
 	}
 
-	return cmd, nil
+	configureCancellation(ctx, cmd, cfg)
+
+	return localCommand{Cmd: cmd, processGroup: cfg.NewProcessGroup}, nil
+}
+
+// localCommand cleans up after any sub-processes in its process group that are still running once the command itself
+// exited
+type localCommand struct {
+	*exec.Cmd
+	processGroup bool
+}
+
+func (c localCommand) Wait() error {
+	err := c.Cmd.Wait()
+	if c.processGroup {
+		reapProcessGroup(c.Cmd)
+	}
+
+	// Sub-processes that outlived a successful command might have kept its output open, which is cleaned up now
+	if errors.Is(err, exec.ErrWaitDelay) {
+		return nil
+	}
+
+	return errors.WithStack(err)
 }
 
 // GetExitStatus extracts the exit code from an error
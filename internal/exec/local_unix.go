@@ -0,0 +1,64 @@
+//go:build !windows
+
+package exec
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+)
+
+// Commands in a process group of their own are signalled as a whole. This takes care of any sub-processes they
+// spawned, like browsers or servers started by a test runner.
+func configureCancellation(ctx context.Context, cmd *exec.Cmd, cfg CommandConfig) {
+	if cfg.NewProcessGroup {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+
+	if cfg.GracePeriod > 0 {
+		// `exec` kills the command itself if it's still running once the grace period is over
+		cmd.WaitDelay = cfg.GracePeriod
+	}
+
+	cmd.Cancel = func() error {
+		signal := syscall.SIGKILL
+		if interrupt, ok := AsInterruptError(context.Cause(ctx)); ok {
+			// The command most likely received the signal already, so it's only killed once the grace period is over
+			if !cfg.NewProcessGroup && cfg.GracePeriod > 0 {
+				return nil
+			}
+
+			if interruptSignal, ok := interrupt.Signal.(syscall.Signal); ok {
+				signal = interruptSignal
+			}
+		} else if cfg.GracePeriod > 0 {
+			signal = syscall.SIGTERM
+		}
+
+		// A negative PID signals the whole process group, which has the same ID as its leader
+		pid := cmd.Process.Pid
+		if cfg.NewProcessGroup {
+			pid = -pid
+		}
+
+		err := syscall.Kill(pid, signal)
+		if errors.Is(err, syscall.ESRCH) {
+			return os.ErrProcessDone
+		}
+
+		return errors.WithStack(err)
+	}
+}
+
+// reapProcessGroup kills whatever is left of the process group once the command exited
+func reapProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+
+	// The group most likely doesn't exist anymore, which isn't worth reporting
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,280 @@
+//go:build !windows
+
+package exec_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/exec"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// The scripts record their PID once they're ready and touch a file when they receive SIGTERM or SIGINT. Grandchildren
+// are embedded in single quotes, so they stick to double quotes.
+const (
+	grandchildScript = `
+		trap "touch \"$1/grandchild-terminated\"; exit 1" TERM
+		trap "touch \"$1/grandchild-interrupted\"; exit 1" INT
+		echo $$ > "$1/grandchild.pid"
+		while :; do sleep 0.01; done
+	`
+	childScript = `
+		trap 'touch "$1/child-terminated"; exit 1' TERM
+		trap 'touch "$1/child-interrupted"; exit 1' INT
+		sh -c '` + grandchildScript + `' sh "$1" &
+		echo $$ > "$1/child.pid"
+		while :; do sleep 0.01; done
+	`
+	// Background jobs of non-interactive shells ignore SIGINT, so this one runs the grandchild in the foreground. It only
+	// handles its own signal once the grandchild exited.
+	foregroundChildScript = `
+		trap 'touch "$1/child-terminated"; exit 1' TERM
+		trap 'touch "$1/child-interrupted"; exit 1' INT
+		echo $$ > "$1/child.pid"
+		sh -c '` + grandchildScript + `' sh "$1"
+	`
+	stubbornGrandchildScript = `
+		trap "touch \"$1/grandchild-terminated\"" TERM
+		echo $$ > "$1/grandchild.pid"
+		while :; do sleep 0.01; done
+	`
+	stubbornChildScript = `
+		trap 'touch "$1/child-terminated"' TERM
+		sh -c '` + stubbornGrandchildScript + `' sh "$1" &
+		echo $$ > "$1/child.pid"
+		while :; do sleep 0.01; done
+	`
+	orphaningChildScript = `
+		sh -c '` + stubbornGrandchildScript + `' sh "$1" &
+		while [ ! -f "$1/grandchild.pid" ]; do sleep 0.01; done
+	`
+)
+
+var _ = Describe("Local", func() {
+	var (
+		dir    string
+		ctx    context.Context
+		cancel context.CancelCauseFunc
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		ctx, cancel = context.WithCancelCause(context.Background())
+		DeferCleanup(func() { cancel(nil) })
+	})
+
+	start := func(script string, cfg exec.CommandConfig) exec.Command {
+		cfg.Name = "sh"
+		cfg.Args = []string{"-c", script, "sh", dir}
+
+		cmd, err := exec.Local{}.NewCommand(ctx, cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cmd.Start()).To(Succeed())
+
+		return cmd
+	}
+
+	pidOf := func(name string) int {
+		var pid int
+		Eventually(func() error {
+			data, err := os.ReadFile(filepath.Join(dir, name+".pid"))
+			if err != nil {
+				return err
+			}
+
+			pid, err = strconv.Atoi(strings.TrimSpace(string(data)))
+			return err
+		}).WithTimeout(5 * time.Second).Should(Succeed())
+
+		// Make sure to not leave anything behind if a test fails
+		DeferCleanup(func() { _ = syscall.Kill(pid, syscall.SIGKILL) })
+		return pid
+	}
+
+	isRunning := func(pid int) bool {
+		if err := syscall.Kill(pid, 0); err != nil {
+			return false
+		}
+
+		// Orphans aren't necessarily reaped in containers, but zombies aren't running anymore either way
+		stat, err := osexec.Command("ps", "-o", "stat=", "-p", strconv.Itoa(pid)).Output()
+		return err == nil && !strings.HasPrefix(strings.TrimSpace(string(stat)), "Z")
+	}
+
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	wait := func(cmd exec.Command) <-chan error {
+		waited := make(chan error, 1)
+		go func() { waited <- cmd.Wait() }()
+		return waited
+	}
+
+	Context("with a process group of its own", func() {
+		It("starts the command as the leader of a new process group", func() {
+			cmd := start(childScript, exec.CommandConfig{NewProcessGroup: true})
+			child := pidOf("child")
+			grandchild := pidOf("grandchild")
+
+			Expect(syscall.Getpgid(child)).To(Equal(child))
+			Expect(syscall.Getpgid(grandchild)).To(Equal(child))
+			Expect(syscall.Getpgid(child)).NotTo(Equal(syscall.Getpgrp()))
+
+			cancel(nil)
+			Eventually(wait(cmd)).WithTimeout(5 * time.Second).Should(Receive())
+		})
+
+		It("terminates the command and its sub-processes during the grace period", func() {
+			cmd := start(foregroundChildScript, exec.CommandConfig{NewProcessGroup: true, GracePeriod: time.Minute})
+			child := pidOf("child")
+			grandchild := pidOf("grandchild")
+
+			cancel(nil)
+			Eventually(wait(cmd)).WithTimeout(5 * time.Second).Should(Receive())
+
+			Expect(exists("child-terminated")).To(BeTrue())
+			Expect(exists("grandchild-terminated")).To(BeTrue())
+			Eventually(func() bool { return isRunning(child) || isRunning(grandchild) }).Should(BeFalse())
+		})
+
+		It("kills the command and its sub-processes once the grace period is over", func() {
+			gracePeriod := 200 * time.Millisecond
+			cmd := start(stubbornChildScript, exec.CommandConfig{NewProcessGroup: true, GracePeriod: gracePeriod})
+			child := pidOf("child")
+			grandchild := pidOf("grandchild")
+
+			canceledAt := time.Now()
+			cancel(nil)
+
+			var err error
+			Eventually(wait(cmd)).WithTimeout(5 * time.Second).Should(Receive(&err))
+			Expect(time.Since(canceledAt)).To(BeNumerically(">=", gracePeriod))
+			Expect(err).To(HaveOccurred())
+
+			// Both were asked to terminate first
+			Expect(exists("child-terminated")).To(BeTrue())
+			Expect(exists("grandchild-terminated")).To(BeTrue())
+			Eventually(func() bool { return isRunning(child) || isRunning(grandchild) }).Should(BeFalse())
+		})
+
+		It("kills the command right away without a grace period", func() {
+			cmd := start(childScript, exec.CommandConfig{NewProcessGroup: true})
+			child := pidOf("child")
+			grandchild := pidOf("grandchild")
+
+			cancel(nil)
+			Eventually(wait(cmd)).WithTimeout(5 * time.Second).Should(Receive())
+
+			Expect(exists("child-terminated")).To(BeFalse())
+			Expect(exists("grandchild-terminated")).To(BeFalse())
+			Eventually(func() bool { return isRunning(child) || isRunning(grandchild) }).Should(BeFalse())
+		})
+
+		It("forwards the signal captain was interrupted with", func() {
+			cmd := start(foregroundChildScript, exec.CommandConfig{NewProcessGroup: true, GracePeriod: time.Minute})
+			child := pidOf("child")
+			grandchild := pidOf("grandchild")
+
+			cancel(exec.InterruptError{Signal: syscall.SIGINT})
+			Eventually(wait(cmd)).WithTimeout(5 * time.Second).Should(Receive())
+
+			Expect(exists("child-interrupted")).To(BeTrue())
+			Expect(exists("grandchild-interrupted")).To(BeTrue())
+			Expect(exists("child-terminated")).To(BeFalse())
+			Eventually(func() bool { return isRunning(child) || isRunning(grandchild) }).Should(BeFalse())
+		})
+
+		It("doesn't leave any orphans behind once the command exited", func() {
+			cmd := start(orphaningChildScript, exec.CommandConfig{NewProcessGroup: true})
+			grandchild := pidOf("grandchild")
+
+			Eventually(wait(cmd)).WithTimeout(5 * time.Second).Should(Receive(BeNil()))
+			Eventually(func() bool { return isRunning(grandchild) }).Should(BeFalse())
+		})
+	})
+
+	Context("in captain's process group", func() {
+		It("only signals the command itself", func() {
+			cmd := start(childScript, exec.CommandConfig{GracePeriod: time.Minute})
+			child := pidOf("child")
+			grandchild := pidOf("grandchild")
+
+			Expect(syscall.Getpgid(child)).To(Equal(syscall.Getpgrp()))
+
+			cancel(nil)
+			Eventually(wait(cmd)).WithTimeout(5 * time.Second).Should(Receive())
+
+			Expect(exists("child-terminated")).To(BeTrue())
+			Expect(exists("grandchild-terminated")).To(BeFalse())
+			Expect(isRunning(grandchild)).To(BeTrue())
+		})
+	})
+
+	Describe("NotifyContext", func() {
+		It("cancels the context on the first signal and lets a second one terminate captain", func() {
+			// Signals are sent to a process of its own, since a second one would terminate the test suite
+			//nolint:gosec // The test binary runs itself
+			helper := osexec.Command(os.Args[0], "-test.run=^TestNotifyContextHelper$")
+			helper.Env = append(os.Environ(), "CAPTAIN_NOTIFY_CONTEXT_HELPER=1")
+			stdout, err := helper.StdoutPipe()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(helper.Start()).To(Succeed())
+			DeferCleanup(func() { _ = helper.Process.Kill() })
+
+			lines := make(chan string, 2)
+			go func() {
+				scanner := bufio.NewScanner(stdout)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}()
+			Eventually(lines).WithTimeout(5 * time.Second).Should(Receive(Equal("ready")))
+
+			Expect(helper.Process.Signal(syscall.SIGTERM)).To(Succeed())
+			Eventually(lines).WithTimeout(5 * time.Second).Should(Receive(Equal("received terminated")))
+
+			Expect(helper.Process.Signal(syscall.SIGTERM)).To(Succeed())
+			err = helper.Wait()
+			Expect(err).To(HaveOccurred())
+
+			status, ok := helper.ProcessState.Sys().(syscall.WaitStatus)
+			Expect(ok).To(BeTrue())
+			Expect(status.Signaled()).To(BeTrue())
+			Expect(status.Signal()).To(Equal(syscall.SIGTERM))
+		})
+	})
+})
+
+// TestNotifyContextHelper is run by the NotifyContext spec in a process of its own. It waits for the first signal and
+// then keeps running until it's terminated.
+func TestNotifyContextHelper(t *testing.T) {
+	t.Parallel()
+
+	if os.Getenv("CAPTAIN_NOTIFY_CONTEXT_HELPER") == "" {
+		t.Skip("only run by the NotifyContext spec")
+	}
+
+	ctx, stop := exec.Local{}.NotifyContext(context.Background())
+	defer stop()
+
+	fmt.Println("ready")
+	<-ctx.Done()
+	fmt.Println(context.Cause(ctx))
+
+	time.Sleep(time.Minute)
+	t.Fatal("a second signal should have terminated the process")
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package exec
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Windows has neither process groups nor termination signals in the same sense, so the command itself is killed right
+// away once the context is done.
+func configureCancellation(_ context.Context, _ *exec.Cmd, _ CommandConfig) {}
+
+func reapProcessGroup(_ *exec.Cmd) {}
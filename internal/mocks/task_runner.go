@@ -11,6 +11,7 @@ import (
 type TaskRunner struct {
 	MockNewCommand             func(ctx context.Context, cfg exec.CommandConfig) (exec.Command, error)
 	MockGetExitStatusFromError func(error) (int, error)
+	MockNotifyContext          func(ctx context.Context) (context.Context, context.CancelFunc)
 }
 
 // NewCommand either calls the configured mock of itself or returns an error if that doesn't exist.
@@ -30,3 +31,12 @@ func (t *TaskRunner) GetExitStatusFromError(err error) (int, error) {
 
 	return 0, errors.NewInternalError("MockGetExitStatusFromError was not configured")
 }
+
+// NotifyContext either calls the configured mock of itself or returns a context that is never interrupted.
+func (t *TaskRunner) NotifyContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if t.MockNotifyContext != nil {
+		return t.MockNotifyContext(ctx)
+	}
+
+	return context.WithCancel(ctx)
+}
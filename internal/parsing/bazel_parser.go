@@ -0,0 +1,237 @@
+package parsing
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	"github.com/rwx-research/captain-cli/internal/fs"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// Parses the `test.xml` files Bazel writes into `bazel-testlogs`. Bazel either copies the XML written by the test
+// runner or generates a single test case for the whole target, so the contents are plain JUnit XML. What Bazel adds
+// is the target label, which can only be derived from the location of the file.
+// https://bazel.build/reference/test-encyclopedia
+type BazelParser struct {
+	Target string
+}
+
+// BazelTarget identifies a single execution of a Bazel test target, i.e. one shard of one run
+type BazelTarget struct {
+	Label string
+	Run   int
+	Shard int
+}
+
+// BazelTestAttempt is a single attempt of a test target as reported by the Build Event Protocol. Attempts other than
+// the last one are only reported when `--flaky_test_attempts` is used.
+type BazelTestAttempt struct {
+	Target      BazelTarget
+	Attempt     int
+	Status      string
+	TestXMLPath string
+}
+
+type BazelFile struct {
+	Name string `json:"name"`
+	URI  string `json:"uri"`
+}
+
+type BazelTestResultID struct {
+	Label   string `json:"label"`
+	Run     int    `json:"run"`
+	Shard   int    `json:"shard"`
+	Attempt int    `json:"attempt"`
+}
+
+type BazelTestResult struct {
+	Status           string      `json:"status"`
+	TestActionOutput []BazelFile `json:"testActionOutput"`
+}
+
+type BazelBuildEvent struct {
+	ID struct {
+		TestResult *BazelTestResultID `json:"testResult"`
+	} `json:"id"`
+	TestResult *BazelTestResult `json:"testResult"`
+}
+
+// Matches the directories Bazel creates when a target is sharded and/or run multiple times, e.g. `shard_1_of_3`,
+// `run_2_of_5` or `shard_1_of_3_run_2_of_5`
+var bazelRunAndShardRegexp = regexp.MustCompile(`^(?:shard_(\d+)_of_\d+(?:_run_(\d+)_of_\d+)?|run_(\d+)_of_\d+)$`)
+
+func (p BazelParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	// Bazel writes `<testsuites>`, but test runners that write their own `test.xml` may use a single `<testsuite>`
+	reader := bufio.NewReaderSize(data, sniffLength)
+	header, err := reader.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, errors.NewSystemError("Unable to read test results: %s", err)
+	}
+
+	var testResults *v1.TestResults
+	if sniffXML(header, "testsuite") {
+		testResults, err = JUnitTestsuiteParser{}.Parse(reader)
+	} else {
+		testResults, err = JUnitTestsuitesParser{}.Parse(reader)
+	}
+	if err != nil {
+		return nil, errors.NewInputError("Unable to parse the Bazel test.xml as JUnit XML: %s", err)
+	}
+
+	tests := make([]v1.Test, 0, len(testResults.Tests))
+	for _, test := range testResults.Tests {
+		if p.Target != "" {
+			target := p.Target
+			meta := map[string]any{"target": target}
+			for key, value := range test.Attempt.Meta {
+				meta[key] = value
+			}
+
+			test.Scope = &target
+			test.Attempt.Meta = meta
+		}
+
+		tests = append(tests, test)
+	}
+
+	return v1.NewTestResults(
+		v1.BazelFramework,
+		tests,
+		testResults.OtherErrors,
+	), nil
+}
+
+// ParseBazel parses a `test.xml` with the `BazelParser`, attributing all tests to `target`
+func ParseBazel(file fs.File, groupNumber int, target BazelTarget, cfg Config) (*v1.TestResults, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	allResults, err := parseWith(file, []Parser{BazelParser{Target: target.Label}}, groupNumber, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &allResults[0], nil
+}
+
+// NewBazelTarget derives the target from the location of a `test.xml` within `bazel-testlogs`, e.g.
+// `bazel-testlogs/app/models/user_test/shard_1_of_2/test.xml` was written by the first shard of
+// `//app/models:user_test`
+func NewBazelTarget(testlogsPath string, testXMLPath string) (BazelTarget, error) {
+	absoluteTestlogsPath, err := filepath.Abs(testlogsPath)
+	if err != nil {
+		return BazelTarget{}, errors.NewSystemError("Unable to determine absolute path of %q: %s", testlogsPath, err)
+	}
+
+	absoluteTestXMLPath, err := filepath.Abs(testXMLPath)
+	if err != nil {
+		return BazelTarget{}, errors.NewSystemError("Unable to determine absolute path of %q: %s", testXMLPath, err)
+	}
+
+	relativePath, err := filepath.Rel(absoluteTestlogsPath, filepath.Dir(absoluteTestXMLPath))
+	if err != nil || relativePath == "." || strings.HasPrefix(relativePath, "..") {
+		return BazelTarget{}, errors.NewInputError("%q is not located within %q", testXMLPath, testlogsPath)
+	}
+
+	target := BazelTarget{Run: 1, Shard: 1}
+	components := strings.Split(filepath.ToSlash(relativePath), "/")
+
+	if match := bazelRunAndShardRegexp.FindStringSubmatch(components[len(components)-1]); match != nil {
+		if shard, err := strconv.Atoi(match[1]); err == nil {
+			target.Shard = shard
+		}
+		if run, err := strconv.Atoi(match[2] + match[3]); err == nil {
+			target.Run = run
+		}
+		components = components[:len(components)-1]
+	}
+
+	repository := ""
+	if len(components) > 2 && components[0] == "external" {
+		repository = "@" + components[1]
+		components = components[2:]
+	}
+
+	if len(components) == 0 {
+		return BazelTarget{}, errors.NewInputError("Unable to determine the Bazel target of %q", testXMLPath)
+	}
+
+	target.Label = repository + "//" + strings.Join(components[:len(components)-1], "/") + ":" +
+		components[len(components)-1]
+
+	return target, nil
+}
+
+// ParseBazelBuildEvents reads the test attempts from a file written by `--build_event_json_file`
+// https://bazel.build/remote/bep
+func ParseBazelBuildEvents(data io.Reader) ([]BazelTestAttempt, error) {
+	attempts := make([]BazelTestAttempt, 0)
+
+	scanner := bufio.NewScanner(data)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var event BazelBuildEvent
+		if err := json.Unmarshal([]byte(text), &event); err != nil {
+			return nil, errors.NewInputError("Unable to parse the Bazel build event %q: %s", text, err)
+		}
+
+		if event.ID.TestResult == nil || event.TestResult == nil {
+			continue
+		}
+
+		// Runs & shards are numbered from 1, but zero values are omitted from the JSON altogether
+		target := BazelTarget{
+			Label: event.ID.TestResult.Label,
+			Run:   event.ID.TestResult.Run,
+			Shard: event.ID.TestResult.Shard,
+		}
+		if target.Run == 0 {
+			target.Run = 1
+		}
+		if target.Shard == 0 {
+			target.Shard = 1
+		}
+
+		attempt := BazelTestAttempt{
+			Target:  target,
+			Attempt: event.ID.TestResult.Attempt,
+			Status:  event.TestResult.Status,
+		}
+
+		// Outputs uploaded to a remote cache are referenced via `bytestream://`, which we cannot read
+		for _, output := range event.TestResult.TestActionOutput {
+			if output.Name != "test.xml" {
+				continue
+			}
+
+			if uri, err := url.Parse(output.URI); err == nil && uri.Scheme == "file" {
+				attempt.TestXMLPath = uri.Path
+			}
+		}
+
+		attempts = append(attempts, attempt)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewInputError("Unable to read Bazel build events: %s", err)
+	}
+
+	sort.SliceStable(attempts, func(i, j int) bool {
+		return attempts[i].Attempt < attempts[j].Attempt
+	})
+
+	return attempts, nil
+}
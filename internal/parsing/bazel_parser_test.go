@@ -0,0 +1,194 @@
+package parsing_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/bradleyjkemp/cupaloy"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BazelParser", func() {
+	Describe("Parse", func() {
+		It("parses the sample file", func() {
+			fixture, err := os.Open("../../test/fixtures/bazel-testlogs/app/models/user_test/test.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.BazelParser{Target: "//app/models:user_test"}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			rwxJSON, err := json.MarshalIndent(testResults, "", "  ")
+			Expect(err).ToNot(HaveOccurred())
+			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
+		})
+
+		It("attributes every test to the target", func() {
+			fixture, err := os.Open("../../test/fixtures/bazel-testlogs/app/models/user_test/test.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.BazelParser{Target: "//app/models:user_test"}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Framework).To(Equal(v1.BazelFramework))
+			Expect(testResults.Summary.Tests).To(Equal(3))
+			Expect(testResults.Summary.Successful).To(Equal(1))
+			Expect(testResults.Summary.Failed).To(Equal(1))
+			Expect(testResults.Summary.Skipped).To(Equal(1))
+
+			for _, test := range testResults.Tests {
+				Expect(*test.Scope).To(Equal("//app/models:user_test"))
+				Expect(test.Attempt.Meta["target"]).To(Equal("//app/models:user_test"))
+			}
+		})
+
+		It("parses the test.xml Bazel generates for test runners without JUnit output", func() {
+			fixture, err := os.Open("../../test/fixtures/bazel-testlogs/external/rules_smoke/tests/smoke_test/test.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.BazelParser{Target: "@rules_smoke//tests:smoke_test"}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Summary.Tests).To(Equal(1))
+			Expect(testResults.Summary.Failed).To(Equal(1))
+			Expect(testResults.Tests[0].Name).To(Equal("@rules_smoke//tests:smoke_test"))
+			Expect(*testResults.Tests[0].Attempt.Status.Message).To(Equal("exited with error code 1"))
+		})
+
+		It("leaves the scope untouched without a target", func() {
+			fixture, err := os.Open("../../test/fixtures/bazel-testlogs/lib/retry_test/test.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.BazelParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, test := range testResults.Tests {
+				Expect(test.Scope).To(BeNil())
+				Expect(test.Attempt.Meta).NotTo(HaveKey("target"))
+			}
+		})
+
+		It("errors on malformed XML", func() {
+			testResults, err := parsing.BazelParser{Target: "//app:test"}.Parse(strings.NewReader("<abc"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unable to parse the Bazel test.xml as JUnit XML"))
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors on JSON", func() {
+			fixture, err := os.Open("../../test/fixtures/jest.json")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.BazelParser{Target: "//app:test"}.Parse(fixture)
+			Expect(err).To(HaveOccurred())
+			Expect(testResults).To(BeNil())
+		})
+	})
+
+	Describe("ParseBazel", func() {
+		It("keeps the original file", func() {
+			fixture, err := os.Open("../../test/fixtures/bazel-testlogs/lib/retry_test/test.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.ParseBazel(
+				fixture,
+				1,
+				parsing.BazelTarget{Label: "//lib:retry_test", Run: 1, Shard: 1},
+				parsing.Config{Logger: zaptest.NewLogger(GinkgoT()).Sugar()},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(2))
+			Expect(testResults.DerivedFrom).To(HaveLen(1))
+			Expect(testResults.DerivedFrom[0].OriginalFilePath).To(HaveSuffix("lib/retry_test/test.xml"))
+		})
+	})
+
+	Describe("NewBazelTarget", func() {
+		It("derives the label from the path", func() {
+			target, err := parsing.NewBazelTarget("bazel-testlogs", "bazel-testlogs/app/models/user_test/test.xml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target).To(Equal(parsing.BazelTarget{Label: "//app/models:user_test", Run: 1, Shard: 1}))
+		})
+
+		It("derives the label of targets in the root package", func() {
+			target, err := parsing.NewBazelTarget("bazel-testlogs/", "bazel-testlogs/smoke_test/test.xml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target.Label).To(Equal("//:smoke_test"))
+		})
+
+		It("derives the label of targets in external repositories", func() {
+			target, err := parsing.NewBazelTarget(
+				"bazel-testlogs",
+				"bazel-testlogs/external/rules_smoke/tests/smoke_test/test.xml",
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target.Label).To(Equal("@rules_smoke//tests:smoke_test"))
+		})
+
+		It("derives the shard and run", func() {
+			target, err := parsing.NewBazelTarget(
+				"bazel-testlogs",
+				"bazel-testlogs/app/util/strings_test/shard_2_of_2/test.xml",
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target).To(Equal(parsing.BazelTarget{Label: "//app/util:strings_test", Run: 1, Shard: 2}))
+
+			target, err = parsing.NewBazelTarget("bazel-testlogs", "bazel-testlogs/app/util/strings_test/run_3_of_5/test.xml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target).To(Equal(parsing.BazelTarget{Label: "//app/util:strings_test", Run: 3, Shard: 1}))
+
+			target, err = parsing.NewBazelTarget(
+				"bazel-testlogs",
+				"bazel-testlogs/app/util/strings_test/shard_2_of_2_run_3_of_5/test.xml",
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target).To(Equal(parsing.BazelTarget{Label: "//app/util:strings_test", Run: 3, Shard: 2}))
+		})
+
+		It("errors for files outside of the test logs", func() {
+			_, err := parsing.NewBazelTarget("bazel-testlogs", "reports/test.xml")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is not located within"))
+
+			_, err = parsing.NewBazelTarget("bazel-testlogs", "bazel-testlogs/test.xml")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ParseBazelBuildEvents", func() {
+		It("parses the test attempts", func() {
+			fixture, err := os.Open("../../test/fixtures/bazel_build_events.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+
+			attempts, err := parsing.ParseBazelBuildEvents(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(attempts).To(HaveLen(3))
+
+			Expect(attempts[0].Target).To(Equal(parsing.BazelTarget{Label: "//lib:retry_test", Run: 1, Shard: 1}))
+			Expect(attempts[0].Attempt).To(Equal(1))
+			Expect(attempts[0].Status).To(Equal("FAILED"))
+			Expect(attempts[0].TestXMLPath).To(HaveSuffix("/testlogs/lib/retry_test/test_attempts/attempt_1.xml"))
+		})
+
+		It("ignores outputs that are not available locally", func() {
+			fixture, err := os.Open("../../test/fixtures/bazel_build_events.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+
+			attempts, err := parsing.ParseBazelBuildEvents(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(attempts[1].Target).To(Equal(parsing.BazelTarget{Label: "//app/util:strings_test", Run: 1, Shard: 2}))
+			Expect(attempts[1].TestXMLPath).To(BeEmpty())
+		})
+
+		It("errors on malformed JSON", func() {
+			attempts, err := parsing.ParseBazelBuildEvents(strings.NewReader("{\"id\":"))
+			Expect(err).To(HaveOccurred())
+			Expect(attempts).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,316 @@
+package parsing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// Parses both `--gtest_output=json` and `--gtest_output=xml`
+// https://google.github.io/googletest/advanced.html#generating-a-json-report
+type CppGoogleTestParser struct{}
+
+type CppGoogleTestFailure struct {
+	Failure string `json:"failure"`
+	Type    string `json:"type"`
+}
+
+type CppGoogleTestSkipped struct {
+	Message string `json:"message"`
+}
+
+type CppGoogleTestTestCase struct {
+	ClassName  string                 `json:"classname"`
+	Failures   []CppGoogleTestFailure `json:"failures"`
+	File       *string                `json:"file"`
+	Line       *int                   `json:"line"`
+	Name       string                 `json:"name"`
+	Result     *string                `json:"result"`
+	Skipped    []CppGoogleTestSkipped `json:"skipped"`
+	Status     *string                `json:"status"`
+	Time       string                 `json:"time"`
+	TypeParam  *string                `json:"type_param"`
+	ValueParam *string                `json:"value_param"`
+}
+
+type CppGoogleTestTestSuite struct {
+	Name      string                  `json:"name"`
+	TestCases []CppGoogleTestTestCase `json:"testsuite"`
+}
+
+type CppGoogleTestTestResults struct {
+	Name       string                    `json:"name"`
+	Tests      *int                      `json:"tests"`
+	TestSuites []*CppGoogleTestTestSuite `json:"testsuites"`
+}
+
+type CppGoogleTestXMLFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+type CppGoogleTestXMLSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type CppGoogleTestXMLTestCase struct {
+	ClassName  string                    `xml:"classname,attr"`
+	Failures   []CppGoogleTestXMLFailure `xml:"failure"`
+	File       *string                   `xml:"file,attr"`
+	Line       *int                      `xml:"line,attr"`
+	Name       string                    `xml:"name,attr"`
+	Result     *string                   `xml:"result,attr"`
+	Skipped    []CppGoogleTestXMLSkipped `xml:"skipped"`
+	Status     *string                   `xml:"status,attr"`
+	Time       string                    `xml:"time,attr"`
+	TypeParam  *string                   `xml:"type_param,attr"`
+	ValueParam *string                   `xml:"value_param,attr"`
+}
+
+type CppGoogleTestXMLTestSuite struct {
+	Name      string                     `xml:"name,attr"`
+	TestCases []CppGoogleTestXMLTestCase `xml:"testcase"`
+}
+
+type CppGoogleTestXMLTestResults struct {
+	Tests      *int                        `xml:"tests,attr"`
+	TestSuites []CppGoogleTestXMLTestSuite `xml:"testsuite"`
+	XMLName    xml.Name                    `xml:"testsuites"`
+}
+
+var cppGoogleTestLocationRegexp = regexp.MustCompile(`^(.+):(\d+)$`)
+
+func (p CppGoogleTestParser) Sniff(header []byte) bool {
+	return sniffJSON(header) || sniffXML(header, "testsuites")
+}
+
+func (p CppGoogleTestParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	reader := bufio.NewReaderSize(data, sniffLength)
+	header, err := reader.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, errors.NewSystemError("Unable to read test results: %s", err)
+	}
+
+	var testResults CppGoogleTestTestResults
+	if bytes.HasPrefix(bytes.TrimSpace(header), []byte("<")) {
+		testResults, err = p.fromXML(reader)
+	} else {
+		testResults, err = p.fromJSON(reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tests := make([]v1.Test, 0)
+	for _, testSuite := range testResults.TestSuites {
+		for _, testCase := range testSuite.TestCases {
+			test, err := p.newTest(testSuite.Name, testCase)
+			if err != nil {
+				return nil, err
+			}
+
+			tests = append(tests, test)
+		}
+	}
+
+	return v1.NewTestResults(
+		v1.CppGoogleTestFramework,
+		tests,
+		nil,
+	), nil
+}
+
+func (p CppGoogleTestParser) fromJSON(data io.Reader) (CppGoogleTestTestResults, error) {
+	var testResults CppGoogleTestTestResults
+	if err := json.NewDecoder(data).Decode(&testResults); err != nil {
+		return testResults, errors.NewInputError("Unable to parse test results as JSON: %s", err)
+	}
+
+	if testResults.Tests == nil || testResults.TestSuites == nil {
+		return testResults, errors.NewInputError("The JSON does not look like a googletest report")
+	}
+
+	for _, testSuite := range testResults.TestSuites {
+		if testSuite == nil || testSuite.TestCases == nil {
+			return testResults, errors.NewInputError("The JSON does not look like a googletest report")
+		}
+
+		if err := p.validateTestCases(testSuite.TestCases); err != nil {
+			return testResults, err
+		}
+	}
+
+	return testResults, nil
+}
+
+func (p CppGoogleTestParser) fromXML(data io.Reader) (CppGoogleTestTestResults, error) {
+	var xmlTestResults CppGoogleTestXMLTestResults
+	if err := xml.NewDecoder(data).Decode(&xmlTestResults); err != nil {
+		return CppGoogleTestTestResults{}, errors.NewInputError("Unable to parse test results as XML: %s", err)
+	}
+
+	if xmlTestResults.Tests == nil || len(xmlTestResults.TestSuites) == 0 {
+		return CppGoogleTestTestResults{}, errors.NewInputError("The XML does not look like a googletest report")
+	}
+
+	testResults := CppGoogleTestTestResults{Tests: xmlTestResults.Tests}
+	for _, xmlTestSuite := range xmlTestResults.TestSuites {
+		testSuite := CppGoogleTestTestSuite{Name: xmlTestSuite.Name}
+
+		for _, xmlTestCase := range xmlTestSuite.TestCases {
+			testCase := CppGoogleTestTestCase{
+				ClassName:  xmlTestCase.ClassName,
+				File:       xmlTestCase.File,
+				Line:       xmlTestCase.Line,
+				Name:       xmlTestCase.Name,
+				Result:     xmlTestCase.Result,
+				Status:     xmlTestCase.Status,
+				Time:       xmlTestCase.Time,
+				TypeParam:  xmlTestCase.TypeParam,
+				ValueParam: xmlTestCase.ValueParam,
+			}
+
+			for _, failure := range xmlTestCase.Failures {
+				contents := failure.Contents
+				if strings.TrimSpace(contents) == "" {
+					contents = failure.Message
+				}
+				testCase.Failures = append(testCase.Failures, CppGoogleTestFailure{Failure: contents, Type: failure.Type})
+			}
+
+			for _, skipped := range xmlTestCase.Skipped {
+				testCase.Skipped = append(testCase.Skipped, CppGoogleTestSkipped(skipped))
+			}
+
+			testSuite.TestCases = append(testSuite.TestCases, testCase)
+		}
+
+		if err := p.validateTestCases(testSuite.TestCases); err != nil {
+			return testResults, err
+		}
+
+		testResults.TestSuites = append(testResults.TestSuites, &testSuite)
+	}
+
+	return testResults, nil
+}
+
+// Plain JUnit XML has no notion of `status` & `result`, so they tell us this was written by googletest
+func (p CppGoogleTestParser) validateTestCases(testCases []CppGoogleTestTestCase) error {
+	for _, testCase := range testCases {
+		if testCase.Status == nil || testCase.Result == nil {
+			return errors.NewInputError("The test %q does not look like it was run by googletest", testCase.Name)
+		}
+	}
+
+	return nil
+}
+
+func (p CppGoogleTestParser) newTest(testSuiteName string, testCase CppGoogleTestTestCase) (v1.Test, error) {
+	var duration *time.Duration
+	if testCase.Time != "" {
+		seconds, err := strconv.ParseFloat(strings.TrimSuffix(testCase.Time, "s"), 64)
+		if err != nil {
+			return v1.Test{}, errors.NewInputError("Unable to parse test duration %q: %s", testCase.Time, err)
+		}
+		parsedDuration := time.Duration(math.Round(seconds * float64(time.Second)))
+		duration = &parsedDuration
+	}
+
+	var status v1.TestStatus
+	switch {
+	case len(testCase.Failures) > 0:
+		status = p.newFailedTestStatus(testCase.Failures)
+	case strings.EqualFold(*testCase.Result, "skipped"):
+		var message *string
+		if len(testCase.Skipped) > 0 {
+			_, skippedMessage := p.splitLocation(testCase.Skipped[0].Message)
+			if skippedMessage != "" {
+				message = &skippedMessage
+			}
+		}
+		status = v1.NewSkippedTestStatus(message)
+	case strings.EqualFold(*testCase.Status, "notrun") || strings.EqualFold(*testCase.Result, "suppressed"):
+		// Tests prefixed with DISABLED_
+		message := "disabled"
+		status = v1.NewSkippedTestStatus(&message)
+	default:
+		status = v1.NewSuccessfulTestStatus()
+	}
+
+	var location *v1.Location
+	if testCase.File != nil {
+		location = &v1.Location{File: *testCase.File, Line: testCase.Line}
+	}
+
+	meta := map[string]any{}
+	if testCase.TypeParam != nil {
+		meta["type_param"] = *testCase.TypeParam
+	}
+	if testCase.ValueParam != nil {
+		meta["value_param"] = *testCase.ValueParam
+	}
+	if len(meta) == 0 {
+		meta = nil
+	}
+
+	return v1.Test{
+		Name:     fmt.Sprintf("%s.%s", testSuiteName, testCase.Name),
+		Lineage:  []string{testSuiteName, testCase.Name},
+		Location: location,
+		Attempt: v1.TestAttempt{
+			Duration: duration,
+			Meta:     meta,
+			Status:   status,
+		},
+	}, nil
+}
+
+func (p CppGoogleTestParser) newFailedTestStatus(failures []CppGoogleTestFailure) v1.TestStatus {
+	messages := make([]string, 0, len(failures))
+	backtrace := make([]string, 0, len(failures))
+
+	for _, failure := range failures {
+		location, message := p.splitLocation(failure.Failure)
+		if location != "" {
+			backtrace = append(backtrace, location)
+		}
+		if message != "" {
+			messages = append(messages, message)
+		}
+	}
+
+	var message *string
+	if len(messages) > 0 {
+		joinedMessages := strings.Join(messages, "\n\n")
+		message = &joinedMessages
+	}
+
+	if len(backtrace) == 0 {
+		backtrace = nil
+	}
+
+	return v1.NewFailedTestStatus(message, nil, backtrace)
+}
+
+// Failures and skips start with the location of the assertion or skip, e.g. `math_test.cc:12`
+func (p CppGoogleTestParser) splitLocation(text string) (string, string) {
+	lines := strings.SplitN(strings.TrimSpace(text), "\n", 2)
+	if len(lines) == 0 || !cppGoogleTestLocationRegexp.MatchString(lines[0]) {
+		return "", strings.TrimSpace(text)
+	}
+
+	return lines[0], strings.TrimSpace(strings.Join(lines[1:], "\n"))
+}
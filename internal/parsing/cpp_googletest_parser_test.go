@@ -0,0 +1,112 @@
+package parsing_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CppGoogleTestParser", func() {
+	Describe("Parse", func() {
+		It("parses the sample JSON file", func() {
+			fixture, err := os.Open("../../test/fixtures/googletest.json")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.CppGoogleTestParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			rwxJSON, err := json.MarshalIndent(testResults, "", "  ")
+			Expect(err).ToNot(HaveOccurred())
+			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
+		})
+
+		It("parses the XML equivalently to the JSON", func() {
+			jsonFixture, err := os.Open("../../test/fixtures/googletest.json")
+			Expect(err).ToNot(HaveOccurred())
+			jsonTestResults, err := parsing.CppGoogleTestParser{}.Parse(jsonFixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			xmlFixture, err := os.Open("../../test/fixtures/googletest.xml")
+			Expect(err).ToNot(HaveOccurred())
+			xmlTestResults, err := parsing.CppGoogleTestParser{}.Parse(xmlFixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(xmlTestResults).To(Equal(jsonTestResults))
+		})
+
+		It("maps the test suite and test name into the lineage", func() {
+			fixture, err := os.Open("../../test/fixtures/googletest.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.CppGoogleTestParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Framework).To(Equal(v1.CppGoogleTestFramework))
+			Expect(testResults.Summary.Tests).To(Equal(6))
+			Expect(testResults.Summary.Successful).To(Equal(2))
+			Expect(testResults.Summary.Failed).To(Equal(2))
+			Expect(testResults.Summary.Skipped).To(Equal(2))
+
+			failedTest := testResults.Tests[5]
+			Expect(failedTest.Name).To(Equal("Primes/PrimeTest.IsPrime/1"))
+			Expect(failedTest.Lineage).To(Equal([]string{"Primes/PrimeTest", "IsPrime/1"}))
+			Expect(failedTest.Attempt.Meta).To(Equal(map[string]any{"value_param": "9"}))
+			Expect(*failedTest.Attempt.Status.Message).To(Equal(
+				"Value of: IsPrime(GetParam())\n  Actual: true\nExpected: false",
+			))
+			Expect(failedTest.Attempt.Status.Backtrace).To(Equal([]string{"tests/prime_test.cc:11"}))
+
+			skippedTest := testResults.Tests[2]
+			Expect(*skippedTest.Attempt.Status.Message).To(Equal("not supported on this platform"))
+		})
+
+		It("errors on malformed input", func() {
+			testResults, err := parsing.CppGoogleTestParser{}.Parse(strings.NewReader(`{`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unable to parse test results as JSON"))
+			Expect(testResults).To(BeNil())
+
+			testResults, err = parsing.CppGoogleTestParser{}.Parse(strings.NewReader(`<abc`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unable to parse test results as XML"))
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors on JSON that doesn't look like googletest", func() {
+			for _, path := range []string{
+				"../../test/fixtures/jest.json",
+				"../../test/fixtures/mocha.json",
+				"../../test/fixtures/rspec.json",
+			} {
+				fixture, err := os.Open(path)
+				Expect(err).ToNot(HaveOccurred())
+
+				testResults, err := parsing.CppGoogleTestParser{}.Parse(fixture)
+				Expect(err).To(HaveOccurred(), path)
+				Expect(testResults).To(BeNil())
+			}
+		})
+
+		It("errors on JUnit XML that wasn't written by googletest", func() {
+			for _, path := range []string{
+				"../../test/fixtures/junit.xml",
+				"../../test/fixtures/surefire.xml",
+				"../../test/fixtures/phpunit.xml",
+			} {
+				fixture, err := os.Open(path)
+				Expect(err).ToNot(HaveOccurred())
+
+				testResults, err := parsing.CppGoogleTestParser{}.Parse(fixture)
+				Expect(err).To(HaveOccurred(), path)
+				Expect(testResults).To(BeNil())
+			}
+		})
+	})
+})
@@ -0,0 +1,394 @@
+package parsing
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// Parses the Common Test Report Format
+// https://ctrf.io/docs/specification/overview
+type CTRFParser struct{}
+
+type CTRFTool struct {
+	Name    string         `json:"name"`
+	Version *string        `json:"version,omitempty"`
+	Extra   map[string]any `json:"extra,omitempty"`
+}
+
+type CTRFSummary struct {
+	Tests   int     `json:"tests"`
+	Passed  int     `json:"passed"`
+	Failed  int     `json:"failed"`
+	Pending int     `json:"pending"`
+	Skipped int     `json:"skipped"`
+	Other   int     `json:"other"`
+	Start   float64 `json:"start"`
+	Stop    float64 `json:"stop"`
+}
+
+type CTRFRetryAttempt struct {
+	Attempt  int            `json:"attempt"`
+	Status   string         `json:"status"`
+	Duration *float64       `json:"duration,omitempty"`
+	Message  *string        `json:"message,omitempty"`
+	Trace    *string        `json:"trace,omitempty"`
+	Line     *int           `json:"line,omitempty"`
+	Stdout   []string       `json:"stdout,omitempty"`
+	Stderr   []string       `json:"stderr,omitempty"`
+	Start    *float64       `json:"start,omitempty"`
+	Stop     *float64       `json:"stop,omitempty"`
+	Extra    map[string]any `json:"extra,omitempty"`
+}
+
+// CTRFSuite is a single string (`file > describe`) in older versions of the specification and an array in newer ones
+type CTRFSuite []string
+
+type CTRFTest struct {
+	Name          string             `json:"name"`
+	Status        string             `json:"status"`
+	Duration      float64            `json:"duration"`
+	Start         *float64           `json:"start,omitempty"`
+	Stop          *float64           `json:"stop,omitempty"`
+	Suite         CTRFSuite          `json:"suite,omitempty"`
+	Message       *string            `json:"message,omitempty"`
+	Trace         *string            `json:"trace,omitempty"`
+	Line          *int               `json:"line,omitempty"`
+	RawStatus     *string            `json:"rawStatus,omitempty"`
+	FilePath      *string            `json:"filePath,omitempty"`
+	Retries       *int               `json:"retries,omitempty"`
+	Flaky         *bool              `json:"flaky,omitempty"`
+	RetryAttempts []CTRFRetryAttempt `json:"retryAttempts,omitempty"`
+	Stdout        []string           `json:"stdout,omitempty"`
+	Stderr        []string           `json:"stderr,omitempty"`
+	Extra         map[string]any     `json:"extra,omitempty"`
+}
+
+type CTRFResults struct {
+	Tool    *CTRFTool      `json:"tool"`
+	Summary *CTRFSummary   `json:"summary"`
+	Tests   []CTRFTest     `json:"tests"`
+	Extra   map[string]any `json:"extra,omitempty"`
+}
+
+type CTRFReport struct {
+	ReportFormat *string      `json:"reportFormat,omitempty"`
+	SpecVersion  *string      `json:"specVersion,omitempty"`
+	Results      *CTRFResults `json:"results"`
+}
+
+// The fields of `v1.TestResults` which CTRF has no place for are kept in `extra`
+type CTRFTestExtra struct {
+	Column    *int           `json:"column,omitempty"`
+	Exception *string        `json:"exception,omitempty"`
+	ID        *string        `json:"id,omitempty"`
+	Lineage   []string       `json:"lineage,omitempty"`
+	Meta      map[string]any `json:"meta,omitempty"`
+	RawStatus *string        `json:"rawStatus,omitempty"`
+	// The status a quarantined test had before it was quarantined
+	OriginalStatus *string `json:"originalStatus,omitempty"`
+}
+
+type CTRFResultsExtra struct {
+	OtherErrors []v1.OtherError `json:"otherErrors,omitempty"`
+}
+
+const CTRFSuiteSeparator = " > "
+
+func (s *CTRFSuite) UnmarshalJSON(data []byte) error {
+	var suite string
+	if err := json.Unmarshal(data, &suite); err == nil {
+		if suite == "" {
+			*s = nil
+		} else {
+			*s = strings.Split(suite, CTRFSuiteSeparator)
+		}
+		return nil
+	}
+
+	var suites []string
+	if err := json.Unmarshal(data, &suites); err != nil {
+		return errors.NewInputError("Unable to parse the test suite %s: %s", data, err)
+	}
+
+	*s = suites
+	return nil
+}
+
+func (s CTRFSuite) MarshalJSON() ([]byte, error) {
+	encodedSuite, err := json.Marshal(strings.Join(s, CTRFSuiteSeparator))
+	return encodedSuite, errors.WithStack(err)
+}
+
+func (p CTRFParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
+func (p CTRFParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	var report CTRFReport
+
+	if err := json.NewDecoder(data).Decode(&report); err != nil {
+		return nil, errors.NewInputError("Unable to parse test results as JSON: %s", err)
+	}
+
+	if report.ReportFormat != nil && *report.ReportFormat != "CTRF" {
+		return nil, errors.NewInputError("The report format %q is not CTRF", *report.ReportFormat)
+	}
+
+	if report.Results == nil || report.Results.Tool == nil || report.Results.Summary == nil ||
+		report.Results.Tests == nil {
+		return nil, errors.NewInputError("The JSON does not look like a CTRF report")
+	}
+
+	tests := make([]v1.Test, 0, len(report.Results.Tests))
+	for _, ctrfTest := range report.Results.Tests {
+		test, err := p.newTest(ctrfTest)
+		if err != nil {
+			return nil, err
+		}
+
+		tests = append(tests, test)
+	}
+
+	var resultsExtra CTRFResultsExtra
+	p.decodeExtra(report.Results.Extra, &resultsExtra)
+
+	return v1.NewTestResults(
+		p.framework(*report.Results.Tool),
+		tests,
+		resultsExtra.OtherErrors,
+	), nil
+}
+
+// Reports written by Captain include the language, which lets us recover the exact framework. Otherwise, the name of
+// the tool is only used when it unambiguously identifies a framework
+func (p CTRFParser) framework(tool CTRFTool) v1.Framework {
+	if language, ok := tool.Extra["language"].(string); ok {
+		if strings.EqualFold(language, string(v1.FrameworkLanguageOther)) &&
+			strings.EqualFold(tool.Name, string(v1.FrameworkKindOther)) {
+			return v1.NewOtherFramework(nil, nil)
+		}
+
+		return v1.CoerceFramework(language, tool.Name)
+	}
+
+	var framework *v1.Framework
+	for _, knownFramework := range v1.KnownFrameworks {
+		if !strings.EqualFold(string(knownFramework.Kind), strings.TrimSpace(tool.Name)) {
+			continue
+		}
+
+		if framework != nil {
+			return v1.NewOtherFramework(nil, nil)
+		}
+
+		knownFramework := knownFramework
+		framework = &knownFramework
+	}
+
+	if framework == nil {
+		return v1.NewOtherFramework(nil, nil)
+	}
+
+	return *framework
+}
+
+func (p CTRFParser) newTest(ctrfTest CTRFTest) (v1.Test, error) {
+	var extra CTRFTestExtra
+	p.decodeExtra(ctrfTest.Extra, &extra)
+
+	status, err := p.newStatus(ctrfTest.Status, ctrfTest.RawStatus, ctrfTest.Message, ctrfTest.Trace, extra)
+	if err != nil {
+		return v1.Test{}, err
+	}
+
+	lineage := extra.Lineage
+	if lineage == nil && len(ctrfTest.Suite) > 0 {
+		lineage = append(append([]string{}, ctrfTest.Suite...), ctrfTest.Name)
+	}
+
+	var location *v1.Location
+	if ctrfTest.FilePath != nil && *ctrfTest.FilePath != "" {
+		location = &v1.Location{File: *ctrfTest.FilePath, Line: ctrfTest.Line, Column: extra.Column}
+	}
+
+	pastAttempts, err := p.newPastAttempts(ctrfTest)
+	if err != nil {
+		return v1.Test{}, err
+	}
+
+	duration := p.duration(ctrfTest.Duration)
+	return v1.Test{
+		ID:       extra.ID,
+		Name:     ctrfTest.Name,
+		Lineage:  lineage,
+		Location: location,
+		Attempt: v1.TestAttempt{
+			Duration:   &duration,
+			Meta:       extra.Meta,
+			Status:     status,
+			Stderr:     p.output(ctrfTest.Stderr),
+			Stdout:     p.output(ctrfTest.Stdout),
+			StartedAt:  p.time(ctrfTest.Start),
+			FinishedAt: p.time(ctrfTest.Stop),
+		},
+		PastAttempts: pastAttempts,
+	}, nil
+}
+
+// CTRF either lists the retry attempts or only counts them. In the latter case, all we know is that each retry was
+// preceded by a failure
+func (p CTRFParser) newPastAttempts(ctrfTest CTRFTest) ([]v1.TestAttempt, error) {
+	if len(ctrfTest.RetryAttempts) > 0 {
+		pastAttempts := make([]v1.TestAttempt, 0, len(ctrfTest.RetryAttempts))
+		for _, retryAttempt := range ctrfTest.RetryAttempts {
+			var extra CTRFTestExtra
+			p.decodeExtra(retryAttempt.Extra, &extra)
+
+			status, err := p.newStatus(retryAttempt.Status, extra.RawStatus, retryAttempt.Message, retryAttempt.Trace, extra)
+			if err != nil {
+				return nil, err
+			}
+
+			var duration *time.Duration
+			if retryAttempt.Duration != nil {
+				attemptDuration := p.duration(*retryAttempt.Duration)
+				duration = &attemptDuration
+			}
+
+			pastAttempts = append(pastAttempts, v1.TestAttempt{
+				Duration:   duration,
+				Meta:       extra.Meta,
+				Status:     status,
+				Stderr:     p.output(retryAttempt.Stderr),
+				Stdout:     p.output(retryAttempt.Stdout),
+				StartedAt:  p.time(retryAttempt.Start),
+				FinishedAt: p.time(retryAttempt.Stop),
+			})
+		}
+
+		return pastAttempts, nil
+	}
+
+	if ctrfTest.Retries == nil || *ctrfTest.Retries <= 0 {
+		return nil, nil
+	}
+
+	pastAttempts := make([]v1.TestAttempt, *ctrfTest.Retries)
+	for i := range pastAttempts {
+		pastAttempts[i] = v1.TestAttempt{Status: v1.NewFailedTestStatus(nil, nil, nil)}
+	}
+
+	return pastAttempts, nil
+}
+
+func (p CTRFParser) newStatus(
+	ctrfStatus string,
+	rawStatus *string,
+	message *string,
+	trace *string,
+	extra CTRFTestExtra,
+) (v1.TestStatus, error) {
+	exception := extra.Exception
+
+	var backtrace []string
+	if trace != nil && *trace != "" {
+		backtrace = strings.Split(*trace, "\n")
+	}
+
+	// Reports written by Captain keep the original status as the raw status
+	if rawStatus != nil {
+		switch v1.TestStatusKind(*rawStatus) {
+		case v1.TestStatusSuccessful:
+			return v1.NewSuccessfulTestStatus(), nil
+		case v1.TestStatusFailed:
+			return v1.NewFailedTestStatus(message, exception, backtrace), nil
+		case v1.TestStatusCanceled:
+			return v1.TestStatus{Kind: v1.TestStatusCanceled, Message: message, Backtrace: backtrace}, nil
+		case v1.TestStatusTimedOut:
+			return v1.TestStatus{Kind: v1.TestStatusTimedOut, Message: message, Backtrace: backtrace}, nil
+		case v1.TestStatusPended:
+			return v1.NewPendedTestStatus(message), nil
+		case v1.TestStatusSkipped:
+			return v1.NewSkippedTestStatus(message), nil
+		case v1.TestStatusTodo:
+			return v1.NewTodoTestStatus(message), nil
+		case v1.TestStatusQuarantined:
+			originalExtra := extra
+			originalExtra.OriginalStatus = nil
+			originalStatus, err := p.newStatus(ctrfStatus, extra.OriginalStatus, message, trace, originalExtra)
+			if err != nil {
+				return v1.TestStatus{}, err
+			}
+			return v1.NewQuarantinedTestStatus(originalStatus), nil
+		}
+	}
+
+	switch ctrfStatus {
+	case "passed":
+		return v1.NewSuccessfulTestStatus(), nil
+	case "failed":
+		return v1.NewFailedTestStatus(message, exception, backtrace), nil
+	case "pending":
+		return v1.NewPendedTestStatus(message), nil
+	case "skipped":
+		return v1.NewSkippedTestStatus(message), nil
+	case "other":
+		// Reporters use `other` for tests that were interrupted
+		return v1.TestStatus{Kind: v1.TestStatusCanceled, Message: message, Backtrace: backtrace}, nil
+	default:
+		return v1.TestStatus{}, errors.NewInputError("Unexpected test status %q", ctrfStatus)
+	}
+}
+
+// Other tools use `extra` for their own purposes too, so we ignore anything we cannot read
+func (p CTRFParser) decodeExtra(extra map[string]any, destination any) {
+	if len(extra) == 0 {
+		return
+	}
+
+	// Fields are decoded one at a time so a single unreadable value doesn't leave a half-decoded one behind
+	for key, value := range extra {
+		buf, err := json.Marshal(map[string]any{key: value})
+		if err != nil {
+			continue
+		}
+
+		if err := json.Unmarshal(buf, reflect.New(reflect.TypeOf(destination).Elem()).Interface()); err != nil {
+			continue
+		}
+
+		_ = json.Unmarshal(buf, destination)
+	}
+}
+
+// Durations are in milliseconds
+func (p CTRFParser) duration(milliseconds float64) time.Duration {
+	return time.Duration(math.Round(milliseconds * float64(time.Millisecond)))
+}
+
+// Timestamps are milliseconds since the epoch
+func (p CTRFParser) time(milliseconds *float64) *time.Time {
+	if milliseconds == nil || *milliseconds <= 0 {
+		return nil
+	}
+
+	wholeMilliseconds, fractionalMilliseconds := math.Modf(*milliseconds)
+	parsedTime := time.UnixMilli(int64(wholeMilliseconds)).Add(p.duration(fractionalMilliseconds)).UTC()
+	return &parsedTime
+}
+
+func (p CTRFParser) output(lines []string) *string {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	output := strings.Join(lines, "\n")
+	return &output
+}
@@ -0,0 +1,124 @@
+package parsing_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CTRFParser", func() {
+	Describe("Parse", func() {
+		It("parses the sample file", func() {
+			fixture, err := os.Open("../../test/fixtures/ctrf.json")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.CTRFParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			rwxJSON, err := json.MarshalIndent(testResults, "", "  ")
+			Expect(err).ToNot(HaveOccurred())
+			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
+		})
+
+		It("maps the statuses, locations and retries", func() {
+			fixture, err := os.Open("../../test/fixtures/ctrf.json")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.CTRFParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Framework).To(Equal(v1.JavaScriptPlaywrightFramework))
+			Expect(testResults.Summary.Tests).To(Equal(6))
+			Expect(testResults.Summary.Successful).To(Equal(2))
+			Expect(testResults.Summary.Failed).To(Equal(1))
+			Expect(testResults.Summary.Pended).To(Equal(1))
+			Expect(testResults.Summary.Skipped).To(Equal(1))
+			Expect(testResults.Summary.Canceled).To(Equal(1))
+			Expect(testResults.Summary.Retries).To(Equal(2))
+
+			flakyTest := testResults.Tests[1]
+			Expect(flakyTest.Lineage).To(Equal([]string{"auth/login.spec.ts", "login", "rejects an expired session"}))
+			Expect(flakyTest.Location.String()).To(Equal("tests/auth/login.spec.ts:31"))
+			Expect(*flakyTest.Attempt.Duration).To(Equal(2210 * time.Millisecond))
+			Expect(*flakyTest.Attempt.Stdout).To(Equal("redirecting to /login\nsession expired"))
+			Expect(flakyTest.PastAttempts).To(HaveLen(2))
+			Expect(flakyTest.PastAttempts[0].Status.Kind).To(Equal(v1.TestStatusFailed))
+			Expect(flakyTest.PastAttempts[0].Status.Backtrace).To(Equal([]string{"at tests/auth/login.spec.ts:38:41"}))
+			Expect(flakyTest.Flaky()).To(BeTrue())
+
+			failedTest := testResults.Tests[2]
+			Expect(failedTest.Lineage).To(Equal(
+				[]string{"checkout/cart.spec.ts", "cart", "discounts", "applies a discount code"},
+			))
+			Expect(*failedTest.Attempt.Stderr).To(Equal("[webserver] GET /api/discounts/SPRING 500"))
+			Expect(failedTest.PastAttempts).To(HaveLen(1))
+			Expect(failedTest.PastAttempts[0].Status.Kind).To(Equal(v1.TestStatusFailed))
+		})
+
+		It("only derives the framework from unambiguous tool names", func() {
+			testResults, err := parsing.CTRFParser{}.Parse(strings.NewReader(
+				`{"results":{"tool":{"name":"cucumber"},"summary":{},"tests":[]}}`,
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Framework.IsOther()).To(BeTrue())
+
+			testResults, err = parsing.CTRFParser{}.Parse(strings.NewReader(
+				`{"results":{"tool":{"name":"cucumber","extra":{"language":"Ruby"}},"summary":{},"tests":[]}}`,
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Framework).To(Equal(v1.RubyCucumberFramework))
+		})
+
+		It("ignores extra fields it cannot read", func() {
+			testResults, err := parsing.CTRFParser{}.Parse(strings.NewReader(
+				`{"results":{"tool":{"name":"x"},"summary":{},"tests":[` +
+					`{"name":"a","status":"passed","duration":1,"extra":{"id":5,"owner":"team-a"}}]}}`,
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Tests[0].ID).To(BeNil())
+		})
+
+		It("errors on unknown statuses", func() {
+			testResults, err := parsing.CTRFParser{}.Parse(strings.NewReader(
+				`{"results":{"tool":{"name":"x"},"summary":{},"tests":[{"name":"a","status":"wat","duration":1}]}}`,
+			))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`Unexpected test status "wat"`))
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors on other report formats", func() {
+			testResults, err := parsing.CTRFParser{}.Parse(strings.NewReader(
+				`{"reportFormat":"other","results":{"tool":{"name":"x"},"summary":{},"tests":[]}}`,
+			))
+			Expect(err).To(HaveOccurred())
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors on files that don't look like CTRF", func() {
+			for _, path := range []string{
+				"../../test/fixtures/jest.json",
+				"../../test/fixtures/mocha.json",
+				"../../test/fixtures/playwright.json",
+				"../../test/fixtures/rspec.json",
+				"../../test/fixtures/rwx/v1.json",
+				"../../test/fixtures/junit.xml",
+			} {
+				fixture, err := os.Open(path)
+				Expect(err).ToNot(HaveOccurred())
+
+				testResults, err := parsing.CTRFParser{}.Parse(fixture)
+				Expect(err).To(HaveOccurred(), path)
+				Expect(testResults).To(BeNil())
+			}
+		})
+	})
+})
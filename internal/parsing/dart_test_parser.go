@@ -0,0 +1,316 @@
+package parsing
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// Parses the event stream written by `dart test --reporter json` and `flutter test --machine`
+// https://github.com/dart-lang/test/blob/master/pkgs/test/doc/json_reporter.md
+type DartTestParser struct{}
+
+type DartTestMetadata struct {
+	Skip       bool    `json:"skip"`
+	SkipReason *string `json:"skipReason"`
+}
+
+type DartTestSuite struct {
+	ID       int     `json:"id"`
+	Platform string  `json:"platform"`
+	Path     *string `json:"path"`
+}
+
+type DartTestGroup struct {
+	ID       int              `json:"id"`
+	Name     string           `json:"name"`
+	SuiteID  int              `json:"suiteID"`
+	ParentID *int             `json:"parentID"`
+	Metadata DartTestMetadata `json:"metadata"`
+}
+
+type DartTestTest struct {
+	ID         int              `json:"id"`
+	Name       string           `json:"name"`
+	SuiteID    int              `json:"suiteID"`
+	GroupIDs   []int            `json:"groupIDs"`
+	Metadata   DartTestMetadata `json:"metadata"`
+	Line       *int             `json:"line"`
+	Column     *int             `json:"column"`
+	URL        *string          `json:"url"`
+	RootLine   *int             `json:"root_line"`
+	RootColumn *int             `json:"root_column"`
+	RootURL    *string          `json:"root_url"`
+}
+
+type DartTestEvent struct {
+	Type            *string        `json:"type"`
+	Time            int            `json:"time"`
+	ProtocolVersion *string        `json:"protocolVersion"`
+	Suite           *DartTestSuite `json:"suite"`
+	Group           *DartTestGroup `json:"group"`
+	Test            *DartTestTest  `json:"test"`
+	TestID          *int           `json:"testID"`
+	Message         *string        `json:"message"`
+	Error           *string        `json:"error"`
+	StackTrace      *string        `json:"stackTrace"`
+	IsFailure       bool           `json:"isFailure"`
+	Result          *string        `json:"result"` // success, failure, error
+	Skipped         bool           `json:"skipped"`
+	Hidden          bool           `json:"hidden"`
+}
+
+type dartTestState struct {
+	test      DartTestTest
+	startTime int
+	done      *DartTestEvent
+	errors    []DartTestEvent
+	prints    []string
+}
+
+// Sniff looks for the `start` event, which is always the first one
+func (p DartTestParser) Sniff(header []byte) bool {
+	return sniffJSONLines(header, func(line []byte) (bool, error) {
+		var event DartTestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return false, errors.WithStack(err)
+		}
+
+		return event.Type != nil && *event.Type == "start" && event.ProtocolVersion != nil, nil
+	})
+}
+
+func (p DartTestParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	suites := map[int]DartTestSuite{}
+	groups := map[int]DartTestGroup{}
+	testIDs := make([]int, 0)
+	testsByID := map[int]*dartTestState{}
+	sawStart := false
+
+	scanner := bufio.NewScanner(data)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(text, "{") {
+			continue
+		}
+
+		var event DartTestEvent
+		if err := json.Unmarshal([]byte(text), &event); err != nil {
+			continue
+		}
+
+		if event.Type == nil {
+			return nil, errors.NewInputError("Test results do not look like the Dart JSON reporter")
+		}
+
+		// The first event is always `start`, which tells us the version of the protocol
+		if !sawStart {
+			if *event.Type != "start" || event.ProtocolVersion == nil {
+				return nil, errors.NewInputError("Test results do not look like the Dart JSON reporter")
+			}
+			sawStart = true
+			continue
+		}
+
+		switch *event.Type {
+		case "suite":
+			if event.Suite != nil {
+				suites[event.Suite.ID] = *event.Suite
+			}
+		case "group":
+			if event.Group != nil {
+				groups[event.Group.ID] = *event.Group
+			}
+		case "testStart":
+			if event.Test == nil {
+				return nil, errors.NewInputError("testStart event is missing its test: %v", text)
+			}
+			testIDs = append(testIDs, event.Test.ID)
+			testsByID[event.Test.ID] = &dartTestState{test: *event.Test, startTime: event.Time}
+		case "print", "error", "testDone":
+			if event.TestID == nil {
+				return nil, errors.NewInputError("%v event is missing its testID: %v", *event.Type, text)
+			}
+
+			state, ok := testsByID[*event.TestID]
+			if !ok {
+				return nil, errors.NewInputError("%v event refers to unknown test %v", *event.Type, *event.TestID)
+			}
+
+			switch *event.Type {
+			case "print":
+				if event.Message != nil {
+					state.prints = append(state.prints, *event.Message)
+				}
+			case "error":
+				state.errors = append(state.errors, event)
+			case "testDone":
+				doneEvent := event
+				state.done = &doneEvent
+			}
+		default:
+			// allSuites, debug, done and events of newer protocol versions don't concern individual tests
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewInputError("Unable to read Dart JSON: %s", err)
+	}
+
+	if !sawStart {
+		return nil, errors.NewInputError("Did not see any events, so we cannot be sure it is Dart JSON")
+	}
+
+	tests := make([]v1.Test, 0)
+	otherErrors := make([]v1.OtherError, 0)
+	for _, id := range testIDs {
+		state := testsByID[id]
+
+		// Hidden tests are synthesized by the runner for loading suites and for setUpAll/tearDownAll. They are
+		// only interesting if they failed, e.g. because a suite did not compile
+		if state.done != nil && state.done.Hidden {
+			for _, errorEvent := range state.errors {
+				otherErrors = append(otherErrors, v1.OtherError{
+					Backtrace: p.backtrace(errorEvent.StackTrace),
+					Message:   p.value(errorEvent.Error),
+					Meta:      map[string]any{"test": state.test.Name},
+				})
+			}
+			continue
+		}
+
+		tests = append(tests, p.newTest(*state, suites, groups))
+	}
+
+	return v1.NewTestResults(
+		v1.DartTestFramework,
+		tests,
+		otherErrors,
+	), nil
+}
+
+func (p DartTestParser) newTest(
+	state dartTestState,
+	suites map[int]DartTestSuite,
+	groups map[int]DartTestGroup,
+) v1.Test {
+	var duration *time.Duration
+	var status v1.TestStatus
+	switch {
+	case state.done == nil:
+		// The run was interrupted before the test finished
+		status = v1.NewCanceledTestStatus()
+	case state.done.Skipped:
+		status = v1.NewSkippedTestStatus(state.test.Metadata.SkipReason)
+	case state.done.Result != nil && *state.done.Result == "success" && len(state.errors) == 0:
+		status = v1.NewSuccessfulTestStatus()
+	default:
+		status = p.newFailedTestStatus(state.errors)
+	}
+	if state.done != nil {
+		testDuration := time.Duration(state.done.Time-state.startTime) * time.Millisecond
+		duration = &testDuration
+	}
+
+	var stdout *string
+	if len(state.prints) > 0 {
+		joinedPrints := strings.Join(state.prints, "\n")
+		stdout = &joinedPrints
+	}
+
+	// Group and test names include the names of their parent groups, so we strip those to build the lineage
+	lineage := make([]string, 0, len(state.test.GroupIDs)+1)
+	parentName := ""
+	for _, groupID := range state.test.GroupIDs {
+		group, ok := groups[groupID]
+		if !ok || group.Name == "" {
+			continue
+		}
+
+		lineage = append(lineage, strings.TrimPrefix(strings.TrimPrefix(group.Name, parentName), " "))
+		parentName = group.Name
+	}
+	lineage = append(lineage, strings.TrimPrefix(strings.TrimPrefix(state.test.Name, parentName), " "))
+
+	var path *string
+	if suite, ok := suites[state.test.SuiteID]; ok {
+		path = suite.Path
+	}
+
+	var location *v1.Location
+	var meta map[string]any
+	if path != nil {
+		meta = map[string]any{"path": *path}
+
+		// The root location is where the test is declared in the suite when it's defined by a helper elsewhere
+		location = &v1.Location{File: *path, Line: state.test.Line, Column: state.test.Column}
+		if state.test.RootLine != nil {
+			location = &v1.Location{File: *path, Line: state.test.RootLine, Column: state.test.RootColumn}
+		}
+	}
+
+	return v1.Test{
+		Scope:    path,
+		Name:     state.test.Name,
+		Lineage:  lineage,
+		Location: location,
+		Attempt: v1.TestAttempt{
+			Duration: duration,
+			Meta:     meta,
+			Status:   status,
+			Stdout:   stdout,
+		},
+	}
+}
+
+func (p DartTestParser) newFailedTestStatus(errorEvents []DartTestEvent) v1.TestStatus {
+	if len(errorEvents) == 0 {
+		return v1.NewFailedTestStatus(nil, nil, nil)
+	}
+
+	messages := make([]string, 0, len(errorEvents))
+	for _, errorEvent := range errorEvents {
+		if message := strings.TrimSpace(p.value(errorEvent.Error)); message != "" {
+			messages = append(messages, message)
+		}
+	}
+
+	var message *string
+	if len(messages) > 0 {
+		joinedMessages := strings.Join(messages, "\n\n")
+		message = &joinedMessages
+	}
+
+	return v1.NewFailedTestStatus(message, nil, p.backtrace(errorEvents[0].StackTrace))
+}
+
+func (p DartTestParser) backtrace(stackTrace *string) []string {
+	if stackTrace == nil {
+		return nil
+	}
+
+	backtrace := make([]string, 0)
+	for _, line := range strings.Split(*stackTrace, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			backtrace = append(backtrace, line)
+		}
+	}
+	if len(backtrace) == 0 {
+		return nil
+	}
+
+	return backtrace
+}
+
+func (p DartTestParser) value(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
@@ -0,0 +1,103 @@
+package parsing_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DartTestParser", func() {
+	Describe("Parse", func() {
+		It("parses the sample file", func() {
+			fixture, err := os.Open("../../test/fixtures/dart_test.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.DartTestParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			rwxJSON, err := json.MarshalIndent(testResults, "", "  ")
+			Expect(err).ToNot(HaveOccurred())
+			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
+		})
+
+		It("reconstructs the groups as the lineage", func() {
+			fixture, err := os.Open("../../test/fixtures/dart_test.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.DartTestParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Framework).To(Equal(v1.DartTestFramework))
+			Expect(testResults.Summary.Tests).To(Equal(5))
+			Expect(testResults.Summary.Successful).To(Equal(2))
+			Expect(testResults.Summary.Failed).To(Equal(2))
+			Expect(testResults.Summary.Skipped).To(Equal(1))
+			Expect(testResults.Summary.OtherErrors).To(Equal(1))
+
+			nestedTest := testResults.Tests[3]
+			Expect(nestedTest.Name).To(Equal("Calculator when empty throws on pop"))
+			Expect(nestedTest.Lineage).To(Equal([]string{"Calculator", "when empty", "throws on pop"}))
+			Expect(*nestedTest.Attempt.Status.Message).To(Equal("Bad state: No element"))
+			Expect(nestedTest.Attempt.Status.Backtrace).To(HaveLen(3))
+
+			Expect(*testResults.Tests[0].Attempt.Stdout).To(Equal("adding 1 and 2"))
+			Expect(*testResults.Tests[2].Attempt.Status.Message).To(Equal("not supported yet"))
+			Expect(*testResults.Tests[4].Location.Line).To(Equal(26))
+		})
+
+		It("marks tests which never finished as canceled", func() {
+			testResults, err := parsing.DartTestParser{}.Parse(strings.NewReader(strings.Join([]string{
+				`{"protocolVersion":"0.1.1","type":"start","time":0}`,
+				`{"test":{"id":1,"name":"hangs","suiteID":0,"groupIDs":[]},"type":"testStart","time":1}`,
+			}, "\n")))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(1))
+			Expect(testResults.Tests[0].Attempt.Status.Kind).To(Equal(v1.TestStatusCanceled))
+		})
+
+		It("errors on malformed JSON with no remnants of Dart JSON", func() {
+			testResults, err := parsing.DartTestParser{}.Parse(strings.NewReader(`asdfasdfsdf`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Did not see any events, so we cannot be sure it is Dart JSON"))
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors on JSON that doesn't look like Dart JSON", func() {
+			testResults, err := parsing.DartTestParser{}.Parse(strings.NewReader(`{"type":"suite","event":"started"}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Test results do not look like the Dart JSON reporter"))
+			Expect(testResults).To(BeNil())
+
+			for _, path := range []string{
+				"../../test/fixtures/go_test.jsonl",
+				"../../test/fixtures/nextest.jsonl",
+			} {
+				fixture, err := os.Open(path)
+				Expect(err).ToNot(HaveOccurred())
+
+				testResults, err := parsing.DartTestParser{}.Parse(fixture)
+				Expect(err).To(HaveOccurred(), path)
+				Expect(testResults).To(BeNil())
+			}
+		})
+	})
+
+	Describe("Sniff", func() {
+		It("looks for the start event at the beginning of the header", func() {
+			fixture, err := os.ReadFile("../../test/fixtures/dart_test.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsing.DartTestParser{}.Sniff(fixture)).To(BeTrue())
+
+			Expect(parsing.DartTestParser{}.Sniff([]byte(`{"type":"suite","suite":{"id":0}}`))).To(BeFalse())
+			Expect(parsing.DartTestParser{}.Sniff([]byte(`{"type":"test","event":"started"}`))).To(BeFalse())
+			Expect(parsing.DartTestParser{}.Sniff([]byte("1..2\nok 1 - works"))).To(BeFalse())
+		})
+	})
+})
@@ -135,6 +135,10 @@ var dotNetxUnitAssemblyNameRegexp = regexp.MustCompile(fmt.Sprintf(`[^%s]+$`, st
 
 var dotNetxUnitNewlineRegexp = regexp.MustCompile(`\r?\n`)
 
+func (p DotNetxUnitParser) Sniff(header []byte) bool {
+	return sniffXML(header, "assemblies")
+}
+
 func (p DotNetxUnitParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var testResults DotNetxUnitTestResults
 
@@ -62,6 +62,10 @@ type ElixirExUnitTestResults struct {
 
 var elixirExUnitNewlineRegexp = regexp.MustCompile(`\r?\n`)
 
+func (p ElixirExUnitParser) Sniff(header []byte) bool {
+	return sniffXML(header, "testsuites")
+}
+
 func (p ElixirExUnitParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var testResults ElixirExUnitTestResults
 
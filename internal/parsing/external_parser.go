@@ -0,0 +1,106 @@
+package parsing
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	"github.com/rwx-research/captain-cli/internal/exec"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// TaskRunner spawns the executables of external parsers
+type TaskRunner interface {
+	NewCommand(ctx context.Context, cfg exec.CommandConfig) (exec.Command, error)
+}
+
+// The time an external parser gets to run if none is configured
+const DefaultExternalParserTimeout = time.Minute
+
+// ExternalParser hands a test results file to an executable on stdin and reads RWX v1 JSON from its stdout. It is
+// used for in-house formats that Captain doesn't know about.
+type ExternalParser struct {
+	Name       string
+	Command    []string
+	TaskRunner TaskRunner
+
+	// ProbeCommand receives the start of the file on stdin and exits successfully if the file can be parsed. This way,
+	// the parser only reads the files it claims in full.
+	ProbeCommand []string
+
+	// Timeout limits every run of the command and the probe. The process group of an executable that runs out of time
+	// is killed.
+	Timeout time.Duration
+}
+
+func (p ExternalParser) Sniff(header []byte) bool {
+	if len(p.ProbeCommand) == 0 {
+		return true
+	}
+
+	return p.run(p.ProbeCommand, bytes.NewReader(header), io.Discard, io.Discard) == nil
+}
+
+func (p ExternalParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	stdout := new(bytes.Buffer)
+	stderr := new(strings.Builder)
+
+	if err := p.run(p.Command, data, stdout, stderr); err != nil {
+		return nil, errors.NewInputError(
+			"The %q parser was unable to parse the test results: %s\n%s",
+			p.Name,
+			err,
+			strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	testResults, err := RWXParser{}.Parse(stdout)
+	if err != nil {
+		return nil, errors.NewInputError("The %q parser did not print RWX v1 JSON: %s", p.Name, err)
+	}
+
+	return testResults, nil
+}
+
+func (p ExternalParser) run(command []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	if len(command) == 0 {
+		return errors.NewInternalError("The %q parser does not have a command to run", p.Name)
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExternalParserTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd, err := p.TaskRunner.NewCommand(ctx, exec.CommandConfig{
+		Name:            command[0],
+		Args:            command[1:],
+		NewProcessGroup: true,
+		Stdin:           stdin,
+		Stdout:          stdout,
+		Stderr:          stderr,
+	})
+	if err != nil {
+		return errors.NewSystemError("unable to spawn sub-process: %s", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return errors.NewSystemError("unable to execute sub-command: %s", err)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return errors.NewSystemError("%q did not finish within %v", command[0], timeout)
+		}
+
+		return errors.NewSystemError("%s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package parsing_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	"github.com/rwx-research/captain-cli/internal/exec"
+	"github.com/rwx-research/captain-cli/internal/mocks"
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExternalParser", func() {
+	var (
+		taskRunner *mocks.TaskRunner
+		parser     parsing.ExternalParser
+		commands   []exec.CommandConfig
+		stdins     []string
+		stdout     string
+		stderr     string
+		waitErr    error
+	)
+
+	BeforeEach(func() {
+		commands = make([]exec.CommandConfig, 0)
+		stdins = make([]string, 0)
+		stdout = `{"$schema":"https://raw.githubusercontent.com/rwx-research/test-results-schema/main/v1.json",` +
+			`"framework":{"language":"other","kind":"other"},"summary":{"tests":1,"successful":1},` +
+			`"tests":[{"name":"simulates a thing","attempt":{"status":{"kind":"successful"}}}]}`
+		stderr = ""
+		waitErr = nil
+
+		taskRunner = new(mocks.TaskRunner)
+		taskRunner.MockNewCommand = func(_ context.Context, cfg exec.CommandConfig) (exec.Command, error) {
+			commands = append(commands, cfg)
+
+			return &mocks.Command{
+				MockStart: func() error {
+					stdin, err := io.ReadAll(cfg.Stdin)
+					Expect(err).ToNot(HaveOccurred())
+					stdins = append(stdins, string(stdin))
+
+					_, err = fmt.Fprint(cfg.Stdout, stdout)
+					Expect(err).ToNot(HaveOccurred())
+					_, err = fmt.Fprint(cfg.Stderr, stderr)
+					Expect(err).ToNot(HaveOccurred())
+					return nil
+				},
+				MockWait: func() error {
+					return waitErr
+				},
+			}, nil
+		}
+
+		parser = parsing.ExternalParser{
+			Name:       "simulation",
+			Command:    []string{"bin/simulation-to-rwx", "--strict"},
+			TaskRunner: taskRunner,
+		}
+	})
+
+	Describe("Parse", func() {
+		It("parses the RWX v1 JSON printed by the command", func() {
+			testResults, err := parser.Parse(strings.NewReader("PASS simulates a thing"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(commands).To(HaveLen(1))
+			Expect(commands[0].Name).To(Equal("bin/simulation-to-rwx"))
+			Expect(commands[0].Args).To(Equal([]string{"--strict"}))
+			Expect(stdins).To(Equal([]string{"PASS simulates a thing"}))
+
+			Expect(testResults.Framework.IsOther()).To(BeTrue())
+			Expect(testResults.Summary.Successful).To(Equal(1))
+			Expect(testResults.Tests).To(HaveLen(1))
+			Expect(testResults.Tests[0].Name).To(Equal("simulates a thing"))
+			Expect(testResults.Tests[0].Attempt.Status.Kind).To(Equal(v1.TestStatusSuccessful))
+		})
+
+		It("errors when the command fails", func() {
+			stderr = "unexpected token on line 1"
+			waitErr = errors.NewExecutionError(1, "exit status 1")
+
+			testResults, err := parser.Parse(strings.NewReader("garbage"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`The "simulation" parser was unable to parse the test results`))
+			Expect(err.Error()).To(ContainSubstring("unexpected token on line 1"))
+			Expect(testResults).To(BeNil())
+		})
+
+		It("runs the command in a process group of its own with a deadline", func() {
+			var deadline time.Time
+			var hasDeadline bool
+			mockNewCommand := taskRunner.MockNewCommand
+			taskRunner.MockNewCommand = func(ctx context.Context, cfg exec.CommandConfig) (exec.Command, error) {
+				deadline, hasDeadline = ctx.Deadline()
+				return mockNewCommand(ctx, cfg)
+			}
+
+			_, err := parser.Parse(strings.NewReader("PASS simulates a thing"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(commands[0].NewProcessGroup).To(BeTrue())
+			Expect(hasDeadline).To(BeTrue())
+			Expect(time.Until(deadline)).To(BeNumerically("~", parsing.DefaultExternalParserTimeout, time.Second))
+		})
+
+		It("errors when the command runs out of time", func() {
+			parser.Timeout = 10 * time.Millisecond
+			taskRunner.MockNewCommand = func(ctx context.Context, cfg exec.CommandConfig) (exec.Command, error) {
+				return &mocks.Command{
+					MockStart: func() error { return nil },
+					MockWait: func() error {
+						<-ctx.Done()
+						return errors.NewExecutionError(137, "signal: killed")
+					},
+				}, nil
+			}
+
+			testResults, err := parser.Parse(strings.NewReader("PASS simulates a thing"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`"bin/simulation-to-rwx" did not finish within 10ms`))
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors when the command doesn't print RWX v1 JSON", func() {
+			stdout = "everything passed"
+
+			testResults, err := parser.Parse(strings.NewReader("PASS simulates a thing"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`The "simulation" parser did not print RWX v1 JSON`))
+			Expect(testResults).To(BeNil())
+		})
+	})
+
+	Describe("Sniff", func() {
+		It("accepts every file without a probe", func() {
+			Expect(parser.Sniff([]byte("anything"))).To(BeTrue())
+			Expect(commands).To(BeEmpty())
+		})
+
+		It("passes the start of the file to the probe", func() {
+			parser.ProbeCommand = []string{"bin/simulation-to-rwx", "--probe"}
+
+			Expect(parser.Sniff([]byte("PASS simulates a thing"))).To(BeTrue())
+			Expect(commands).To(HaveLen(1))
+			Expect(commands[0].Args).To(Equal([]string{"--probe"}))
+			Expect(stdins).To(Equal([]string{"PASS simulates a thing"}))
+		})
+
+		It("rejects files when the probe fails", func() {
+			parser.ProbeCommand = []string{"bin/simulation-to-rwx", "--probe"}
+			waitErr = errors.NewExecutionError(1, "exit status 1")
+
+			Expect(parser.Sniff([]byte("<testsuites />"))).To(BeFalse())
+		})
+	})
+})
@@ -15,6 +15,10 @@ type GoGinkgoParser struct{}
 
 var goGinkgoNewlineRegexp = regexp.MustCompile(`\r?\n`)
 
+func (p GoGinkgoParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
 func (p GoGinkgoParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var ginkgoReport []ginkgo.Report
 
@@ -27,7 +27,11 @@ type GoTestTestOutput struct {
 
 func (p GoTestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	testsByPackage := map[string]map[string]v1.Test{}
+	// Output is collected separately since concatenating it line by line gets slow for tests with a lot of output
+	outputByPackage := map[string]map[string]*strings.Builder{}
 	scanner := bufio.NewScanner(data)
+	// Tests with a lot of output can produce very long lines
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 64*1024*1024)
 	for scanner.Scan() {
 		text := strings.TrimSpace(scanner.Text())
 		if !strings.HasPrefix(text, "{") {
@@ -50,6 +54,7 @@ func (p GoTestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 
 		if _, ok := testsByPackage[*testOutput.Package]; !ok {
 			testsByPackage[*testOutput.Package] = map[string]v1.Test{}
+			outputByPackage[*testOutput.Package] = map[string]*strings.Builder{}
 		}
 
 		testPackage := *testOutput.Package
@@ -79,12 +84,12 @@ func (p GoTestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 				return nil, errors.NewInputError("JSON with action of output is missing output: %v", testOutput)
 			}
 
-			if existingTest.Attempt.Stdout == nil {
-				existingTest.Attempt.Stdout = testOutput.Output
-			} else {
-				newStdout := strings.Join([]string{*existingTest.Attempt.Stdout, *testOutput.Output}, "")
-				existingTest.Attempt.Stdout = &newStdout
+			output, ok := outputByPackage[testPackage][*testOutput.Test]
+			if !ok {
+				output = new(strings.Builder)
+				outputByPackage[testPackage][*testOutput.Test] = output
 			}
+			output.WriteString(*testOutput.Output)
 		case "pass":
 			duration := time.Duration(math.Round(*testOutput.Elapsed * float64(time.Second)))
 			existingTest.Attempt.Duration = &duration
@@ -92,7 +97,8 @@ func (p GoTestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 		case "pause":
 			// no-op
 		case "run":
-			// no-op
+			// Tests that never finish were stopped along with go test, e.g. because the run timed out or was interrupted
+			existingTest.Attempt.Status = v1.NewCanceledTestStatus()
 		case "skip":
 			duration := time.Duration(math.Round(*testOutput.Elapsed * float64(time.Second)))
 			existingTest.Attempt.Duration = &duration
@@ -103,10 +109,18 @@ func (p GoTestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 
 		testsByPackage[*testOutput.Package][*testOutput.Test] = existingTest
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewInputError("Unable to read go test output: %s", err)
+	}
 
 	tests := make([]v1.Test, 0)
-	for _, testsByName := range testsByPackage {
-		for _, test := range testsByName {
+	for testPackage, testsByName := range testsByPackage {
+		for name, test := range testsByName {
+			if output, ok := outputByPackage[testPackage][name]; ok {
+				stdout := output.String()
+				test.Attempt.Stdout = &stdout
+			}
+
 			tests = append(tests, test)
 		}
 	}
@@ -42,6 +42,20 @@ var _ = Describe("GoTestParser", func() {
 			))
 		})
 
+		It("marks tests which never finished as canceled", func() {
+			testResults, err := parsing.GoTestParser{}.Parse(strings.NewReader(strings.Join([]string{
+				`{"Action":"run","Package":"example.com/pkg","Test":"TestPasses"}`,
+				`{"Action":"pass","Package":"example.com/pkg","Test":"TestPasses","Elapsed":0.01}`,
+				`{"Action":"run","Package":"example.com/pkg","Test":"TestHangs"}`,
+				`{"Action":"output","Package":"example.com/pkg","Test":"TestHangs","Output":"=== RUN   TestHangs\n"}`,
+			}, "\n")))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(2))
+			Expect(testResults.Tests[0].Name).To(Equal("TestHangs"))
+			Expect(testResults.Tests[0].Attempt.Status.Kind).To(Equal(v1.TestStatusCanceled))
+			Expect(testResults.Tests[1].Attempt.Status.Kind).To(Equal(v1.TestStatusSuccessful))
+		})
+
 		It("errors on malformed JSON with no remnants of Go Test JSON", func() {
 			testResults, err := parsing.GoTestParser{}.Parse(strings.NewReader(`asdfasdfsdf`))
 			Expect(err).To(HaveOccurred())
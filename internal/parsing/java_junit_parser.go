@@ -0,0 +1,274 @@
+package parsing
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// Parses the JUnit XML flavours written by the Maven Surefire & Failsafe plugins as well as by Gradle
+// https://maven.apache.org/surefire/maven-surefire-plugin/xsd/surefire-test-report-3.0.xsd
+type JavaJUnitParser struct{}
+
+type JavaJUnitFailure struct {
+	Type     *string `xml:"type,attr"`
+	Message  *string `xml:"message,attr"`
+	Contents *string `xml:",chardata"`
+}
+
+// Surefire reports every additional run of a test as one of these when `rerunFailingTestsCount` is set
+type JavaJUnitRerun struct {
+	Type       *string `xml:"type,attr"`
+	Message    *string `xml:"message,attr"`
+	StackTrace *string `xml:"stackTrace"`
+	SystemErr  *string `xml:"system-err"`
+	SystemOut  *string `xml:"system-out"`
+}
+
+type JavaJUnitSkipped struct {
+	Message *string `xml:"message,attr"`
+}
+
+type JavaJUnitTestCase struct {
+	ClassName     string            `xml:"classname,attr"`
+	Error         *JavaJUnitFailure `xml:"error"`
+	Failure       *JavaJUnitFailure `xml:"failure"`
+	File          *string           `xml:"file,attr"`
+	FlakyErrors   []JavaJUnitRerun  `xml:"flakyError"`
+	FlakyFailures []JavaJUnitRerun  `xml:"flakyFailure"`
+	Line          *int              `xml:"line,attr"`
+	Name          string            `xml:"name,attr"`
+	RerunErrors   []JavaJUnitRerun  `xml:"rerunError"`
+	RerunFailures []JavaJUnitRerun  `xml:"rerunFailure"`
+	Skipped       *JavaJUnitSkipped `xml:"skipped"`
+	SystemErr     *string           `xml:"system-err"`
+	SystemOut     *string           `xml:"system-out"`
+	Time          string            `xml:"time,attr"`
+
+	XMLName xml.Name `xml:"testcase"`
+}
+
+// The attributes of a `<testsuite>`, its test cases are converted one at a time while they are being read
+type JavaJUnitTestSuite struct {
+	Hostname       *string         `xml:"hostname,attr"`
+	Name           string          `xml:"name,attr"`
+	Properties     []JUnitProperty `xml:"-"`
+	SchemaLocation string          `xml:"noNamespaceSchemaLocation,attr"`
+	Tests          *int            `xml:"tests,attr"`
+
+	XMLName xml.Name `xml:"testsuite"`
+}
+
+var (
+	javaJUnitNewlineRegexp   = regexp.MustCompile(`\r?\n`)
+	javaJUnitClassNameRegexp = regexp.MustCompile(`^[\p{L}_$][\p{L}\p{N}_$]*(\.[\p{L}_$][\p{L}\p{N}_$]*)+$`)
+)
+
+func (p JavaJUnitParser) Sniff(header []byte) bool {
+	return sniffXML(header, "testsuite", "testsuites")
+}
+
+func (p JavaJUnitParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	decoder := xml.NewDecoder(data)
+
+	tests := make([]v1.Test, 0)
+	sawTestSuite := false
+
+	// Surefire writes one `<testsuite>` per file, Gradle does the same but other tools aggregate them in `<testsuites>`
+	err := streamJUnitTestSuites(decoder, func(element xml.StartElement) error {
+		var testSuite JavaJUnitTestSuite
+		if err := decodeXMLAttributes(element, &testSuite); err != nil {
+			return err
+		}
+
+		if !sawTestSuite && testSuite.Tests == nil {
+			return errors.NewInputError("The test suites in the XML do not appear to match JUnit XML")
+		}
+		sawTestSuite = true
+
+		testSuiteTests, classNamesMatch, err := p.decodeTestCases(decoder, &testSuite)
+		if err != nil {
+			return err
+		}
+
+		if !p.looksLikeJava(testSuite, classNamesMatch) {
+			return errors.NewInputError(
+				"The test suite %q does not appear to be written by Surefire or Gradle",
+				testSuite.Name,
+			)
+		}
+
+		tests = append(tests, testSuiteTests...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !sawTestSuite {
+		return nil, errors.NewInputError("The test suites in the XML do not appear to match JUnit XML")
+	}
+
+	return v1.NewTestResults(
+		v1.JavaJUnitFramework,
+		tests,
+		nil,
+	), nil
+}
+
+// decodeTestCases converts the `<testcase>` elements of a `<testsuite>` while they are being read. The properties of
+// the suite are added to it along the way. It also reports whether all test cases belong to the class the suite is
+// named after, which is how Gradle writes them.
+func (p JavaJUnitParser) decodeTestCases(
+	decoder *xml.Decoder,
+	testSuite *JavaJUnitTestSuite,
+) ([]v1.Test, bool, error) {
+	tests := make([]v1.Test, 0)
+	classNamesMatch := true
+
+	err := decodeXMLChildren(decoder, func(element xml.StartElement) error {
+		switch element.Name.Local {
+		case "testcase":
+			var testCase JavaJUnitTestCase
+			if err := decoder.DecodeElement(&testCase, &element); err != nil {
+				return errors.NewInputError("Unable to parse test results as XML: %s", err)
+			}
+
+			test, err := p.newTest(testCase)
+			if err != nil {
+				return err
+			}
+
+			classNamesMatch = classNamesMatch && testCase.ClassName == testSuite.Name
+			tests = append(tests, test)
+		case "properties":
+			var properties JUnitProperties
+			if err := decoder.DecodeElement(&properties, &element); err != nil {
+				return errors.NewInputError("Unable to parse test results as XML: %s", err)
+			}
+
+			testSuite.Properties = append(testSuite.Properties, properties.Properties...)
+		default:
+			return skipXMLElement(decoder)
+		}
+
+		return nil
+	})
+
+	return tests, classNamesMatch, err
+}
+
+func (p JavaJUnitParser) looksLikeJava(testSuite JavaJUnitTestSuite, classNamesMatch bool) bool {
+	if strings.Contains(testSuite.SchemaLocation, "surefire") {
+		return true
+	}
+
+	// Surefire & Ant write all JVM system properties
+	for _, property := range testSuite.Properties {
+		if strings.HasPrefix(property.Name, "java.") {
+			return true
+		}
+	}
+
+	// Gradle writes one suite per test class & does not write any properties
+	return testSuite.Hostname != nil && javaJUnitClassNameRegexp.MatchString(testSuite.Name) && classNamesMatch
+}
+
+func (p JavaJUnitParser) newTest(testCase JavaJUnitTestCase) (v1.Test, error) {
+	var duration *time.Duration
+	if testCase.Time != "" {
+		// Older versions of Surefire format durations with thousands separators
+		seconds, err := strconv.ParseFloat(strings.ReplaceAll(testCase.Time, ",", ""), 64)
+		if err != nil {
+			return v1.Test{}, errors.NewInputError("Unable to parse test duration %q: %s", testCase.Time, err)
+		}
+		parsedDuration := time.Duration(math.Round(seconds * float64(time.Second)))
+		duration = &parsedDuration
+	}
+
+	var status v1.TestStatus
+	switch {
+	case testCase.Failure != nil:
+		status = p.newFailedTestStatus(testCase.Failure.Message, testCase.Failure.Type, testCase.Failure.Contents)
+	case testCase.Error != nil:
+		status = p.newFailedTestStatus(testCase.Error.Message, testCase.Error.Type, testCase.Error.Contents)
+	case testCase.Skipped != nil:
+		status = v1.NewSkippedTestStatus(testCase.Skipped.Message)
+	default:
+		status = v1.NewSuccessfulTestStatus()
+	}
+
+	var location *v1.Location
+	if testCase.File != nil {
+		location = &v1.Location{File: *testCase.File, Line: testCase.Line}
+	}
+
+	// Nested classes are separated by a `$`, e.g. com.example.CalculatorTest$WhenEmpty
+	lineage := strings.Split(testCase.ClassName, "$")
+	lineage = append(lineage, testCase.Name)
+
+	pastAttempts := make([]v1.TestAttempt, 0)
+	for _, reruns := range [][]JavaJUnitRerun{
+		testCase.FlakyFailures,
+		testCase.FlakyErrors,
+		testCase.RerunFailures,
+		testCase.RerunErrors,
+	} {
+		for _, rerun := range reruns {
+			pastAttempts = append(pastAttempts, v1.TestAttempt{
+				Meta:   map[string]any{"classname": testCase.ClassName},
+				Status: p.newFailedTestStatus(rerun.Message, rerun.Type, rerun.StackTrace),
+				Stderr: rerun.SystemErr,
+				Stdout: rerun.SystemOut,
+			})
+		}
+	}
+	if len(pastAttempts) == 0 {
+		pastAttempts = nil
+	}
+
+	className := testCase.ClassName
+	return v1.Test{
+		Scope:    &className,
+		Name:     fmt.Sprintf("%s %s", testCase.ClassName, testCase.Name),
+		Lineage:  lineage,
+		Location: location,
+		Attempt: v1.TestAttempt{
+			Duration: duration,
+			Meta:     map[string]any{"classname": testCase.ClassName},
+			Status:   status,
+			Stderr:   testCase.SystemErr,
+			Stdout:   testCase.SystemOut,
+		},
+		PastAttempts: pastAttempts,
+	}, nil
+}
+
+func (p JavaJUnitParser) newFailedTestStatus(message *string, exception *string, stackTrace *string) v1.TestStatus {
+	if stackTrace == nil {
+		return v1.NewFailedTestStatus(message, exception, nil)
+	}
+
+	backtrace := make([]string, 0)
+	for _, line := range javaJUnitNewlineRegexp.Split(*stackTrace, -1) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		backtrace = append(backtrace, line)
+	}
+	if len(backtrace) == 0 {
+		return v1.NewFailedTestStatus(message, exception, nil)
+	}
+
+	return v1.NewFailedTestStatus(message, exception, backtrace)
+}
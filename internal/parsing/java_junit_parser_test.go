@@ -0,0 +1,128 @@
+package parsing_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JavaJUnitParser", func() {
+	Describe("Parse", func() {
+		It("parses the sample Surefire file", func() {
+			fixture, err := os.Open("../../test/fixtures/surefire.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.JavaJUnitParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			rwxJSON, err := json.MarshalIndent(testResults, "", "  ")
+			Expect(err).ToNot(HaveOccurred())
+			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
+		})
+
+		It("parses the sample Gradle file", func() {
+			fixture, err := os.Open("../../test/fixtures/gradle.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.JavaJUnitParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			rwxJSON, err := json.MarshalIndent(testResults, "", "  ")
+			Expect(err).ToNot(HaveOccurred())
+			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
+		})
+
+		It("maps flaky and rerun failures to past attempts", func() {
+			fixture, err := os.Open("../../test/fixtures/surefire.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.JavaJUnitParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Summary.Tests).To(Equal(8))
+			Expect(testResults.Summary.Successful).To(Equal(4))
+			Expect(testResults.Summary.Failed).To(Equal(3))
+			Expect(testResults.Summary.Skipped).To(Equal(1))
+			Expect(testResults.Summary.Retries).To(Equal(2))
+
+			flakyTest := testResults.Tests[4]
+			Expect(flakyTest.Name).To(Equal("com.example.CalculatorTest roundsNumbers"))
+			Expect(flakyTest.Attempt.Status.Kind).To(Equal(v1.TestStatusSuccessful))
+			Expect(flakyTest.PastAttempts).To(HaveLen(1))
+			Expect(flakyTest.PastAttempts[0].Status.Kind).To(Equal(v1.TestStatusFailed))
+			Expect(*flakyTest.PastAttempts[0].Stdout).To(Equal("rounding 2.5\n"))
+			Expect(flakyTest.Flaky()).To(BeTrue())
+
+			rerunTest := testResults.Tests[5]
+			Expect(rerunTest.Attempt.Status.Kind).To(Equal(v1.TestStatusFailed))
+			Expect(rerunTest.PastAttempts).To(HaveLen(1))
+			Expect(rerunTest.Flaky()).To(BeFalse())
+		})
+
+		It("splits nested classes into the lineage", func() {
+			fixture, err := os.Open("../../test/fixtures/surefire.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.JavaJUnitParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Tests[6].Lineage).To(Equal(
+				[]string{"com.example.CalculatorTest", "WhenNew", "isEmptyOnCreation"},
+			))
+		})
+
+		It("parses suites wrapped in a testsuites element", func() {
+			testResults, err := parsing.JavaJUnitParser{}.Parse(strings.NewReader(
+				`<testsuites><testsuite name="com.example.FooTest" tests="1" hostname="localhost">` +
+					`<testcase name="bar()" classname="com.example.FooTest" time="1,234.5"/>` +
+					`</testsuite></testsuites>`,
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(1))
+			Expect(testResults.Tests[0].Attempt.Duration.Seconds()).To(Equal(1234.5))
+		})
+
+		It("recognises Surefire suites by properties that follow their test cases", func() {
+			testResults, err := parsing.JavaJUnitParser{}.Parse(strings.NewReader(
+				`<testsuite name="FooTest" tests="1">` +
+					`<testcase name="bar()" classname="FooTest" time="0.1"/>` +
+					`<properties><property name="java.version" value="17"/></properties>` +
+					`</testsuite>`,
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(1))
+			Expect(testResults.Tests[0].Name).To(Equal("FooTest bar()"))
+		})
+
+		It("errors on malformed XML", func() {
+			testResults, err := parsing.JavaJUnitParser{}.Parse(strings.NewReader(`<abc`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unable to parse test results as XML"))
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors on JUnit XML that was not written by Surefire or Gradle", func() {
+			for _, path := range []string{
+				"../../test/fixtures/junit.xml",
+				"../../test/fixtures/exunit.xml",
+				"../../test/fixtures/cypress.xml",
+				"../../test/fixtures/phpunit.xml",
+				"../../test/fixtures/minitest.xml",
+				"../../test/fixtures/unittest.xml",
+			} {
+				fixture, err := os.Open(path)
+				Expect(err).ToNot(HaveOccurred())
+
+				testResults, err := parsing.JavaJUnitParser{}.Parse(fixture)
+				Expect(err).To(HaveOccurred(), path)
+				Expect(testResults).To(BeNil())
+			}
+		})
+	})
+})
@@ -51,6 +51,10 @@ type JavaScriptCucumberJSONFeature struct {
 	} `json:"elements"`
 }
 
+func (p JavaScriptCucumberJSONParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
 func (p JavaScriptCucumberJSONParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var cucumberFeatures []JavaScriptCucumberJSONFeature
 
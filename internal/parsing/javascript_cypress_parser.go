@@ -66,6 +66,10 @@ type JavaScriptCypressTestResults struct {
 
 var javaScriptCypressNewlineRegexp = regexp.MustCompile(`\r?\n`)
 
+func (p JavaScriptCypressParser) Sniff(header []byte) bool {
+	return sniffXML(header, "testsuites")
+}
+
 func (p JavaScriptCypressParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var testResults JavaScriptCypressTestResults
 
@@ -105,7 +109,12 @@ func (p JavaScriptCypressParser) Parse(data io.Reader) (*v1.TestResults, error)
 			properties[property.Name] = property.Value
 		}
 
-		for _, testCase := range testSuite.TestCases {
+		// With `retries` enabled, every attempt of a test ends up as its own test case in the same test suite, right
+		// after the failed attempt before it. The suite still counts the test only once, so there are more test cases
+		// than tests when anything was retried.
+		retriesPossible := testSuite.Tests == 0 || len(testSuite.TestCases) > testSuite.Tests
+		var previousTestCase *JavaScriptCypressTestCase
+		for i, testCase := range testSuite.TestCases {
 			// The mocha junit reporter library allows switching these
 			// We want the one that has the entire description (contains the short description)
 			// e.g. classname="Some Tests with some context it passes" name="it passes"
@@ -161,20 +170,33 @@ func (p JavaScriptCypressParser) Parse(data io.Reader) (*v1.TestResults, error)
 				location = &v1.Location{File: *currentFile}
 			}
 
-			tests = append(
-				tests,
-				v1.Test{
-					Name:     name,
-					Location: location,
-					Attempt: v1.TestAttempt{
-						Duration: &duration,
-						Meta:     properties,
-						Status:   status,
-						Stderr:   testCase.SystemErr,
-						Stdout:   testCase.SystemOut,
-					},
+			// Every attempt gets a meta of its own since tagging a test changes its meta in place
+			test := v1.Test{
+				Name:     name,
+				Location: location,
+				Attempt: v1.TestAttempt{
+					Duration: &duration,
+					Meta:     copyMeta(properties),
+					Status:   status,
+					Stderr:   testCase.SystemErr,
+					Stdout:   testCase.SystemOut,
 				},
-			)
+			}
+
+			isRetry := retriesPossible && previousTestCase != nil &&
+				previousTestCase.ClassName == testCase.ClassName &&
+				previousTestCase.Name == testCase.Name &&
+				tests[len(tests)-1].Attempt.Status.ImpliesFailure()
+			previousTestCase = &testSuite.TestCases[i]
+
+			if isRetry {
+				previousTest := tests[len(tests)-1]
+				test.PastAttempts = append(previousTest.PastAttempts, previousTest.Attempt)
+				tests[len(tests)-1] = test
+				continue
+			}
+
+			tests = append(tests, test)
 		}
 	}
 
@@ -230,6 +230,90 @@ var _ = Describe("JavaScriptCypressParser", func() {
 			Fail("Unreachable")
 		})
 
+		It("maps the attempts of retried tests to past attempts", func() {
+			testResults, err := parsing.JavaScriptCypressParser{}.Parse(strings.NewReader(
+				`
+					<testsuites tests="2">
+						<testsuite tests="2" file="cypress/e2e/todo.cy.js">
+							<testcase name="todo adds items" classname="adds items">
+								<failure message="first" type="AssertionError"><![CDATA[AssertionError: first]]></failure>
+							</testcase>
+							<testcase name="todo adds items" classname="adds items">
+								<failure message="second" type="AssertionError"><![CDATA[AssertionError: second]]></failure>
+							</testcase>
+							<testcase name="todo adds items" classname="adds items"></testcase>
+							<testcase name="todo removes items" classname="removes items"></testcase>
+						</testsuite>
+					</testsuites>
+				`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(2))
+			Expect(testResults.Summary.Retries).To(Equal(1))
+
+			test := testResults.Tests[0]
+			Expect(test.Name).To(Equal("todo adds items"))
+			Expect(test.Attempt.Status.Kind).To(Equal(v1.TestStatusSuccessful))
+			Expect(test.PastAttempts).To(HaveLen(2))
+			Expect(*test.PastAttempts[0].Status.Message).To(Equal("first"))
+			Expect(*test.PastAttempts[1].Status.Message).To(Equal("second"))
+			Expect(test.Flaky()).To(BeTrue())
+
+			Expect(testResults.Tests[1].PastAttempts).To(BeNil())
+		})
+
+		It("keeps tests apart that only share their title", func() {
+			testResults, err := parsing.JavaScriptCypressParser{}.Parse(strings.NewReader(
+				`
+					<testsuites tests="4">
+						<testsuite tests="4" file="cypress/e2e/todo.cy.js">
+							<testcase name="todo adds items" classname="adds items"></testcase>
+							<testcase name="todo adds items" classname="adds items">
+								<failure message="first" type="AssertionError"><![CDATA[AssertionError: first]]></failure>
+							</testcase>
+							<testcase name="todo removes items" classname="removes items"></testcase>
+							<testcase name="todo adds items" classname="adds items"></testcase>
+						</testsuite>
+						<testsuite tests="2" file="cypress/e2e/list.cy.js">
+							<testcase name="list sorts" classname="sorts">
+								<failure message="first" type="AssertionError"><![CDATA[AssertionError: first]]></failure>
+							</testcase>
+							<testcase name="list sorts" classname="sorts"></testcase>
+						</testsuite>
+					</testsuites>
+				`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(6))
+			Expect(testResults.Summary.Retries).To(Equal(0))
+
+			for _, test := range testResults.Tests {
+				Expect(test.PastAttempts).To(BeNil())
+			}
+		})
+
+		It("gives every attempt of a retried test a meta of its own", func() {
+			testResults, err := parsing.JavaScriptCypressParser{}.Parse(strings.NewReader(
+				`
+					<testsuites tests="1">
+						<testsuite tests="1" file="cypress/e2e/todo.cy.js">
+							<properties><property name="browser" value="chrome"/></properties>
+							<testcase name="todo adds items" classname="adds items">
+								<failure message="first" type="AssertionError"><![CDATA[AssertionError: first]]></failure>
+							</testcase>
+							<testcase name="todo adds items" classname="adds items"></testcase>
+						</testsuite>
+					</testsuites>
+				`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(1))
+
+			tagged := testResults.Tests[0].Tag("isolated", true)
+			Expect(tagged.Attempt.Meta).To(HaveKey("__rwx"))
+			Expect(tagged.PastAttempts[0].Meta).To(Equal(map[string]any{"browser": "chrome"}))
+		})
+
 		It("errors when presented with an <error> element", func() {
 			testResults, err := parsing.JavaScriptCypressParser{}.Parse(strings.NewReader(
 				`
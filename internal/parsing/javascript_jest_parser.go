@@ -111,6 +111,10 @@ var javaScriptJestNewlineRegexp = regexp.MustCompile(`\r?\n`)
 
 var javaScriptJestBacktraceSeparatorRegexp = regexp.MustCompile(`\r?\n\s{4}at`)
 
+func (p JavaScriptJestParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
 func (p JavaScriptJestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var testResults JavaScriptJestTestResults
 
@@ -61,6 +61,10 @@ type JavaScriptKarmaSummary struct {
 
 var javaScriptKarmaBacktraceSeparatorRegexp = regexp.MustCompile(`\r?\n *`)
 
+func (p JavaScriptKarmaParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
 func (p JavaScriptKarmaParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var testResults JavaScriptKarmaTestResults
 
@@ -56,6 +56,10 @@ type JavaScriptMochaTestResults struct {
 
 var javaScriptMochaBacktraceSeparatorRegexp = regexp.MustCompile(`\r?\n\s{4}at`)
 
+func (p JavaScriptMochaParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
 func (p JavaScriptMochaParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var testResults JavaScriptMochaTestResults
 
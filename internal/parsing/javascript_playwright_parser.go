@@ -121,6 +121,10 @@ type JavaScriptPlaywrightMetaFileAttachment struct {
 
 var javaScriptPlaywrightBacktraceSeparatorRegexp = regexp.MustCompile(`\r?\n\s{4}at`)
 
+func (p JavaScriptPlaywrightParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
 func (p JavaScriptPlaywrightParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var report JavaScriptPlaywrightReport
 
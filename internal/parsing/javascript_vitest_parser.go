@@ -88,6 +88,10 @@ type JavaScriptVitestTestResults struct {
 
 var javaScriptVitestBacktraceSeparatorRegexp = regexp.MustCompile(`\r?\n\s{4}at`)
 
+func (p JavaScriptVitestParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
 func (p JavaScriptVitestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var testResults JavaScriptVitestTestResults
 
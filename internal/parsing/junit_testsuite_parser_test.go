@@ -312,5 +312,25 @@ var _ = Describe("JUnitTestsuiteParser", func() {
 			Expect(testResults).NotTo(BeNil())
 			Expect(testResults.Tests[0].Name).To(Equal("prefix some test name"))
 		})
+
+		It("maps the properties and reruns of test cases", func() {
+			testResults, err := parsing.JUnitTestsuiteParser{}.Parse(strings.NewReader(
+				`
+					<testsuite tests="1">
+						<testcase name="some test name" classname="prefix">
+							<properties>
+								<property name="owner" value="team-a" />
+							</properties>
+							<flakyFailure message="expected 1 got 2" type="AssertionError" />
+						</testcase>
+					</testsuite>
+				`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testResults.Tests[0].Attempt.Meta).To(Equal(map[string]any{"owner": "team-a"}))
+			Expect(testResults.Tests[0].PastAttempts).To(HaveLen(1))
+			Expect(testResults.Tests[0].PastAttempts[0].Meta).To(Equal(map[string]any{"owner": "team-a"}))
+			Expect(testResults.Tests[0].Flaky()).To(BeTrue())
+		})
 	})
 })
@@ -1,6 +1,7 @@
 package parsing
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	"io"
@@ -22,19 +23,34 @@ type JUnitFailure struct {
 	ChardataContents *string `xml:",chardata"`
 }
 
+// Surefire and the Jenkins flaky test handler report every additional run of a test as one of these
+type JUnitRerun struct {
+	Type       *string `xml:"type,attr"`
+	Message    *string `xml:"message,attr"`
+	StackTrace *string `xml:"stackTrace"`
+	SystemErr  *string `xml:"system-err"`
+	SystemOut  *string `xml:"system-out"`
+}
+
 type JUnitSkipped struct {
 	Message *string `xml:"message,attr"`
+	Type    *string `xml:"type,attr"`
 }
 
 type JUnitTestCase struct {
-	ClassName string        `xml:"classname,attr,omitempty"`
-	Error     *JUnitFailure `xml:"error"`
-	Failure   *JUnitFailure `xml:"failure"`
-	Name      string        `xml:"name,attr"`
-	Skipped   *JUnitSkipped `xml:"skipped"`
-	SystemErr *string       `xml:"system-err"`
-	SystemOut *string       `xml:"system-out"`
-	Time      float64       `xml:"time,attr"`
+	ClassName     string           `xml:"classname,attr,omitempty"`
+	Error         *JUnitFailure    `xml:"error"`
+	Failure       *JUnitFailure    `xml:"failure"`
+	FlakyErrors   []JUnitRerun     `xml:"flakyError"`
+	FlakyFailures []JUnitRerun     `xml:"flakyFailure"`
+	Name          string           `xml:"name,attr"`
+	Properties    *JUnitProperties `xml:"properties"`
+	RerunErrors   []JUnitRerun     `xml:"rerunError"`
+	RerunFailures []JUnitRerun     `xml:"rerunFailure"`
+	Skipped       *JUnitSkipped    `xml:"skipped"`
+	SystemErr     *string          `xml:"system-err"`
+	SystemOut     *string          `xml:"system-out"`
+	Time          float64          `xml:"time,attr"`
 
 	// out of spec, but maybe interesting
 	File   *string `xml:"file,attr"`
@@ -49,6 +65,10 @@ type JUnitProperty struct {
 	Value string `xml:"value,attr"`
 }
 
+type JUnitProperties struct {
+	Properties []JUnitProperty `xml:"property"`
+}
+
 type JUnitTestSuite struct {
 	Errors     int             `xml:"errors,attr"`
 	Failures   int             `xml:"failures,attr"`
@@ -73,93 +93,57 @@ type JUnitTestResults struct {
 
 var jUnitNewlineRegexp = regexp.MustCompile(`\r?\n`)
 
-func (p JUnitTestsuitesParser) Parse(data io.Reader) (*v1.TestResults, error) {
-	var testResults JUnitTestResults
+func (p JUnitTestsuitesParser) Sniff(header []byte) bool {
+	return sniffXML(header, "testsuites")
+}
 
-	if err := xml.NewDecoder(data).Decode(&testResults); err != nil {
-		return nil, errors.NewInputError("Unable to parse test results as XML: %s", err)
-	}
+func (p JUnitTestsuitesParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	decoder := xml.NewDecoder(data)
 
-	if len(testResults.TestSuites) > 0 && testResults.TestSuites[0].Tests == nil {
-		return nil, errors.NewInputError("The test suites in the XML do not appear to match JUnit XML")
+	if _, err := decodeXMLRoot(decoder, "testsuites"); err != nil {
+		return nil, err
 	}
 
 	tests := make([]v1.Test, 0)
-	for _, testSuite := range testResults.TestSuites {
-		var properties map[string]any
-		if len(testSuite.Properties) > 0 {
-			properties = make(map[string]any)
-		}
-		for _, property := range testSuite.Properties {
-			properties[property.Name] = property.Value
-		}
-
-		for _, testCase := range testSuite.TestCases {
-			// The a lot of reporter libraries allow switching these
-			// We want the one that has the entire description (contains the short description)
-			// e.g. classname="Some Tests with some context it passes" name="it passes"
-			// We'd want the classname in the above case. Classname contains name, but name doesn't contain classname
-			var name string
-			switch {
-			case strings.Contains(testCase.Name, testCase.ClassName) && strings.Contains(testCase.ClassName, testCase.Name):
-				name = testCase.Name
-			case !strings.Contains(testCase.Name, testCase.ClassName) && strings.Contains(testCase.ClassName, testCase.Name):
-				name = testCase.ClassName
-			case strings.Contains(testCase.Name, testCase.ClassName) && !strings.Contains(testCase.ClassName, testCase.Name):
-				name = testCase.Name
-			case !strings.Contains(testCase.Name, testCase.ClassName) && !strings.Contains(testCase.ClassName, testCase.Name):
-				name = fmt.Sprintf("%s %s", testCase.ClassName, testCase.Name)
-			default:
-				return nil, errors.NewInternalError("Unreachable: reached default case of exhaustive switch statement")
-			}
+	firstTestSuite := true
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, errors.NewInputError("Unable to parse test results as XML: %s", err)
+		}
 
-			duration := time.Duration(math.Round(testCase.Time * float64(time.Second)))
-
-			var status v1.TestStatus
-			switch {
-			case testCase.Failure != nil:
-				status = p.NewFailedTestStatus(*testCase.Failure)
-			case testCase.Error != nil:
-				status = p.NewFailedTestStatus(*testCase.Error)
-			case testCase.Skipped != nil:
-				status = v1.NewSkippedTestStatus(testCase.Skipped.Message)
-			default:
-				status = v1.NewSuccessfulTestStatus()
-			}
+		if _, ok := token.(xml.EndElement); ok {
+			break
+		}
 
-			var location *v1.Location
-			switch {
-			case testCase.File != nil:
-				location = &v1.Location{File: *testCase.File}
-			case testSuite.File != nil:
-				location = &v1.Location{File: *testSuite.File}
-			default:
-				location = nil
-			}
-			switch {
-			case location != nil && testCase.Line != nil:
-				location.Line = testCase.Line
-			case location != nil && testCase.Lineno != nil:
-				location.Line = testCase.Lineno
-			default:
-				// nothing to do here
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if element.Name.Local != "testsuite" {
+			if err := decoder.Skip(); err != nil {
+				return nil, errors.NewInputError("Unable to parse test results as XML: %s", err)
 			}
+			continue
+		}
 
-			tests = append(
-				tests,
-				v1.Test{
-					Name:     name,
-					Location: location,
-					Attempt: v1.TestAttempt{
-						Duration: &duration,
-						Meta:     properties,
-						Status:   status,
-						Stderr:   testCase.SystemErr,
-						Stdout:   testCase.SystemOut,
-					},
-				},
-			)
+		testSuite, err := p.decodeTestSuiteAttributes(element)
+		if err != nil {
+			return nil, err
+		}
+
+		if firstTestSuite && testSuite.Tests == nil {
+			return nil, errors.NewInputError("The test suites in the XML do not appear to match JUnit XML")
+		}
+		firstTestSuite = false
+
+		testSuiteTests, err := p.decodeTestCases(decoder, testSuite)
+		if err != nil {
+			return nil, err
 		}
+
+		tests = append(tests, testSuiteTests...)
 	}
 
 	return v1.NewTestResults(
@@ -169,6 +153,207 @@ func (p JUnitTestsuitesParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	), nil
 }
 
+// decodeTestSuiteAttributes decodes the attributes of a `<testsuite>` on their own so that its test cases can be
+// converted one at a time
+func (p JUnitTestsuitesParser) decodeTestSuiteAttributes(element xml.StartElement) (JUnitTestSuite, error) {
+	var testSuite JUnitTestSuite
+	err := decodeXMLAttributes(element, &testSuite)
+	return testSuite, err
+}
+
+// decodeTestCases converts the `<testcase>` elements of a `<testsuite>` while they are being read, up until the end of
+// the test suite
+func (p JUnitTestsuitesParser) decodeTestCases(decoder *xml.Decoder, testSuite JUnitTestSuite) ([]v1.Test, error) {
+	tests := make([]v1.Test, 0)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, errors.NewInputError("Unable to parse test results as XML: %s", err)
+		}
+
+		if _, ok := token.(xml.EndElement); ok {
+			break
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch element.Name.Local {
+		case "testcase":
+			var testCase JUnitTestCase
+			if err := decoder.DecodeElement(&testCase, &element); err != nil {
+				return nil, errors.NewInputError("Unable to parse test results as XML: %s", err)
+			}
+
+			test, err := p.newTest(testSuite, testCase)
+			if err != nil {
+				return nil, err
+			}
+
+			tests = append(tests, test)
+		case "properties":
+			var properties struct {
+				Properties []JUnitProperty `xml:"property"`
+			}
+			if err := decoder.DecodeElement(&properties, &element); err != nil {
+				return nil, errors.NewInputError("Unable to parse test results as XML: %s", err)
+			}
+
+			testSuite.Properties = append(testSuite.Properties, properties.Properties...)
+		default:
+			if err := decoder.Skip(); err != nil {
+				return nil, errors.NewInputError("Unable to parse test results as XML: %s", err)
+			}
+		}
+	}
+
+	// Properties apply to every test case of the suite, including the ones that came before them. A test case's own
+	// properties take precedence over the ones of its suite.
+	// Every attempt gets a meta of its own since tagging a test changes its meta in place.
+	properties := jUnitMeta(testSuite.Properties)
+	for i := range tests {
+		meta := tests[i].Attempt.Meta
+		for name, value := range properties {
+			if _, ok := meta[name]; !ok {
+				if meta == nil {
+					meta = make(map[string]any, len(properties))
+				}
+				meta[name] = value
+			}
+		}
+
+		tests[i].Attempt.Meta = meta
+		for j := range tests[i].PastAttempts {
+			tests[i].PastAttempts[j].Meta = copyMeta(meta)
+		}
+	}
+
+	return tests, nil
+}
+
+func (p JUnitTestsuitesParser) newTest(testSuite JUnitTestSuite, testCase JUnitTestCase) (v1.Test, error) {
+	// The a lot of reporter libraries allow switching these
+	// We want the one that has the entire description (contains the short description)
+	// e.g. classname="Some Tests with some context it passes" name="it passes"
+	// We'd want the classname in the above case. Classname contains name, but name doesn't contain classname
+	var name string
+	switch {
+	case strings.Contains(testCase.Name, testCase.ClassName) && strings.Contains(testCase.ClassName, testCase.Name):
+		name = testCase.Name
+	case !strings.Contains(testCase.Name, testCase.ClassName) && strings.Contains(testCase.ClassName, testCase.Name):
+		name = testCase.ClassName
+	case strings.Contains(testCase.Name, testCase.ClassName) && !strings.Contains(testCase.ClassName, testCase.Name):
+		name = testCase.Name
+	case !strings.Contains(testCase.Name, testCase.ClassName) && !strings.Contains(testCase.ClassName, testCase.Name):
+		name = fmt.Sprintf("%s %s", testCase.ClassName, testCase.Name)
+	default:
+		return v1.Test{}, errors.NewInternalError("Unreachable: reached default case of exhaustive switch statement")
+	}
+
+	duration := time.Duration(math.Round(testCase.Time * float64(time.Second)))
+
+	var status v1.TestStatus
+	switch {
+	case testCase.Failure != nil:
+		status = p.NewFailedTestStatus(*testCase.Failure)
+	case testCase.Error != nil:
+		status = p.NewFailedTestStatus(*testCase.Error)
+	case testCase.Skipped != nil:
+		status = v1.NewSkippedTestStatus(testCase.Skipped.Message)
+	default:
+		status = v1.NewSuccessfulTestStatus()
+	}
+
+	var location *v1.Location
+	switch {
+	case testCase.File != nil:
+		location = &v1.Location{File: *testCase.File}
+	case testSuite.File != nil:
+		location = &v1.Location{File: *testSuite.File}
+	default:
+		location = nil
+	}
+	switch {
+	case location != nil && testCase.Line != nil:
+		location.Line = testCase.Line
+	case location != nil && testCase.Lineno != nil:
+		location.Line = testCase.Lineno
+	default:
+		// nothing to do here
+	}
+
+	var meta map[string]any
+	if testCase.Properties != nil {
+		meta = jUnitMeta(testCase.Properties.Properties)
+	}
+
+	// Reruns by the framework itself (e.g. surefire's `rerunFailingTestsCount`) are all failed attempts. The test
+	// case's own outcome tells whether the test eventually passed.
+	pastAttempts := make([]v1.TestAttempt, 0)
+	for _, reruns := range [][]JUnitRerun{
+		testCase.FlakyFailures,
+		testCase.FlakyErrors,
+		testCase.RerunFailures,
+		testCase.RerunErrors,
+	} {
+		for _, rerun := range reruns {
+			pastAttempts = append(pastAttempts, v1.TestAttempt{
+				Status: p.NewFailedTestStatus(JUnitFailure{
+					Type:             rerun.Type,
+					Message:          rerun.Message,
+					ChardataContents: rerun.StackTrace,
+				}),
+				Stderr: rerun.SystemErr,
+				Stdout: rerun.SystemOut,
+			})
+		}
+	}
+	if len(pastAttempts) == 0 {
+		pastAttempts = nil
+	}
+
+	return v1.Test{
+		Name:     name,
+		Location: location,
+		Attempt: v1.TestAttempt{
+			Duration: &duration,
+			Meta:     meta,
+			Status:   status,
+			Stderr:   testCase.SystemErr,
+			Stdout:   testCase.SystemOut,
+		},
+		PastAttempts: pastAttempts,
+	}, nil
+}
+
+func copyMeta(meta map[string]any) map[string]any {
+	if meta == nil {
+		return nil
+	}
+
+	copied := make(map[string]any, len(meta))
+	for name, value := range meta {
+		copied[name] = value
+	}
+
+	return copied
+}
+
+func jUnitMeta(properties []JUnitProperty) map[string]any {
+	if len(properties) == 0 {
+		return nil
+	}
+
+	meta := make(map[string]any, len(properties))
+	for _, property := range properties {
+		meta[property.Name] = property.Value
+	}
+
+	return meta
+}
+
 func (p JUnitTestsuitesParser) NewFailedTestStatus(failure JUnitFailure) v1.TestStatus {
 	failureMessage := failure.Message
 	failureException := failure.Type
@@ -193,3 +378,113 @@ func (p JUnitTestsuitesParser) NewFailedTestStatus(failure JUnitFailure) v1.Test
 	}
 	return v1.NewFailedTestStatus(failureMessage, failureException, backtrace)
 }
+
+// decodeXMLRoot reads up to the root element, which is expected to be `rootElement`
+func decodeXMLRoot(decoder *xml.Decoder, rootElement string) (xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.StartElement{}, errors.NewInputError("Unable to parse test results as XML: %s", err)
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if element.Name.Local != rootElement {
+			return xml.StartElement{}, errors.NewInputError(
+				"Unable to parse test results as XML: expected element type <%s> but have <%s>",
+				rootElement,
+				element.Name.Local,
+			)
+		}
+
+		return element, nil
+	}
+}
+
+// decodeXMLAttributes decodes only the attributes of an element into `v`, without reading any of its children
+func decodeXMLAttributes(element xml.StartElement, v any) error {
+	var buf bytes.Buffer
+
+	encoder := xml.NewEncoder(&buf)
+	if err := encoder.EncodeToken(element); err != nil {
+		return errors.NewInputError("Unable to parse test results as XML: %s", err)
+	}
+	if err := encoder.EncodeToken(element.End()); err != nil {
+		return errors.NewInputError("Unable to parse test results as XML: %s", err)
+	}
+	if err := encoder.Flush(); err != nil {
+		return errors.NewInputError("Unable to parse test results as XML: %s", err)
+	}
+
+	if err := xml.Unmarshal(buf.Bytes(), v); err != nil {
+		return errors.NewInputError("Unable to parse test results as XML: %s", err)
+	}
+
+	return nil
+}
+
+// decodeXMLChildren calls `decodeChild` for every child element up until the end of the current element. `decodeChild`
+// needs to consume the child, e.g. with `DecodeElement` or `Skip`.
+func decodeXMLChildren(decoder *xml.Decoder, decodeChild func(xml.StartElement) error) error {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return errors.NewInputError("Unable to parse test results as XML: %s", err)
+		}
+
+		if _, ok := token.(xml.EndElement); ok {
+			return nil
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if err := decodeChild(element); err != nil {
+			return err
+		}
+	}
+}
+
+// streamJUnitTestSuites calls `decodeTestSuite` for every `<testsuite>` while the XML is being read, no matter whether
+// it's the root element or the test suites are wrapped in `<testsuites>`
+func streamJUnitTestSuites(decoder *xml.Decoder, decodeTestSuite func(xml.StartElement) error) error {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return errors.NewInputError("Unable to parse test results as XML: %s", err)
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch element.Name.Local {
+		case "testsuite":
+			return decodeTestSuite(element)
+		case "testsuites":
+			return decodeXMLChildren(decoder, func(element xml.StartElement) error {
+				if element.Name.Local != "testsuite" {
+					return skipXMLElement(decoder)
+				}
+
+				return decodeTestSuite(element)
+			})
+		default:
+			return errors.NewInputError("Unexpected root element <%v> in JUnit XML", element.Name.Local)
+		}
+	}
+}
+
+func skipXMLElement(decoder *xml.Decoder) error {
+	if err := decoder.Skip(); err != nil {
+		return errors.NewInputError("Unable to parse test results as XML: %s", err)
+	}
+
+	return nil
+}
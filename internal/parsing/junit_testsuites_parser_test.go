@@ -338,5 +338,133 @@ var _ = Describe("JUnitTestsuitesParser", func() {
 			Expect(testResults).NotTo(BeNil())
 			Expect(testResults.Tests[0].Name).To(Equal("prefix some test name"))
 		})
+
+		It("applies the properties to every test case of the suite, wherever they appear", func() {
+			testResults, err := parsing.JUnitTestsuitesParser{}.Parse(strings.NewReader(
+				`
+					<testsuites>
+						<testsuite tests="2">
+							<testcase name="first" classname="first"></testcase>
+							<properties>
+								<property name="browser" value="firefox" />
+							</properties>
+							<testcase name="second" classname="second"></testcase>
+						</testsuite>
+						<testsuite tests="1">
+							<testcase name="third" classname="third"></testcase>
+						</testsuite>
+					</testsuites>
+				`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(3))
+			Expect(testResults.Tests[0].Attempt.Meta).To(Equal(map[string]any{"browser": "firefox"}))
+			Expect(testResults.Tests[1].Attempt.Meta).To(Equal(map[string]any{"browser": "firefox"}))
+			Expect(testResults.Tests[2].Attempt.Meta).To(BeNil())
+		})
+
+		It("lets the properties of a test case take precedence over the ones of its suite", func() {
+			testResults, err := parsing.JUnitTestsuitesParser{}.Parse(strings.NewReader(
+				`
+					<testsuites>
+						<testsuite tests="2">
+							<properties>
+								<property name="browser" value="firefox" />
+								<property name="os" value="linux" />
+							</properties>
+							<testcase name="first" classname="first">
+								<properties>
+									<property name="browser" value="chrome" />
+									<property name="owner" value="team-a" />
+								</properties>
+							</testcase>
+							<testcase name="second" classname="second"></testcase>
+						</testsuite>
+					</testsuites>
+				`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(2))
+			Expect(testResults.Tests[0].Attempt.Meta).To(Equal(
+				map[string]any{"browser": "chrome", "os": "linux", "owner": "team-a"},
+			))
+			Expect(testResults.Tests[1].Attempt.Meta).To(Equal(map[string]any{"browser": "firefox", "os": "linux"}))
+		})
+
+		It("gives every test and attempt a meta of its own", func() {
+			testResults, err := parsing.JUnitTestsuitesParser{}.Parse(strings.NewReader(
+				`
+					<testsuites>
+						<testsuite tests="2">
+							<properties>
+								<property name="browser" value="firefox" />
+							</properties>
+							<testcase name="first" classname="first">
+								<flakyFailure message="expected 1 got 2" type="AssertionError"></flakyFailure>
+							</testcase>
+							<testcase name="second" classname="second"></testcase>
+						</testsuite>
+					</testsuites>
+				`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(2))
+
+			tagged := testResults.Tests[0].Tag("isolated", true)
+			Expect(tagged.Attempt.Meta).To(HaveKey("__rwx"))
+			Expect(tagged.PastAttempts[0].Meta).To(Equal(map[string]any{"browser": "firefox"}))
+			Expect(testResults.Tests[1].Attempt.Meta).To(Equal(map[string]any{"browser": "firefox"}))
+		})
+
+		It("maps reruns by the framework itself to past attempts", func() {
+			testResults, err := parsing.JUnitTestsuitesParser{}.Parse(strings.NewReader(
+				`
+					<testsuites>
+						<testsuite tests="2" file="spec/checkout_spec.rb">
+							<testcase name="flaky" classname="checkout" line="12">
+								<flakyFailure message="expected 1 got 2" type="AssertionError">
+									<stackTrace>at checkout_spec.rb:14</stackTrace>
+									<system-err>retrying</system-err>
+								</flakyFailure>
+								<flakyError message="timed out" type="TimeoutError"></flakyError>
+								<system-err>passed on the third run</system-err>
+							</testcase>
+							<testcase name="broken" classname="checkout">
+								<failure message="expected 1 got 3" type="AssertionError"></failure>
+								<rerunFailure message="expected 1 got 4" type="AssertionError"></rerunFailure>
+							</testcase>
+						</testsuite>
+					</testsuites>
+				`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(2))
+			Expect(testResults.Summary.Retries).To(Equal(2))
+
+			flakyTest := testResults.Tests[0]
+			Expect(flakyTest.Location.String()).To(Equal("spec/checkout_spec.rb:12"))
+			Expect(*flakyTest.Attempt.Stderr).To(Equal("passed on the third run"))
+			Expect(flakyTest.PastAttempts).To(HaveLen(2))
+			Expect(*flakyTest.PastAttempts[0].Status.Message).To(Equal("expected 1 got 2"))
+			Expect(*flakyTest.PastAttempts[0].Status.Exception).To(Equal("AssertionError"))
+			Expect(flakyTest.PastAttempts[0].Status.Backtrace).To(Equal([]string{"at checkout_spec.rb:14"}))
+			Expect(*flakyTest.PastAttempts[0].Stderr).To(Equal("retrying"))
+			Expect(*flakyTest.PastAttempts[1].Status.Exception).To(Equal("TimeoutError"))
+			Expect(flakyTest.Flaky()).To(BeTrue())
+
+			brokenTest := testResults.Tests[1]
+			Expect(brokenTest.Attempt.Status.Kind).To(Equal(v1.TestStatusFailed))
+			Expect(brokenTest.PastAttempts).To(HaveLen(1))
+			Expect(*brokenTest.PastAttempts[0].Status.Message).To(Equal("expected 1 got 4"))
+			Expect(brokenTest.Flaky()).To(BeFalse())
+		})
+	})
+
+	Describe("Sniff", func() {
+		It("only accepts XML with a testsuites root element", func() {
+			Expect(parsing.JUnitTestsuitesParser{}.Sniff([]byte(`<?xml version="1.0"?><testsuites>`))).To(BeTrue())
+			Expect(parsing.JUnitTestsuitesParser{}.Sniff([]byte(`<testsuite tests="1">`))).To(BeFalse())
+			Expect(parsing.JUnitTestsuitesParser{}.Sniff([]byte(`{"testsuites": []}`))).To(BeFalse())
+		})
 	})
 })
@@ -6,6 +6,7 @@ package parsing
 import (
 	"encoding/base64"
 	"io"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -20,9 +21,15 @@ type Config struct {
 	MutuallyExclusiveParsers  []Parser
 	GenericParsers            []Parser
 	FrameworkParsers          map[v1.Framework][]Parser
+	BazelTestlogsPath         string
+	BazelBuildEventJSONPath   string
+	MaxDerivedFromSize        int64
 	Logger                    *zap.SugaredLogger
 }
 
+// The size in bytes above which the contents of a test results file are no longer kept in `DerivedFrom`
+const DefaultMaxDerivedFromSize = 25 * 1024 * 1024
+
 func (c Config) Validate() error {
 	if c.ProvidedFrameworkKind != "" && c.ProvidedFrameworkLanguage == "" {
 		return errors.NewConfigurationError(
@@ -44,6 +51,16 @@ func (c Config) Validate() error {
 		)
 	}
 
+	if c.BazelBuildEventJSONPath != "" && c.BazelTestlogsPath == "" {
+		return errors.NewConfigurationError(
+			"Unable to read Bazel build events",
+			"You provided a Bazel build event file, but not the location of the Bazel test logs. Captain needs "+
+				"the test logs in order to know which test results belong to which target.",
+			"The test logs can be set using the --bazel-testlogs flag (usually to 'bazel-testlogs'). Alternatively, "+
+				"you can use the Captain configuration file to permanently set the Bazel options for a test suite.",
+		)
+	}
+
 	if c.Logger == nil {
 		return errors.NewInternalError("No logger was provided")
 	}
@@ -51,7 +68,27 @@ func (c Config) Validate() error {
 	return nil
 }
 
+// Parse parses a file that holds the test results of a single framework
 func Parse(file fs.File, groupNumber int, cfg Config) (*v1.TestResults, error) {
+	allResults, err := ParseAll(file, groupNumber, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(allResults) != 1 {
+		return nil, errors.NewInputError(
+			"Expected the test results of a single framework in %q, found %d",
+			file.Name(),
+			len(allResults),
+		)
+	}
+
+	return &allResults[0], nil
+}
+
+// ParseAll parses a file that might hold the test results of several frameworks, like the RWX JSON that captain itself
+// writes for them.
+func ParseAll(file fs.File, groupNumber int, cfg Config) ([]v1.TestResults, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -82,83 +119,163 @@ func Parse(file fs.File, groupNumber int, cfg Config) (*v1.TestResults, error) {
 		coercedFramework = &framework
 	}
 
-	results, err := parseWith(file, parsers, groupNumber, cfg.Logger)
+	allResults, err := parseWith(file, parsers, groupNumber, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if coercedFramework != nil {
-		results.Framework = *coercedFramework
-	}
+	for i := range allResults {
+		if coercedFramework != nil {
+			allResults[i].Framework = *coercedFramework
+		}
 
-	if results.Framework.IsOther() && !results.Framework.IsProvided() {
-		cfg.Logger.Warnf(
-			"We could not determine which framework produced your test results. " +
-				"For a better experience in Captain, specify both your --language and --framework.",
-		)
+		if allResults[i].Framework.IsOther() && !allResults[i].Framework.IsProvided() {
+			cfg.Logger.Warnf(
+				"We could not determine which framework produced your test results. " +
+					"For a better experience in Captain, specify both your --language and --framework.",
+			)
+		}
 	}
 
-	return results, nil
+	return allResults, nil
 }
 
-func parseWith(file fs.File, parsers []Parser, groupNumber int, log *zap.SugaredLogger) (*v1.TestResults, error) {
+func parseWith(file fs.File, parsers []Parser, groupNumber int, cfg Config) ([]v1.TestResults, error) {
 	if len(parsers) == 0 {
 		return nil, errors.NewInternalError("No parsers were provided")
 	}
 
-	parsedTestResults := make([]v1.TestResults, 0)
-	var firstParser Parser
+	header, err := readHeader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var finalResults []v1.TestResults
+	var responsibleParser Parser
 	for _, parser := range parsers {
+		if sniffer, ok := parser.(Sniffer); ok && !sniffer.Sniff(header) {
+			cfg.Logger.Debugf("%T was not capable of parsing the test results judging by the start of the file", parser)
+			continue
+		}
+
 		if err := rewindFile(file); err != nil {
 			return nil, err
 		}
 
-		parsedTestResult, err := parser.Parse(file)
+		parsedTestResults, err := parseAllWith(file, parser)
 		if err != nil {
-			log.Debugf("%T was not capable of parsing the test results. Error: %v", parser, err)
+			cfg.Logger.Debugf("%T was not capable of parsing the test results. Error: %v", parser, err)
 			continue
 		}
-		if parsedTestResult == nil {
+		if len(parsedTestResults) == 0 {
 			return nil, errors.NewInternalError("%T did not error and did not return a test result", parser)
 		}
-		log.Debugf("%T was capable of parsing the test results.", parser)
+		cfg.Logger.Debugf("%T was capable of parsing the test results.", parser)
 
-		if firstParser == nil {
-			firstParser = parser
-		}
-		parsedTestResults = append(parsedTestResults, *parsedTestResult)
+		// Parsing is the expensive part with large files, so we stop at the first parser that succeeds
+		finalResults = parsedTestResults
+		responsibleParser = parser
+		break
 	}
 
-	if len(parsedTestResults) == 0 {
+	if finalResults == nil {
 		return nil, errors.NewInputError("No parsers were capable of parsing the provided test results")
 	}
 
-	finalResults := parsedTestResults[0]
-	log.Debugf("%T was ultimately responsible for parsing the test results", firstParser)
+	cfg.Logger.Debugf("%T was ultimately responsible for parsing the test results", responsibleParser)
+
+	// only set DerivedFrom for non-RWX parsers
+	if _, ok := responsibleParser.(RWXParser); !ok {
+		originalTestResults, err := newOriginalTestResults(file, groupNumber, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range finalResults {
+			finalResults[i].DerivedFrom = []v1.OriginalTestResults{originalTestResults}
+		}
+	}
 
 	if err := rewindFile(file); err != nil {
 		return nil, err
 	}
-	buf, err := io.ReadAll(file)
-	if err != nil {
-		return nil, errors.NewSystemError("Unable to read file into buffer: %s", err)
+
+	return finalResults, nil
+}
+
+// parseAllWith parses the file with a single parser, which might return the test results of several frameworks
+func parseAllWith(file fs.File, parser Parser) ([]v1.TestResults, error) {
+	if multiParser, ok := parser.(MultiParser); ok {
+		allResults, err := multiParser.ParseAll(file)
+		return allResults, errors.WithStack(err)
+	}
+
+	testResults, err := parser.Parse(file)
+	if err != nil || testResults == nil {
+		return nil, errors.WithStack(err)
 	}
+
+	return []v1.TestResults{*testResults}, nil
+}
+
+func readHeader(file fs.File) ([]byte, error) {
 	if err := rewindFile(file); err != nil {
 		return nil, err
 	}
 
-	// only set DerivedFrom for non-RWX parsers
-	if _, ok := firstParser.(RWXParser); !ok {
-		finalResults.DerivedFrom = []v1.OriginalTestResults{
-			{
-				OriginalFilePath: file.Name(),
-				Contents:         base64.StdEncoding.EncodeToString(buf),
-				GroupNumber:      groupNumber,
-			},
-		}
+	header := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, errors.NewSystemError("Unable to read file: %s", err)
+	}
+
+	return header[:n], nil
+}
+
+// newOriginalTestResults base64-encodes the file while reading it. Files larger than `MaxDerivedFromSize` are only
+// referenced by their path so that memory usage doesn't grow with the size of the test results.
+func newOriginalTestResults(file fs.File, groupNumber int, cfg Config) (v1.OriginalTestResults, error) {
+	originalTestResults := v1.OriginalTestResults{
+		OriginalFilePath: file.Name(),
+		GroupNumber:      groupNumber,
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return originalTestResults, errors.NewSystemError("Unable to determine file-size for %q: %s", file.Name(), err)
+	}
+
+	maxSize := cfg.MaxDerivedFromSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxDerivedFromSize
+	}
+
+	if fileInfo.Size() > maxSize {
+		cfg.Logger.Debugf(
+			"%q is larger than %v bytes, its original contents will not be kept with the test results",
+			file.Name(),
+			maxSize,
+		)
+		return originalTestResults, nil
+	}
+
+	if err := rewindFile(file); err != nil {
+		return originalTestResults, err
+	}
+
+	var contents strings.Builder
+	contents.Grow(base64.StdEncoding.EncodedLen(int(fileInfo.Size())))
+
+	encoder := base64.NewEncoder(base64.StdEncoding, &contents)
+	if _, err := io.Copy(encoder, file); err != nil {
+		return originalTestResults, errors.NewSystemError("Unable to read file into buffer: %s", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return originalTestResults, errors.NewSystemError("Unable to encode file: %s", err)
 	}
 
-	return &finalResults, nil
+	originalTestResults.Contents = contents.String()
+	return originalTestResults, nil
 }
 
 func rewindFile(file fs.File) error {
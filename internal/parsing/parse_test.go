@@ -2,6 +2,7 @@ package parsing_test
 
 import (
 	"encoding/base64"
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -49,6 +50,18 @@ func (p ErrorParser) Parse(testResults io.Reader) (*v1.TestResults, error) {
 	return nil, errors.NewInternalError("could not parse")
 }
 
+type SniffingParser struct{}
+
+func (p SniffingParser) Sniff(header []byte) bool {
+	Expect(string(header)).To(Equal("the fake contents to base64 encode"))
+	return false
+}
+
+func (p SniffingParser) Parse(_ io.Reader) (*v1.TestResults, error) {
+	Fail("the parser should not have been attempted")
+	return nil, nil
+}
+
 type NeitherErrorNorResultsParser struct{}
 
 func (p NeitherErrorNorResultsParser) Parse(_ io.Reader) (*v1.TestResults, error) {
@@ -165,8 +178,8 @@ var _ = Describe("Parse", func() {
 			2,
 			parsing.Config{
 				MutuallyExclusiveParsers: []parsing.Parser{
-					SuccessfulParserTwo{},
 					ErrorParser{},
+					SuccessfulParserTwo{},
 					SuccessfulParserOne{},
 				},
 				Logger: log,
@@ -194,12 +207,12 @@ var _ = Describe("Parse", func() {
 		Expect(logMessages).To(ContainElement(
 			ContainSubstring("ErrorParser was not capable of parsing the test results"),
 		))
-		Expect(logMessages).To(ContainElement(
-			ContainSubstring("SuccessfulParserOne was capable of parsing the test results."),
-		))
 		Expect(logMessages).To(ContainElement(
 			ContainSubstring("SuccessfulParserTwo was capable of parsing the test results."),
 		))
+		Expect(logMessages).NotTo(ContainElement(
+			ContainSubstring("SuccessfulParserOne"),
+		))
 		Expect(logMessages).To(ContainElement(
 			ContainSubstring("SuccessfulParserTwo was ultimately responsible for parsing the test results"),
 		))
@@ -210,6 +223,49 @@ var _ = Describe("Parse", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("skips parsers that rule out the file by its header", func() {
+		results, err := parsing.Parse(
+			file,
+			1,
+			parsing.Config{
+				MutuallyExclusiveParsers: []parsing.Parser{
+					SniffingParser{},
+					SuccessfulParserOne{},
+				},
+				Logger: log,
+			},
+		)
+
+		Expect(err).To(BeNil())
+		Expect(*results.Framework.ProvidedKind).To(Equal("one"))
+
+		logMessages := make([]string, 0)
+		for _, log := range recordedLogs.All() {
+			logMessages = append(logMessages, log.Message)
+		}
+
+		Expect(logMessages).To(ContainElement(
+			ContainSubstring("SniffingParser was not capable of parsing the test results judging by the start of the file"),
+		))
+	})
+
+	It("only references the original file when it is larger than the maximum size", func() {
+		results, err := parsing.Parse(
+			file,
+			1,
+			parsing.Config{
+				MutuallyExclusiveParsers: []parsing.Parser{SuccessfulParserOne{}},
+				MaxDerivedFromSize:       10,
+				Logger:                   log,
+			},
+		)
+
+		Expect(err).To(BeNil())
+		Expect(results.DerivedFrom).To(Equal(
+			[]v1.OriginalTestResults{{OriginalFilePath: "some/path/to/file", GroupNumber: 1}},
+		))
+	})
+
 	It("does not set DerivedFrom when parsing with the RWX parser", func() {
 		fixture, err := os.Open("../../test/fixtures/rwx/v1_not_derived.json")
 		Expect(err).ToNot(HaveOccurred())
@@ -234,6 +290,27 @@ var _ = Describe("Parse", func() {
 		Expect(err).To(BeNil())
 	})
 
+	It("parses the test results of several frameworks from a single file", func() {
+		fixture, err := os.ReadFile("../../test/fixtures/rwx/v1_not_derived.json")
+		Expect(err).ToNot(HaveOccurred())
+
+		file = new(mocks.File)
+		file.Reader = strings.NewReader(fmt.Sprintf("[%s, %s]", fixture, fixture))
+		file.MockName = func() string { return "some/path/to/file" }
+		cfg := parsing.Config{
+			MutuallyExclusiveParsers: []parsing.Parser{parsing.RWXParser{}},
+			Logger:                   log,
+		}
+
+		allResults, err := parsing.ParseAll(file, 1, cfg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allResults).To(HaveLen(2))
+
+		results, err := parsing.Parse(file, 1, cfg)
+		Expect(err).To(HaveOccurred())
+		Expect(results).To(BeNil())
+	})
+
 	Describe("when no language and kind are provided", func() {
 		It("uses the mutually exclusive and generic parsers to auto-detect framework", func() {
 			results, err := parsing.Parse(
@@ -9,3 +9,9 @@ import (
 type Parser interface {
 	Parse(io.Reader) (*v1.TestResults, error)
 }
+
+// MultiParser is implemented by parsers of formats that can hold the test results of several frameworks in a single
+// file. `ParseAll` is used in place of `Parse` for them.
+type MultiParser interface {
+	ParseAll(io.Reader) ([]v1.TestResults, error)
+}
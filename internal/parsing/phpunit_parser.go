@@ -58,6 +58,10 @@ type PHPUnitTestResults struct {
 
 var phpUnitNewlineRegexp = regexp.MustCompile(`\r?\n`)
 
+func (p PHPUnitParser) Sniff(header []byte) bool {
+	return sniffXML(header, "testsuites")
+}
+
 func (p PHPUnitParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var testResults PHPUnitTestResults
 
@@ -0,0 +1,174 @@
+package parsing
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// Parses the JUnit XML written by `pytest --junitxml`
+type PythonPytestJUnitParser struct {
+	// Without it, files are only recognised by pytest's default suite name or its skip & xfail markers
+	AssumePytest bool
+}
+
+func (p PythonPytestJUnitParser) Sniff(header []byte) bool {
+	if !sniffXML(header, "testsuites", "testsuite") {
+		return false
+	}
+
+	return p.AssumePytest || bytes.Contains(header, []byte("pytest"))
+}
+
+func (p PythonPytestJUnitParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	decoder := xml.NewDecoder(data)
+
+	tests := make([]v1.Test, 0)
+	sawTestSuite := false
+	sawPytest := p.AssumePytest
+
+	// pytest used to write a single `<testsuite>`, newer versions wrap it in `<testsuites>`
+	err := streamJUnitTestSuites(decoder, func(element xml.StartElement) error {
+		var testSuite JUnitTestSuite
+		if err := decodeXMLAttributes(element, &testSuite); err != nil {
+			return err
+		}
+
+		if !sawTestSuite && testSuite.Tests == nil {
+			return errors.NewInputError("The test suites in the XML do not appear to match JUnit XML")
+		}
+		sawTestSuite = true
+
+		// `junit_suite_name` defaults to "pytest"
+		sawPytest = sawPytest || testSuite.Name == "pytest"
+
+		return decodeXMLChildren(decoder, func(element xml.StartElement) error {
+			if element.Name.Local != "testcase" {
+				return skipXMLElement(decoder)
+			}
+
+			var testCase JUnitTestCase
+			if err := decoder.DecodeElement(&testCase, &element); err != nil {
+				return errors.NewInputError("Unable to parse test results as XML: %s", err)
+			}
+
+			sawPytest = sawPytest || p.skippedByPytest(testCase)
+			tests = append(tests, p.newTest(testCase))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !sawTestSuite {
+		return nil, errors.NewInputError("The test suites in the XML do not appear to match JUnit XML")
+	}
+
+	if !sawPytest {
+		return nil, errors.NewInputError("The test suites in the XML do not appear to be written by pytest")
+	}
+
+	return v1.NewTestResults(
+		v1.PythonPytestFramework,
+		tests,
+		nil,
+	), nil
+}
+
+// pytest marks the skips & expected failures it reports with its own types, e.g. `pytest.skip`
+func (p PythonPytestJUnitParser) skippedByPytest(testCase JUnitTestCase) bool {
+	return testCase.Skipped != nil && testCase.Skipped.Type != nil && strings.HasPrefix(*testCase.Skipped.Type, "pytest.")
+}
+
+func (p PythonPytestJUnitParser) newTest(testCase JUnitTestCase) v1.Test {
+	file, classes := p.splitClassName(testCase)
+
+	lineage := make([]string, 0, len(classes)+1)
+	lineage = append(lineage, classes...)
+	lineage = append(lineage, testCase.Name)
+	name := strings.Join(lineage, "::")
+	nodeid := file + "::" + name
+
+	var location *v1.Location
+	switch {
+	case testCase.Line != nil:
+		location = &v1.Location{File: file, Line: testCase.Line}
+	case testCase.Lineno != nil:
+		location = &v1.Location{File: file, Line: testCase.Lineno}
+	default:
+		location = &v1.Location{File: file}
+	}
+
+	duration := time.Duration(math.Round(testCase.Time * float64(time.Second)))
+
+	var status v1.TestStatus
+	switch {
+	case testCase.Failure != nil:
+		status = JUnitTestsuitesParser{}.NewFailedTestStatus(*testCase.Failure)
+	case testCase.Error != nil:
+		status = JUnitTestsuitesParser{}.NewFailedTestStatus(*testCase.Error)
+	case testCase.Skipped != nil:
+		status = v1.NewSkippedTestStatus(testCase.Skipped.Message)
+	default:
+		status = v1.NewSuccessfulTestStatus()
+	}
+
+	// Properties recorded with `record_property` end up on the test case, just like the user properties of reportlog
+	userProperties := map[string]any{}
+	if testCase.Properties != nil {
+		for _, property := range testCase.Properties.Properties {
+			userProperties[property.Name] = property.Value
+		}
+	}
+
+	return v1.Test{
+		ID:       &nodeid,
+		Name:     name,
+		Lineage:  lineage,
+		Location: location,
+		Attempt: v1.TestAttempt{
+			Duration: &duration,
+			Meta:     map[string]any{"user_properties": userProperties},
+			Status:   status,
+			Stderr:   testCase.SystemErr,
+			Stdout:   testCase.SystemOut,
+		},
+	}
+}
+
+// splitClassName reverses how pytest derives the classname from the node ID: the path of the module with dots
+// instead of slashes and without `.py`, followed by the classes the test is nested in. The `file` attribute is only
+// written with `junit_family=xunit1`; otherwise the classes are told apart from the modules by their capitalization.
+func (p PythonPytestJUnitParser) splitClassName(testCase JUnitTestCase) (string, []string) {
+	segments := strings.Split(testCase.ClassName, ".")
+	if testCase.ClassName == "" {
+		segments = nil
+	}
+
+	if testCase.File != nil {
+		module := strings.Split(strings.TrimSuffix(*testCase.File, ".py"), "/")
+		if len(module) <= len(segments) && strings.Join(segments[:len(module)], ".") == strings.Join(module, ".") {
+			return *testCase.File, segments[len(module):]
+		}
+
+		return *testCase.File, segments
+	}
+
+	moduleLength := len(segments)
+	for i, segment := range segments {
+		if segment != "" && unicode.IsUpper([]rune(segment)[0]) {
+			moduleLength = i
+			break
+		}
+	}
+
+	return strings.Join(segments[:moduleLength], "/") + ".py", segments[moduleLength:]
+}
@@ -0,0 +1,145 @@
+package parsing_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PythonPytestJUnitParser", func() {
+	Describe("Parse", func() {
+		It("parses the sample file", func() {
+			fixture, err := os.Open("../../test/fixtures/pytest.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.PythonPytestJUnitParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			rwxJSON, err := json.MarshalIndent(testResults, "", "  ")
+			Expect(err).ToNot(HaveOccurred())
+			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
+		})
+
+		It("derives the node IDs from the classnames", func() {
+			fixture, err := os.Open("../../test/fixtures/pytest.xml")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.PythonPytestJUnitParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Framework).To(Equal(v1.PythonPytestFramework))
+			Expect(testResults.Summary.Tests).To(Equal(7))
+			Expect(testResults.Summary.Successful).To(Equal(3))
+			Expect(testResults.Summary.Failed).To(Equal(2))
+			Expect(testResults.Summary.Skipped).To(Equal(2))
+
+			ids := make([]string, 0)
+			for _, test := range testResults.Tests {
+				ids = append(ids, *test.ID)
+			}
+			Expect(ids).To(Equal([]string{
+				"test_top_level.py::test_top_level_passing",
+				"test_top_level.py::test_top_level_failing",
+				"test_top_level.py::test_top_level_skipped",
+				"nested/test_nested.py::TestNested::test_parametrized[1-2]",
+				"nested/test_nested.py::TestNested::TestDeeper::test_deeper",
+				"nested/test_nested.py::test_xfail",
+				"nested/test_nested.py::test_with_fixture",
+			}))
+
+			test := testResults.Tests[4]
+			Expect(test.Name).To(Equal("TestNested::TestDeeper::test_deeper"))
+			Expect(test.Lineage).To(Equal([]string{"TestNested", "TestDeeper", "test_deeper"}))
+			Expect(test.Location.String()).To(Equal("nested/test_nested.py:20"))
+
+			Expect(testResults.Tests[0].Attempt.Meta).To(Equal(
+				map[string]any{"user_properties": map[string]any{"owner": "team-a"}},
+			))
+		})
+
+		It("derives the file from the classname without a file attribute", func() {
+			testResults, err := parsing.PythonPytestJUnitParser{}.Parse(strings.NewReader(
+				`
+					<testsuites>
+						<testsuite name="pytest" tests="2">
+							<testcase classname="tests.api.test_users.TestCreate" name="test_valid" time="0.1" />
+							<testcase classname="tests.api.test_users" name="test_list" time="0.1" />
+						</testsuite>
+					</testsuites>
+				`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*testResults.Tests[0].ID).To(Equal("tests/api/test_users.py::TestCreate::test_valid"))
+			Expect(*testResults.Tests[1].ID).To(Equal("tests/api/test_users.py::test_list"))
+		})
+
+		It("recognises pytest by its skip markers when the suite is renamed", func() {
+			testResults, err := parsing.PythonPytestJUnitParser{}.Parse(strings.NewReader(
+				`
+					<testsuite name="api" tests="1">
+						<testcase classname="test_api" name="test_skipped" time="0">
+							<skipped type="pytest.skip" message="flaky upstream" />
+						</testcase>
+					</testsuite>
+				`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(1))
+			Expect(*testResults.Tests[0].Attempt.Status.Message).To(Equal("flaky upstream"))
+		})
+
+		It("accepts any JUnit XML when told the results come from pytest", func() {
+			input := `
+				<testsuites>
+					<testsuite name="api" tests="1">
+						<testcase classname="test_api" name="test_passing" time="0" />
+					</testsuite>
+				</testsuites>
+			`
+
+			testResults, err := parsing.PythonPytestJUnitParser{}.Parse(strings.NewReader(input))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("do not appear to be written by pytest"))
+			Expect(testResults).To(BeNil())
+
+			testResults, err = parsing.PythonPytestJUnitParser{AssumePytest: true}.Parse(strings.NewReader(input))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*testResults.Tests[0].ID).To(Equal("test_api.py::test_passing"))
+		})
+
+		It("errors on files that don't look like pytest", func() {
+			for _, path := range []string{
+				"../../test/fixtures/cypress.xml",
+				"../../test/fixtures/junit.xml",
+				"../../test/fixtures/surefire.xml",
+				"../../test/fixtures/unittest.xml",
+				"../../test/fixtures/pytest_reportlog.jsonl",
+			} {
+				fixture, err := os.Open(path)
+				Expect(err).ToNot(HaveOccurred())
+
+				testResults, err := parsing.PythonPytestJUnitParser{}.Parse(fixture)
+				Expect(err).To(HaveOccurred(), path)
+				Expect(testResults).To(BeNil())
+			}
+		})
+	})
+
+	Describe("Sniff", func() {
+		It("only accepts XML that mentions pytest", func() {
+			Expect(parsing.PythonPytestJUnitParser{}.Sniff([]byte(`<testsuites><testsuite name="pytest">`))).To(BeTrue())
+			Expect(parsing.PythonPytestJUnitParser{}.Sniff([]byte(`<testsuites><testsuite name="api">`))).To(BeFalse())
+			Expect(parsing.PythonPytestJUnitParser{AssumePytest: true}.Sniff(
+				[]byte(`<testsuites><testsuite name="api">`),
+			)).To(BeTrue())
+			Expect(parsing.PythonPytestJUnitParser{}.Sniff([]byte(`{"pytest_version": "7.2.0"}`))).To(BeFalse())
+		})
+	})
+})
@@ -35,7 +35,7 @@ type PythonPytestTestResult struct {
 	Nodeid         string          `json:"nodeid"`
 	Location       []any           `json:"location"` // ["test_top_level.py", 0, "test_top_level_passing"],
 	Keywords       map[string]int  `json:"keywords"`
-	Outcome        string          `json:"outcome"` // failed, passed, skipped
+	Outcome        string          `json:"outcome"` // failed, passed, skipped, rerun
 	Longrepr       json.RawMessage `json:"longrepr"`
 	When           string          `json:"when"`
 	UserProperties [][]any         `json:"user_properties"`
@@ -50,6 +50,10 @@ type PythonPytestTestResult struct {
 	Node       string `json:"node"`
 }
 
+func (p PythonPytestParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
 func (p PythonPytestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	decoder := json.NewDecoder(data)
 
@@ -96,7 +100,15 @@ func (p PythonPytestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 		}
 
 		test, ok := testsByNodeid[testResult.Nodeid]
-		if ok {
+		switch {
+		case ok && test.Attempt.Duration == nil:
+			// pytest-rerunfailures doesn't report the teardown of a rerun attempt, so the next report starts a new one
+			test.Attempt = v1.TestAttempt{
+				Duration: &duration,
+				Status:   *status,
+				Meta:     map[string]any{"user_properties": userProperties},
+			}
+		case ok:
 			newDuration := *test.Attempt.Duration + duration
 			test.Attempt.Duration = &newDuration
 			test.Attempt.Meta = map[string]any{"user_properties": userProperties}
@@ -113,7 +125,7 @@ func (p PythonPytestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 			}
 
 			test.Attempt.Status = *status
-		} else {
+		default:
 			name := strings.TrimPrefix(testResult.Nodeid, fmt.Sprintf("%v::", location.File))
 			test = v1.Test{
 				ID:       &testResult.Nodeid,
@@ -127,12 +139,24 @@ func (p PythonPytestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 				},
 			}
 		}
+
+		if testResult.Outcome == "rerun" {
+			test.PastAttempts = append(test.PastAttempts, test.Attempt)
+			test.Attempt = v1.TestAttempt{}
+		}
+
 		testsByNodeid[testResult.Nodeid] = test
 	}
 
 	tests := make([]v1.Test, len(testsByNodeid))
 	i := 0
 	for _, test := range testsByNodeid {
+		// The log ended right after a rerun, so the last attempt we know of is the one that was rerun
+		if test.Attempt.Duration == nil && len(test.PastAttempts) > 0 {
+			test.Attempt = test.PastAttempts[len(test.PastAttempts)-1]
+			test.PastAttempts = test.PastAttempts[:len(test.PastAttempts)-1]
+		}
+
 		tests[i] = test
 		i++
 	}
@@ -150,7 +174,8 @@ func (p PythonPytestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 func (p PythonPytestParser) statusOf(testResult PythonPytestTestResult) (*v1.TestStatus, error) {
 	var status v1.TestStatus
 	switch testResult.Outcome {
-	case "failed":
+	// pytest-rerunfailures reports failed attempts that are going to be retried as reruns
+	case "failed", "rerun":
 		_, wasxfail := testResult.Keywords["xfail"]
 
 		if wasxfail {
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/bradleyjkemp/cupaloy"
 
@@ -28,6 +29,51 @@ var _ = Describe("PythonPytestParser", func() {
 			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
 		})
 
+		It("maps reruns by pytest-rerunfailures to past attempts", func() {
+			report := func(outcome string, when string, longrepr string) string {
+				return `{"nodeid": "test_flaky.py::test_flaky", "location": ["test_flaky.py", 3, "test_flaky"], ` +
+					`"keywords": {}, "outcome": "` + outcome + `", "longrepr": ` + longrepr + `, "when": "` + when +
+					`", "user_properties": [], "sections": [], "duration": 0.5, "$report_type": "TestReport"}` + "\n"
+			}
+			failure := `{"reprcrash": {"path": "test_flaky.py", "lineno": 5, "message": "assert 1 == 2"}}`
+
+			testResults, err := parsing.PythonPytestParser{}.Parse(strings.NewReader(
+				`{"pytest_version": "7.2.0", "$report_type": "SessionStart"}` + "\n" +
+					report("passed", "setup", "null") +
+					report("rerun", "call", failure) +
+					report("passed", "setup", "null") +
+					report("rerun", "call", failure) +
+					report("passed", "setup", "null") +
+					report("passed", "call", "null") +
+					report("passed", "teardown", "null"),
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(1))
+			Expect(testResults.Summary.Retries).To(Equal(1))
+
+			test := testResults.Tests[0]
+			Expect(test.Attempt.Status.Kind).To(Equal(v1.TestStatusSuccessful))
+			Expect(*test.Attempt.Duration).To(Equal(1500 * time.Millisecond))
+			Expect(test.PastAttempts).To(HaveLen(2))
+			Expect(test.PastAttempts[0].Status.Kind).To(Equal(v1.TestStatusFailed))
+			Expect(*test.PastAttempts[0].Status.Message).To(Equal("assert 1 == 2"))
+			Expect(*test.PastAttempts[0].Duration).To(Equal(time.Second))
+			Expect(test.Flaky()).To(BeTrue())
+		})
+
+		It("keeps the last rerun as the attempt when the log ends right after it", func() {
+			testResults, err := parsing.PythonPytestParser{}.Parse(strings.NewReader(
+				`{"pytest_version": "7.2.0", "$report_type": "SessionStart"}
+				{"nodeid": "test_flaky.py::test_flaky", "location": ["test_flaky.py", 3, "test_flaky"], "keywords": {}, ` +
+					`"outcome": "rerun", "longrepr": {"reprcrash": {"path": "test_flaky.py", "lineno": 5, "message": "boom"}}, ` +
+					`"when": "call", "user_properties": [], "sections": [], "duration": 0.5, "$report_type": "TestReport"}`,
+			))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(1))
+			Expect(testResults.Tests[0].Attempt.Status.Kind).To(Equal(v1.TestStatusFailed))
+			Expect(testResults.Tests[0].PastAttempts).To(BeEmpty())
+		})
+
 		It("errors on malformed JSON", func() {
 			testResults, err := parsing.PythonPytestParser{}.Parse(strings.NewReader(`{"pytest_version":`))
 			Expect(err).To(HaveOccurred())
@@ -60,6 +60,10 @@ type PythonUnitTestTestSuite struct {
 
 var PythonUnitTestNewlineRegexp = regexp.MustCompile(`\r?\n`)
 
+func (p PythonUnitTestParser) Sniff(header []byte) bool {
+	return sniffXML(header)
+}
+
 func (p PythonUnitTestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var testResults PythonUnitTestTestSuite
 
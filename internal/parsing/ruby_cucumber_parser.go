@@ -78,6 +78,10 @@ type RubyCucumberResult struct {
 	ErrorMessage *string `json:"error_message"` // this is required if status is failed
 }
 
+func (p RubyCucumberParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
 func (p RubyCucumberParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var cucumberFeatures []RubyCucumberFeature
 
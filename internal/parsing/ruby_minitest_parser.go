@@ -60,6 +60,10 @@ type RubyMinitestTestResults struct {
 
 var rubyMinitestFailureLocationRegexp = regexp.MustCompile(`\n.+\(.+\)\s\[(.+)\]:\n`)
 
+func (p RubyMinitestParser) Sniff(header []byte) bool {
+	return sniffXML(header, "testsuites")
+}
+
 func (p RubyMinitestParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var testResults RubyMinitestTestResults
 
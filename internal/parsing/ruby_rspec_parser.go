@@ -50,6 +50,10 @@ type RubyRSpecTestResults struct {
 
 var fileRegexp = regexp.MustCompile(`\.rb(:.+|\[.+\])$`)
 
+func (p RubyRSpecParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
 func (p RubyRSpecParser) Parse(data io.Reader) (*v1.TestResults, error) {
 	var testResults RubyRSpecTestResults
 
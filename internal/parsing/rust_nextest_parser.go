@@ -0,0 +1,180 @@
+package parsing
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// RustNextestParser parses the libtest JSON output of cargo-nextest (`--message-format libtest-json`) as well as the
+// unstable libtest JSON output of `cargo test -- -Z unstable-options --format json`.
+type RustNextestParser struct{}
+
+// https://nexte.st/docs/machine-readable/libtest-json/
+type RustNextestEvent struct {
+	Type     *string  `json:"type"`  // suite, test, bench
+	Event    *string  `json:"event"` // started, ok, failed, ignored, allowed_fail, timeout
+	Name     *string  `json:"name"`
+	ExecTime *float64 `json:"exec_time"` // in seconds
+	Stdout   *string  `json:"stdout"`    // only set for failed tests
+	Message  *string  `json:"message"`
+}
+
+var (
+	rustNextestPanicRegexp       = regexp.MustCompile(`thread '[^']*' panicked at ([^\n']+:\d+:\d+):\n`)
+	rustNextestLegacyPanicRegexp = regexp.MustCompile(`thread '[^']*' panicked at '((?s:.*?))', ([^\n]+:\d+:\d+)\n`)
+)
+
+func (p RustNextestParser) Sniff(header []byte) bool {
+	return sniffJSONLines(header, func(line []byte) (bool, error) {
+		var event RustNextestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return false, errors.WithStack(err)
+		}
+
+		return event.Type != nil && event.Event != nil, nil
+	})
+}
+
+func (p RustNextestParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	tests := make([]v1.Test, 0)
+	startedNames := make([]string, 0)
+	unfinishedByName := make(map[string]int)
+
+	scanner := bufio.NewScanner(data)
+	// Failed tests include their entire output on a single line
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 64*1024*1024)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(text, "{") {
+			continue
+		}
+
+		var event RustNextestEvent
+		if err := json.Unmarshal([]byte(text), &event); err != nil {
+			continue
+		}
+
+		if event.Type == nil || event.Event == nil {
+			return nil, errors.NewInputError("Test results do not look like libtest JSON")
+		}
+
+		// We don't care about suite-level stats or benchmarks
+		if *event.Type != "test" {
+			continue
+		}
+
+		if event.Name == nil {
+			return nil, errors.NewInputError("JSON with type of test is missing a name: %v", text)
+		}
+
+		var status v1.TestStatus
+		switch *event.Event {
+		case "started":
+			startedNames = append(startedNames, *event.Name)
+			unfinishedByName[*event.Name]++
+			continue
+		case "timeout":
+			// libtest emits this while a test is still running after 60 seconds; it will still report a result
+			continue
+		case "ok", "allowed_fail":
+			status = v1.NewSuccessfulTestStatus()
+		case "failed":
+			status = p.newFailedTestStatus(event)
+		case "ignored":
+			status = v1.NewSkippedTestStatus(event.Message)
+		default:
+			return nil, errors.NewInputError("Unexpected test event: %v", *event.Event)
+		}
+
+		if unfinishedByName[*event.Name] > 0 {
+			unfinishedByName[*event.Name]--
+		}
+		tests = append(tests, p.newTest(event, status))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewInputError("Unable to read test results: %s", err)
+	}
+
+	// Tests that never finish were stopped along with the test runner, e.g. because the run timed out or was interrupted
+	for _, name := range startedNames {
+		if unfinishedByName[name] == 0 {
+			continue
+		}
+
+		unfinishedByName[name]--
+		name := name
+		tests = append(tests, p.newTest(RustNextestEvent{Name: &name}, v1.NewCanceledTestStatus()))
+	}
+
+	if len(tests) == 0 {
+		return nil, errors.NewInputError("Did not see any tests, so we cannot be sure it is libtest JSON")
+	}
+
+	return v1.NewTestResults(
+		v1.RustNextestFramework,
+		tests,
+		nil,
+	), nil
+}
+
+func (p RustNextestParser) newTest(event RustNextestEvent, status v1.TestStatus) v1.Test {
+	test := v1.Test{
+		Name: *event.Name,
+		Attempt: v1.TestAttempt{
+			Status: status,
+			Stdout: event.Stdout,
+		},
+	}
+
+	// nextest prefixes every test name with the ID of the binary it belongs to, separated by a `$`
+	if binaryID, name, found := strings.Cut(*event.Name, "$"); found {
+		test.Scope = &binaryID
+		test.Name = name
+		test.Attempt.Meta = map[string]any{"binary_id": binaryID}
+	}
+	test.Lineage = strings.Split(test.Name, "::")
+
+	if event.ExecTime != nil {
+		duration := time.Duration(math.Round(*event.ExecTime * float64(time.Second)))
+		test.Attempt.Duration = &duration
+	}
+
+	return test
+}
+
+func (p RustNextestParser) newFailedTestStatus(event RustNextestEvent) v1.TestStatus {
+	if event.Stdout == nil {
+		return v1.NewFailedTestStatus(event.Message, nil, nil)
+	}
+
+	stdout := *event.Stdout
+
+	// Rust >= 1.73: thread 'name' panicked at src/lib.rs:1:2:\nmessage
+	if match := rustNextestPanicRegexp.FindStringSubmatchIndex(stdout); match != nil {
+		message := stdout[match[1]:]
+		for _, terminator := range []string{"\nnote: ", "\nstack backtrace:", "\n\n"} {
+			if i := strings.Index(message, terminator); i >= 0 {
+				message = message[:i]
+			}
+		}
+		message = strings.TrimSpace(message)
+		return v1.NewFailedTestStatus(&message, nil, []string{stdout[match[2]:match[3]]})
+	}
+
+	// Rust < 1.73: thread 'name' panicked at 'message', src/lib.rs:1:2
+	if match := rustNextestLegacyPanicRegexp.FindStringSubmatch(stdout); match != nil {
+		message := match[1]
+		return v1.NewFailedTestStatus(&message, nil, []string{match[2]})
+	}
+
+	return v1.NewFailedTestStatus(event.Message, nil, nil)
+}
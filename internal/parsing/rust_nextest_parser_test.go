@@ -0,0 +1,133 @@
+package parsing_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RustNextestParser", func() {
+	Describe("Parse", func() {
+		It("parses the sample file", func() {
+			fixture, err := os.Open("../../test/fixtures/nextest.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.RustNextestParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			rwxJSON, err := json.MarshalIndent(testResults, "", "  ")
+			Expect(err).ToNot(HaveOccurred())
+			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
+		})
+
+		It("parses cargo test output", func() {
+			fixture, err := os.Open("../../test/fixtures/cargo_test.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.RustNextestParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Framework).To(Equal(v1.RustNextestFramework))
+			Expect(testResults.Summary.Tests).To(Equal(3))
+			Expect(testResults.Summary.Successful).To(Equal(1))
+			Expect(testResults.Summary.Failed).To(Equal(1))
+			Expect(testResults.Summary.Skipped).To(Equal(1))
+
+			failedTest := testResults.Tests[2]
+			Expect(failedTest.Name).To(Equal("tests::it_fails"))
+			Expect(failedTest.Scope).To(BeNil())
+			Expect(failedTest.Lineage).To(Equal([]string{"tests", "it_fails"}))
+			Expect(*failedTest.Attempt.Status.Message).To(Equal("explicit panic"))
+			Expect(failedTest.Attempt.Status.Backtrace).To(Equal([]string{"src/lib.rs:18:9"}))
+		})
+
+		It("sets the binary ID as the scope", func() {
+			fixture, err := os.Open("../../test/fixtures/nextest.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.RustNextestParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, test := range testResults.Tests {
+				Expect(*test.Scope).To(SatisfyAny(Equal("captain-rust"), Equal("captain-rust::integration")))
+				Expect(test.Attempt.Meta["binary_id"]).To(Equal(*test.Scope))
+			}
+		})
+
+		It("extracts panic messages in the legacy format", func() {
+			testResults, err := parsing.RustNextestParser{}.Parse(strings.NewReader(
+				`{"type":"test","event":"failed","name":"it_fails","stdout":"thread 'it_fails' panicked at ` +
+					`'oh, no', src/lib.rs:3:5\nnote: run with ` + "`RUST_BACKTRACE=1`" + `\n"}`,
+			))
+			Expect(err).ToNot(HaveOccurred())
+
+			status := testResults.Tests[0].Attempt.Status
+			Expect(status.Kind).To(Equal(v1.TestStatusFailed))
+			Expect(*status.Message).To(Equal("oh, no"))
+			Expect(status.Backtrace).To(Equal([]string{"src/lib.rs:3:5"}))
+		})
+
+		It("marks tests which never finished as canceled", func() {
+			testResults, err := parsing.RustNextestParser{}.Parse(strings.NewReader(strings.Join([]string{
+				`{"type":"suite","event":"started","test_count":2}`,
+				`{"type":"test","event":"started","name":"captain-rust$tests::it_passes"}`,
+				`{"type":"test","event":"started","name":"captain-rust$tests::it_hangs"}`,
+				`{"type":"test","event":"ok","name":"captain-rust$tests::it_passes","exec_time":0.001}`,
+			}, "\n")))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(2))
+			Expect(testResults.Tests[0].Name).To(Equal("tests::it_passes"))
+			Expect(testResults.Tests[0].Attempt.Status.Kind).To(Equal(v1.TestStatusSuccessful))
+			Expect(testResults.Tests[1].Name).To(Equal("tests::it_hangs"))
+			Expect(*testResults.Tests[1].Scope).To(Equal("captain-rust"))
+			Expect(testResults.Tests[1].Attempt.Status.Kind).To(Equal(v1.TestStatusCanceled))
+		})
+
+		It("errors on malformed JSON with no remnants of libtest JSON", func() {
+			testResults, err := parsing.RustNextestParser{}.Parse(strings.NewReader(`asdfasdfsdf`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(
+				"Did not see any tests, so we cannot be sure it is libtest JSON",
+			))
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors on JSON that doesn't look like libtest JSON", func() {
+			var testResults *v1.TestResults
+			var err error
+
+			testResults, err = parsing.RustNextestParser{}.Parse(strings.NewReader(`{}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Test results do not look like libtest JSON"))
+			Expect(testResults).To(BeNil())
+
+			fixture, err := os.Open("../../test/fixtures/go_test.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err = parsing.RustNextestParser{}.Parse(fixture)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Test results do not look like libtest JSON"))
+			Expect(testResults).To(BeNil())
+		})
+	})
+
+	Describe("Sniff", func() {
+		It("looks at the first JSON event of the header", func() {
+			fixture, err := os.ReadFile("../../test/fixtures/nextest.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsing.RustNextestParser{}.Sniff(fixture)).To(BeTrue())
+
+			Expect(parsing.RustNextestParser{}.Sniff([]byte("   Compiling foo\n{\"type\":\"suite\",\"event\":\"started\"}"))).
+				To(BeTrue())
+			Expect(parsing.RustNextestParser{}.Sniff([]byte(`{"examples": [], "summary": {}}`))).To(BeFalse())
+			Expect(parsing.RustNextestParser{}.Sniff([]byte("<testsuites></testsuites>"))).To(BeFalse())
+		})
+	})
+})
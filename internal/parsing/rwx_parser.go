@@ -1,21 +1,191 @@
 package parsing
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"io"
+	"unicode"
 
 	"github.com/rwx-research/captain-cli/internal/errors"
 	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+	v2 "github.com/rwx-research/captain-cli/internal/testingschema/v2"
 )
 
 type RWXParser struct{}
 
+func (p RWXParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
+// Parse reads the RWX test results of a single framework.
 func (p RWXParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	allTestResults, err := p.ParseAll(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(allTestResults) != 1 {
+		return nil, errors.NewInputError(
+			"Expected the test results of a single framework, found %d",
+			len(allTestResults),
+		)
+	}
+
+	return &allTestResults[0], nil
+}
+
+// ParseAll reads both versions of the RWX test results schema. V2 results are converted to V1, which keeps the data
+// that V1 has no place for in the meta of the test attempts. The results of several frameworks are expected as an
+// array of documents, which is how captain reports them. The version is read from the `$schema` of the first document,
+// so that the results can be decoded in a single pass; all documents of an array are expected to share it.
+func (p RWXParser) ParseAll(data io.Reader) ([]v1.TestResults, error) {
+	reader := bufio.NewReaderSize(data, sniffLength)
+	header, _ := reader.Peek(sniffLength)
+
+	schema, ok := schemaOf(header)
+	if !ok {
+		return p.parseRawDocuments(reader)
+	}
+
+	decoder := json.NewDecoder(reader)
+	if !bytes.HasPrefix(bytes.TrimLeftFunc(header, unicode.IsSpace), []byte("[")) {
+		testResults, err := decodeDocument(decoder, schema)
+		if err != nil {
+			return nil, err
+		}
+
+		return []v1.TestResults{testResults}, nil
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, errors.NewInputError("Unable to parse test results as JSON: %s", err)
+	}
+
+	allTestResults := make([]v1.TestResults, 0)
+	for decoder.More() {
+		testResults, err := decodeDocument(decoder, schema)
+		if err != nil {
+			return nil, err
+		}
+
+		allTestResults = append(allTestResults, testResults)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, errors.NewInputError("Unable to parse test results as JSON: %s", err)
+	}
+
+	if len(allTestResults) == 0 {
+		return nil, errors.NewInputError("Unable to parse test results as JSON: the array is empty")
+	}
+
+	return allTestResults, nil
+}
+
+// schemaOf looks up the `$schema` of the first document in the header without decoding the rest of it. It isn't found
+// if the header is cut off before the end of the property, in which case the schema is unknown.
+func schemaOf(header []byte) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(header))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return "", false
+	}
+
+	if token == json.Delim('[') {
+		if token, err = decoder.Token(); err != nil {
+			return "", false
+		}
+	}
+
+	if token != json.Delim('{') {
+		return "", false
+	}
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return "", false
+		}
+
+		if key == "$schema" {
+			value, err := decoder.Token()
+			if err != nil {
+				return "", false
+			}
+
+			schema, ok := value.(string)
+			return schema, ok
+		}
+
+		var skipped json.RawMessage
+		if err := decoder.Decode(&skipped); err != nil {
+			return "", false
+		}
+	}
+
+	// The document ended without a `$schema`, which the V1 decoding reports on
+	return "", true
+}
+
+func decodeDocument(decoder *json.Decoder, schema string) (v1.TestResults, error) {
+	if schema == v2.SchemaURL {
+		var testResults v2.TestResults
+		if err := decoder.Decode(&testResults); err != nil {
+			return v1.TestResults{}, errors.NewInputError("Unable to parse test results as JSON: %s", err)
+		}
+
+		return testResults.ToV1(), nil
+	}
+
 	var testResults v1.TestResults
+	if err := decoder.Decode(&testResults); err != nil {
+		return v1.TestResults{}, errors.NewInputError("Unable to parse test results as JSON: %s", err)
+	}
+
+	return testResults, nil
+}
 
-	if err := json.NewDecoder(data).Decode(&testResults); err != nil {
+// parseRawDocuments is used when the schema can't be told from the header. Each document is buffered so that its
+// `$schema` can be read before decoding it.
+func (p RWXParser) parseRawDocuments(data io.Reader) ([]v1.TestResults, error) {
+	var document json.RawMessage
+	if err := json.NewDecoder(data).Decode(&document); err != nil {
 		return nil, errors.NewInputError("Unable to parse test results as JSON: %s", err)
 	}
 
-	return &testResults, nil
+	documents := []json.RawMessage{document}
+	if bytes.HasPrefix(document, []byte("[")) {
+		if err := json.Unmarshal(document, &documents); err != nil {
+			return nil, errors.NewInputError("Unable to parse test results as JSON: %s", err)
+		}
+
+		if len(documents) == 0 {
+			return nil, errors.NewInputError("Unable to parse test results as JSON: the array is empty")
+		}
+	}
+
+	allTestResults := make([]v1.TestResults, 0, len(documents))
+	for _, document := range documents {
+		testResults, err := p.parseRawDocument(document)
+		if err != nil {
+			return nil, err
+		}
+
+		allTestResults = append(allTestResults, testResults)
+	}
+
+	return allTestResults, nil
+}
+
+func (p RWXParser) parseRawDocument(document json.RawMessage) (v1.TestResults, error) {
+	var schema struct {
+		Schema string `json:"$schema"`
+	}
+	if err := json.Unmarshal(document, &schema); err != nil {
+		return v1.TestResults{}, errors.NewInputError("Unable to parse test results as JSON: %s", err)
+	}
+
+	return decodeDocument(json.NewDecoder(bytes.NewReader(document)), schema.Schema)
 }
@@ -2,6 +2,7 @@ package parsing_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"strings"
 
@@ -9,6 +10,7 @@ import (
 
 	"github.com/rwx-research/captain-cli/internal/parsing"
 	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+	v2 "github.com/rwx-research/captain-cli/internal/testingschema/v2"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -27,6 +29,32 @@ var _ = Describe("RWXParser", func() {
 			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
 		})
 
+		It("parses RWX v2 JSON into v1 test results", func() {
+			fixture, err := os.Open("../../test/fixtures/rwx/v2.json")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.RWXParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Framework).To(Equal(v1.JavaScriptPlaywrightFramework))
+			Expect(testResults.Summary.Retries).To(Equal(1))
+			Expect(testResults.Tests).To(HaveLen(2))
+			Expect(testResults.Tests[0].Attempt.Meta).To(HaveKeyWithValue("tags", []string{"@smoke", "@auth"}))
+			Expect(testResults.Tests[0].Attempt.Meta).To(HaveKey("steps"))
+			Expect(testResults.Tests[0].PastAttempts[0].Meta).To(HaveKey("attachments"))
+			Expect(testResults.Tests[1].Attempt.Meta).To(HaveKeyWithValue("tags", "not a list"))
+		})
+
+		It("parses results whose schema comes after the part of the file that is sniffed", func() {
+			padding := strings.Repeat("x", 128*1024)
+			testResults, err := parsing.RWXParser{}.Parse(strings.NewReader(fmt.Sprintf(
+				`{"padding": %q, "framework": {"language": "JavaScript", "kind": "Playwright"}, "$schema": %q}`,
+				padding,
+				v2.SchemaURL,
+			)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Framework).To(Equal(v1.JavaScriptPlaywrightFramework))
+		})
+
 		It("errors on malformed JSON", func() {
 			testResults, err := parsing.RWXParser{}.Parse(strings.NewReader(`{"summary":`))
 			Expect(err).To(HaveOccurred())
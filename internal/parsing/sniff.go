@@ -0,0 +1,79 @@
+package parsing
+
+import (
+	"bytes"
+	"encoding/xml"
+	"unicode"
+)
+
+// The number of bytes at the start of a file that parsers get to look at before the file is parsed in full
+const sniffLength = 64 * 1024
+
+// Sniffer is implemented by parsers that can rule out a file by only looking at its first few kilobytes. Parsers that
+// don't implement it are always given the full file.
+type Sniffer interface {
+	Sniff(header []byte) bool
+}
+
+var utf8ByteOrderMark = []byte{0xEF, 0xBB, 0xBF}
+
+// sniffJSON reports whether the header starts like a JSON document (or a stream of JSON documents)
+func sniffJSON(header []byte) bool {
+	header = bytes.TrimLeftFunc(bytes.TrimPrefix(header, utf8ByteOrderMark), unicode.IsSpace)
+	return len(header) > 0 && (header[0] == '{' || header[0] == '[')
+}
+
+// sniffJSONLines hands the first line of the header that holds a JSON object to `accept`, which reports whether it's
+// the start of a stream of JSON events. `accept` can return an error to skip a line, just like the parsers do. Headers
+// without such a line are only ruled out when they hold the entire file, since the events might start further down.
+func sniffJSONLines(header []byte, accept func(line []byte) (bool, error)) bool {
+	rest := header
+	for len(rest) > 0 {
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+
+		line = bytes.TrimSpace(line)
+		if !bytes.HasPrefix(line, []byte("{")) {
+			continue
+		}
+
+		accepted, err := accept(line)
+		if err != nil {
+			continue
+		}
+
+		return accepted
+	}
+
+	return len(header) >= sniffLength
+}
+
+// sniffXML reports whether the header starts like an XML document with one of the given root elements. Any root
+// element is accepted when none are given.
+func sniffXML(header []byte, rootElements ...string) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(header))
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if len(rootElements) == 0 {
+			return true
+		}
+
+		for _, rootElement := range rootElements {
+			if element.Name.Local == rootElement {
+				return true
+			}
+		}
+
+		return false
+	}
+}
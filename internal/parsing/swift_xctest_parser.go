@@ -0,0 +1,235 @@
+package parsing
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// Parses the test summaries exported with `xcrun xcresulttool get --legacy --format json --id <testsRef>`
+// https://developer.apple.com/documentation/xcode/xcresulttool
+type SwiftXCTestParser struct{}
+
+type SwiftXCTestType struct {
+	Name string `json:"_name"`
+}
+
+// xcresulttool wraps every primitive in an object describing its type
+type SwiftXCTestValue struct {
+	Value string `json:"_value"`
+}
+
+type SwiftXCTestFailureSummary struct {
+	FileName   *SwiftXCTestValue `json:"fileName"`
+	LineNumber *SwiftXCTestValue `json:"lineNumber"`
+	Message    *SwiftXCTestValue `json:"message"`
+}
+
+type SwiftXCTestFailureSummaries struct {
+	Values []SwiftXCTestFailureSummary `json:"_values"`
+}
+
+type SwiftXCTestNode struct {
+	Type              SwiftXCTestType             `json:"_type"`
+	Duration          *SwiftXCTestValue           `json:"duration"`
+	FailureSummaries  SwiftXCTestFailureSummaries `json:"failureSummaries"`
+	Identifier        *SwiftXCTestValue           `json:"identifier"`
+	Name              *SwiftXCTestValue           `json:"name"`
+	SkipNoticeSummary *SwiftXCTestFailureSummary  `json:"skipNoticeSummary"`
+	Subtests          SwiftXCTestNodes            `json:"subtests"`
+	TestStatus        *SwiftXCTestValue           `json:"testStatus"`
+}
+
+type SwiftXCTestNodes struct {
+	Values []SwiftXCTestNode `json:"_values"`
+}
+
+type SwiftXCTestTestableSummary struct {
+	Name       *SwiftXCTestValue `json:"name"`
+	TargetName *SwiftXCTestValue `json:"targetName"`
+	Tests      SwiftXCTestNodes  `json:"tests"`
+}
+
+type SwiftXCTestRunSummary struct {
+	Name              *SwiftXCTestValue `json:"name"`
+	TestableSummaries struct {
+		Values []SwiftXCTestTestableSummary `json:"_values"`
+	} `json:"testableSummaries"`
+}
+
+type SwiftXCTestResults struct {
+	Type      SwiftXCTestType `json:"_type"`
+	Summaries struct {
+		Values []SwiftXCTestRunSummary `json:"_values"`
+	} `json:"summaries"`
+}
+
+func (p SwiftXCTestParser) Sniff(header []byte) bool {
+	return sniffJSON(header)
+}
+
+func (p SwiftXCTestParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	var testResults SwiftXCTestResults
+
+	if err := json.NewDecoder(data).Decode(&testResults); err != nil {
+		return nil, errors.NewInputError("Unable to parse test results as JSON: %s", err)
+	}
+
+	if testResults.Type.Name != "ActionTestPlanRunSummaries" {
+		return nil, errors.NewInputError("The JSON does not look like xcresulttool test summaries")
+	}
+
+	tests := make([]v1.Test, 0)
+	for _, runSummary := range testResults.Summaries.Values {
+		for _, testableSummary := range runSummary.TestableSummaries.Values {
+			target := p.value(testableSummary.TargetName)
+			if target == "" {
+				target = p.value(testableSummary.Name)
+			}
+
+			for _, node := range testableSummary.Tests.Values {
+				testableTests, err := p.testsFor(target, node)
+				if err != nil {
+					return nil, err
+				}
+
+				tests = append(tests, testableTests...)
+			}
+		}
+	}
+
+	return v1.NewTestResults(
+		v1.SwiftXCTestFramework,
+		tests,
+		nil,
+	), nil
+}
+
+// Test summary groups nest the test bundle, the test class and finally the test methods
+func (p SwiftXCTestParser) testsFor(target string, node SwiftXCTestNode) ([]v1.Test, error) {
+	if node.Type.Name == "ActionTestSummaryGroup" {
+		tests := make([]v1.Test, 0)
+		for _, subtest := range node.Subtests.Values {
+			subtests, err := p.testsFor(target, subtest)
+			if err != nil {
+				return nil, err
+			}
+
+			tests = append(tests, subtests...)
+		}
+
+		return tests, nil
+	}
+
+	if node.TestStatus == nil || node.Identifier == nil {
+		return nil, errors.NewInputError("Unexpected test summary of type %q", node.Type.Name)
+	}
+
+	test, err := p.newTest(target, node)
+	if err != nil {
+		return nil, err
+	}
+
+	return []v1.Test{test}, nil
+}
+
+func (p SwiftXCTestParser) newTest(target string, node SwiftXCTestNode) (v1.Test, error) {
+	var duration *time.Duration
+	if node.Duration != nil {
+		seconds, err := strconv.ParseFloat(node.Duration.Value, 64)
+		if err != nil {
+			return v1.Test{}, errors.NewInputError("Unable to parse test duration %q: %s", node.Duration.Value, err)
+		}
+		parsedDuration := time.Duration(math.Round(seconds * float64(time.Second)))
+		duration = &parsedDuration
+	}
+
+	var location *v1.Location
+	var status v1.TestStatus
+	switch node.TestStatus.Value {
+	case "Success", "Expected Failure":
+		status = v1.NewSuccessfulTestStatus()
+	case "Failure":
+		messages := make([]string, 0)
+		backtrace := make([]string, 0)
+		for _, failureSummary := range node.FailureSummaries.Values {
+			if message := p.value(failureSummary.Message); message != "" {
+				messages = append(messages, message)
+			}
+
+			if failureLocation := p.location(failureSummary); failureLocation != nil {
+				backtrace = append(backtrace, failureLocation.String())
+				if location == nil {
+					location = failureLocation
+				}
+			}
+		}
+
+		var message *string
+		if len(messages) > 0 {
+			joinedMessages := strings.Join(messages, "\n\n")
+			message = &joinedMessages
+		}
+		if len(backtrace) == 0 {
+			backtrace = nil
+		}
+
+		status = v1.NewFailedTestStatus(message, nil, backtrace)
+	case "Skipped":
+		var message *string
+		if node.SkipNoticeSummary != nil {
+			if skipMessage := p.value(node.SkipNoticeSummary.Message); skipMessage != "" {
+				message = &skipMessage
+			}
+			location = p.location(*node.SkipNoticeSummary)
+		}
+
+		status = v1.NewSkippedTestStatus(message)
+	default:
+		return v1.Test{}, errors.NewInputError("Unexpected test status %q", node.TestStatus.Value)
+	}
+
+	// Identifiers look like `CalculatorTests/testAddition()`
+	identifier := node.Identifier.Value
+	lineage := append([]string{target}, strings.Split(identifier, "/")...)
+
+	return v1.Test{
+		Scope:    &target,
+		Name:     identifier,
+		Lineage:  lineage,
+		Location: location,
+		Attempt: v1.TestAttempt{
+			Duration: duration,
+			Meta:     map[string]any{"target": target, "identifier": identifier},
+			Status:   status,
+		},
+	}, nil
+}
+
+func (p SwiftXCTestParser) location(summary SwiftXCTestFailureSummary) *v1.Location {
+	file := p.value(summary.FileName)
+	if file == "" {
+		return nil
+	}
+
+	location := v1.Location{File: file}
+	if line, err := strconv.Atoi(p.value(summary.LineNumber)); err == nil {
+		location.Line = &line
+	}
+
+	return &location
+}
+
+func (p SwiftXCTestParser) value(value *SwiftXCTestValue) string {
+	if value == nil {
+		return ""
+	}
+
+	return value.Value
+}
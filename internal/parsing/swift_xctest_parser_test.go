@@ -0,0 +1,92 @@
+package parsing_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SwiftXCTestParser", func() {
+	Describe("Parse", func() {
+		It("parses the sample file", func() {
+			fixture, err := os.Open("../../test/fixtures/xcresult.json")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.SwiftXCTestParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			rwxJSON, err := json.MarshalIndent(testResults, "", "  ")
+			Expect(err).ToNot(HaveOccurred())
+			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
+		})
+
+		It("maps the failure summaries to the location and lineage", func() {
+			fixture, err := os.Open("../../test/fixtures/xcresult.json")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.SwiftXCTestParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Framework).To(Equal(v1.SwiftXCTestFramework))
+			Expect(testResults.Summary.Tests).To(Equal(7))
+			Expect(testResults.Summary.Successful).To(Equal(4))
+			Expect(testResults.Summary.Failed).To(Equal(2))
+			Expect(testResults.Summary.Skipped).To(Equal(1))
+
+			failedTest := testResults.Tests[1]
+			Expect(failedTest.Name).To(Equal("CalculatorTests/testSubtraction()"))
+			Expect(*failedTest.Scope).To(Equal("CalculatorTests"))
+			Expect(failedTest.Lineage).To(Equal([]string{"CalculatorTests", "CalculatorTests", "testSubtraction()"}))
+			Expect(failedTest.Location.File).To(Equal(
+				"/Users/runner/work/calculator/CalculatorTests/CalculatorTests.swift",
+			))
+			Expect(*failedTest.Location.Line).To(Equal(21))
+			Expect(failedTest.Attempt.Status.Backtrace).To(HaveLen(2))
+		})
+
+		It("errors on malformed JSON", func() {
+			testResults, err := parsing.SwiftXCTestParser{}.Parse(strings.NewReader(`{`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unable to parse test results as JSON"))
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors on JSON that doesn't look like xcresulttool test summaries", func() {
+			testResults, err := parsing.SwiftXCTestParser{}.Parse(strings.NewReader(`{}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("The JSON does not look like xcresulttool test summaries"))
+			Expect(testResults).To(BeNil())
+
+			fixture, err := os.Open("../../test/fixtures/jest.json")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err = parsing.SwiftXCTestParser{}.Parse(fixture)
+			Expect(err).To(HaveOccurred())
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors on unknown test statuses", func() {
+			testResults, err := parsing.SwiftXCTestParser{}.Parse(strings.NewReader(`{
+				"_type": {"_name": "ActionTestPlanRunSummaries"},
+				"summaries": {"_values": [{"testableSummaries": {"_values": [{
+					"targetName": {"_value": "AppTests"},
+					"tests": {"_values": [{
+						"_type": {"_name": "ActionTestMetadata"},
+						"identifier": {"_value": "AppTests/testLaunch()"},
+						"testStatus": {"_value": "Exploded"}
+					}]}
+				}]}}]}
+			}`))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`Unexpected test status "Exploded"`))
+			Expect(testResults).To(BeNil())
+		})
+	})
+})
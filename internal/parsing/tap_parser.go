@@ -0,0 +1,457 @@
+package parsing
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// Parses the Test Anything Protocol as emitted by prove, bats, node-tap & friends
+// https://testanything.org/tap-version-13-specification.html
+// https://testanything.org/tap-version-14-specification.html
+type TAPParser struct{}
+
+// Subtests are indented by four spaces relative to their parent
+const tapSubtestIndentation = 4
+
+var (
+	tapVersionRegexp   = regexp.MustCompile(`^TAP version \d+\s*$`)
+	tapPlanRegexp      = regexp.MustCompile(`^1\.\.\d+(\s*#.*)?$`)
+	tapTestPointRegexp = regexp.MustCompile(`^(not )?ok\b\s*(\d+)?\s*(?:-\s*)?(.*?)\s*$`)
+	tapDirectiveRegexp = regexp.MustCompile(`(?i)(?:^|\s)#\s*(skip\S*|todo\b)\s*(.*)$`)
+	tapTimeRegexp      = regexp.MustCompile(`(?:^|\s)#\s*time=(\d+(?:\.\d+)?)(ms|s)?$`)
+	tapSubtestRegexp   = regexp.MustCompile(`^#\s*Subtest(?::\s*(.*))?$`)
+	tapBailOutRegexp   = regexp.MustCompile(`^Bail out!\s*(.*)$`)
+)
+
+// A line of TAP along with the number of spaces it is indented by
+type tapLine struct {
+	indentation int
+	text        string
+}
+
+type tapTestPoint struct {
+	ok          bool
+	number      int
+	description string
+	directive   string
+	reason      string
+	buffered    bool
+	duration    *time.Duration
+	diagnostics map[string]any
+	children    []v1.Test
+}
+
+type tapDocument struct {
+	lines       []tapLine
+	position    int
+	otherErrors []v1.OtherError
+	bailedOut   bool
+}
+
+// Sniff only looks at the first non-empty line, which is all that Parse needs to rule out a file as well
+func (p TAPParser) Sniff(header []byte) bool {
+	header = bytes.TrimPrefix(header, utf8ByteOrderMark)
+
+	for len(header) > 0 {
+		var line []byte
+		line, header, _ = bytes.Cut(header, []byte("\n"))
+
+		if tapLine := newTAPLine(string(line)); tapLine.text != "" {
+			return p.startsLikeTAP(tapLine)
+		}
+	}
+
+	return false
+}
+
+func (p TAPParser) Parse(data io.Reader) (*v1.TestResults, error) {
+	lines := make([]tapLine, 0)
+
+	scanner := bufio.NewScanner(data)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	sawContent := false
+	for scanner.Scan() {
+		line := newTAPLine(scanner.Text())
+
+		// Anything else is rejected as soon as we see it, so that other files aren't read in full
+		if !sawContent && line.text != "" {
+			if !p.startsLikeTAP(line) {
+				return nil, errors.NewInputError("Test results do not look like TAP")
+			}
+			sawContent = true
+		}
+
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewInputError("Unable to read TAP: %s", err)
+	}
+
+	if !sawContent {
+		return nil, errors.NewInputError("Test results do not look like TAP")
+	}
+
+	document := &tapDocument{lines: lines}
+	tests, err := p.parseLevel(document, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tests) == 0 && len(document.otherErrors) == 0 {
+		return nil, errors.NewInputError("Did not see any tests, so we cannot be sure it is TAP")
+	}
+
+	for i := range tests {
+		tests[i].Name = strings.Join(tests[i].Lineage, " ")
+	}
+
+	return v1.NewTestResults(
+		v1.NewOtherFramework(nil, nil),
+		tests,
+		document.otherErrors,
+	), nil
+}
+
+func newTAPLine(line string) tapLine {
+	text := strings.TrimRight(line, " \t\r")
+	trimmed := strings.TrimLeft(text, " ")
+	return tapLine{indentation: len(text) - len(trimmed), text: trimmed}
+}
+
+// TAP is line-based plain text, so we only accept documents whose first line is something only TAP would write
+func (p TAPParser) startsLikeTAP(line tapLine) bool {
+	return line.indentation == 0 && (tapVersionRegexp.MatchString(line.text) ||
+		tapPlanRegexp.MatchString(line.text) ||
+		tapTestPointRegexp.MatchString(line.text) ||
+		tapSubtestRegexp.MatchString(line.text))
+}
+
+// parseLevel consumes lines until one is indented less than the given depth. The returned tests have a lineage
+// relative to the depth, their parents prepend their own description once the enclosing test point is seen
+func (p TAPParser) parseLevel(document *tapDocument, depth int) ([]v1.Test, error) {
+	indentation := depth * tapSubtestIndentation
+	tests := make([]v1.Test, 0)
+
+	var testPoint *tapTestPoint
+	var subtestName string
+	var pendingChildren []v1.Test
+	finishTestPoint := func() {
+		if testPoint != nil {
+			tests = append(tests, p.testsFor(*testPoint)...)
+			testPoint = nil
+		}
+	}
+
+	for document.position < len(document.lines) && !document.bailedOut {
+		line := document.lines[document.position]
+
+		if line.text == "" {
+			document.position++
+			continue
+		}
+
+		if line.indentation < indentation {
+			break
+		}
+
+		// YAML diagnostics are indented by two spaces relative to the test point they belong to
+		if line.indentation >= indentation+2 && line.indentation < indentation+tapSubtestIndentation &&
+			line.text == "---" && testPoint != nil {
+			diagnostics, err := p.parseDiagnostics(document, line.indentation)
+			if err != nil {
+				return nil, err
+			}
+			testPoint.diagnostics = diagnostics
+			continue
+		}
+
+		if line.indentation >= indentation+tapSubtestIndentation {
+			children, err := p.parseLevel(document, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			// Buffered subtests (`ok 1 - name {`) follow their test point, all other subtests precede it
+			if testPoint != nil && testPoint.buffered {
+				testPoint.children = append(testPoint.children, children...)
+			} else {
+				finishTestPoint()
+				pendingChildren = append(pendingChildren, children...)
+			}
+			continue
+		}
+
+		document.position++
+
+		if line.indentation > indentation {
+			continue
+		}
+
+		if match := tapBailOutRegexp.FindStringSubmatch(line.text); match != nil {
+			finishTestPoint()
+			message := "Bail out!"
+			if match[1] != "" {
+				message = fmt.Sprintf("Bail out! %s", match[1])
+			}
+			document.otherErrors = append(document.otherErrors, v1.OtherError{Message: message})
+			document.bailedOut = true
+			break
+		}
+
+		if match := tapSubtestRegexp.FindStringSubmatch(line.text); match != nil {
+			finishTestPoint()
+			subtestName = strings.TrimSpace(match[1])
+			continue
+		}
+
+		if line.text == "}" {
+			continue
+		}
+
+		if match := tapTestPointRegexp.FindStringSubmatch(line.text); match != nil {
+			finishTestPoint()
+
+			newTestPoint := p.parseTestPoint(match)
+			newTestPoint.children = pendingChildren
+			if newTestPoint.description == "" {
+				newTestPoint.description = subtestName
+			}
+			if newTestPoint.description == "" {
+				newTestPoint.description = strconv.Itoa(newTestPoint.number)
+			}
+
+			testPoint = &newTestPoint
+			subtestName = ""
+			pendingChildren = nil
+			continue
+		}
+
+		// Comments, plans, pragmas and anything unknown are ignored as per the specification
+	}
+
+	finishTestPoint()
+
+	// Subtests without a closing test point (e.g. due to a bail out) are still reported
+	for _, child := range pendingChildren {
+		if subtestName != "" {
+			child.Lineage = append([]string{subtestName}, child.Lineage...)
+		}
+		tests = append(tests, child)
+	}
+
+	return tests, nil
+}
+
+func (p TAPParser) parseTestPoint(match []string) tapTestPoint {
+	testPoint := tapTestPoint{ok: match[1] == ""}
+
+	if match[2] != "" {
+		number, err := strconv.Atoi(match[2])
+		if err == nil {
+			testPoint.number = number
+		}
+	}
+
+	description := match[3]
+
+	// node-tap appends the duration of every test point, e.g. `ok 1 - adds numbers # time=1.234ms`
+	if match := tapTimeRegexp.FindStringSubmatch(description); match != nil {
+		testPoint.duration = p.parseTime(match[1], match[2])
+		description = strings.TrimSuffix(description, match[0])
+	}
+
+	if directive := tapDirectiveRegexp.FindStringSubmatchIndex(description); directive != nil {
+		testPoint.directive = strings.ToLower(description[directive[2]:directive[3]])
+		testPoint.reason = strings.TrimSpace(description[directive[4]:directive[5]])
+		description = description[:directive[0]]
+	}
+
+	description = strings.TrimSpace(description)
+	if strings.HasSuffix(description, "{") {
+		testPoint.buffered = true
+		description = strings.TrimSpace(strings.TrimSuffix(description, "{"))
+	}
+	testPoint.description = strings.ReplaceAll(description, `\#`, "#")
+
+	return testPoint
+}
+
+func (p TAPParser) parseDiagnostics(document *tapDocument, indentation int) (map[string]any, error) {
+	start := document.lines[document.position]
+	document.position++
+
+	var builder strings.Builder
+	for document.position < len(document.lines) {
+		line := document.lines[document.position]
+		if line.text != "" && line.indentation < start.indentation {
+			break
+		}
+		document.position++
+
+		if line.indentation == start.indentation && line.text == "..." {
+			break
+		}
+
+		if line.text != "" {
+			builder.WriteString(strings.Repeat(" ", line.indentation-indentation))
+			builder.WriteString(line.text)
+		}
+		builder.WriteString("\n")
+	}
+
+	diagnostics := map[string]any{}
+	if err := yaml.Unmarshal([]byte(builder.String()), &diagnostics); err != nil {
+		return nil, errors.NewInputError("Unable to parse TAP YAML diagnostics: %s", err)
+	}
+
+	return diagnostics, nil
+}
+
+func (p TAPParser) testsFor(testPoint tapTestPoint) []v1.Test {
+	tests := make([]v1.Test, 0, len(testPoint.children)+1)
+
+	childFailed := false
+	for _, child := range testPoint.children {
+		child.Lineage = append([]string{testPoint.description}, child.Lineage...)
+		childFailed = childFailed || child.Attempt.Status.ImpliesFailure()
+		tests = append(tests, child)
+	}
+
+	// A subtest is summarized by its test point, which only carries new information when none of its children failed
+	if len(testPoint.children) > 0 && (testPoint.ok || childFailed) {
+		return tests
+	}
+
+	var message *string
+	if diagnosticMessage, ok := testPoint.diagnostics["message"].(string); ok {
+		message = &diagnosticMessage
+	}
+
+	var reason *string
+	if testPoint.reason != "" {
+		reason = &testPoint.reason
+	}
+
+	var status v1.TestStatus
+	switch {
+	case strings.HasPrefix(testPoint.directive, "skip"):
+		status = v1.NewSkippedTestStatus(reason)
+	case testPoint.directive == "todo":
+		status = v1.NewTodoTestStatus(reason)
+	case testPoint.ok:
+		status = v1.NewSuccessfulTestStatus()
+	default:
+		status = v1.NewFailedTestStatus(message, nil, p.backtraceFor(testPoint.diagnostics))
+	}
+
+	duration := testPoint.duration
+	if milliseconds, ok := p.float(testPoint.diagnostics["duration_ms"]); ok {
+		parsedDuration := time.Duration(math.Round(milliseconds * float64(time.Millisecond)))
+		duration = &parsedDuration
+	}
+
+	return append(tests, v1.Test{
+		Lineage:  []string{testPoint.description},
+		Location: p.locationFor(testPoint.diagnostics),
+		Attempt: v1.TestAttempt{
+			Duration: duration,
+			Status:   status,
+		},
+	})
+}
+
+// node-tap writes a `stack`, other producers write `at` either as a string or as a file/line/column mapping
+func (p TAPParser) backtraceFor(diagnostics map[string]any) []string {
+	if stack, ok := diagnostics["stack"].(string); ok {
+		backtrace := make([]string, 0)
+		for _, line := range strings.Split(stack, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				backtrace = append(backtrace, line)
+			}
+		}
+		if len(backtrace) > 0 {
+			return backtrace
+		}
+	}
+
+	switch at := diagnostics["at"].(type) {
+	case string:
+		return []string{at}
+	case map[string]any:
+		file, ok := at["file"].(string)
+		if !ok {
+			return nil
+		}
+
+		location := file
+		if line, ok := at["line"].(int); ok {
+			location = fmt.Sprintf("%s:%d", location, line)
+			if column, ok := at["column"].(int); ok {
+				location = fmt.Sprintf("%s:%d", location, column)
+			}
+		}
+		return []string{location}
+	}
+
+	return nil
+}
+
+func (p TAPParser) locationFor(diagnostics map[string]any) *v1.Location {
+	at, ok := diagnostics["at"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	file, ok := at["file"].(string)
+	if !ok {
+		return nil
+	}
+
+	location := v1.Location{File: file}
+	if line, ok := at["line"].(int); ok {
+		location.Line = &line
+	}
+	if column, ok := at["column"].(int); ok {
+		location.Column = &column
+	}
+
+	return &location
+}
+
+func (p TAPParser) parseTime(value string, unit string) *time.Duration {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+
+	multiplier := time.Millisecond
+	if unit == "s" {
+		multiplier = time.Second
+	}
+
+	duration := time.Duration(math.Round(number * float64(multiplier)))
+	return &duration
+}
+
+func (p TAPParser) float(value any) (float64, bool) {
+	switch number := value.(type) {
+	case int:
+		return float64(number), true
+	case float64:
+		return number, true
+	default:
+		return 0, false
+	}
+}
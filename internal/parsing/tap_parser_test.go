@@ -0,0 +1,150 @@
+package parsing_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TAPParser", func() {
+	Describe("Parse", func() {
+		It("parses the sample node-tap file", func() {
+			fixture, err := os.Open("../../test/fixtures/node-tap.tap")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.TAPParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			rwxJSON, err := json.MarshalIndent(testResults, "", "  ")
+			Expect(err).ToNot(HaveOccurred())
+			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
+		})
+
+		It("parses the sample bats file", func() {
+			fixture, err := os.Open("../../test/fixtures/bats.tap")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.TAPParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+
+			rwxJSON, err := json.MarshalIndent(testResults, "", "  ")
+			Expect(err).ToNot(HaveOccurred())
+			cupaloy.SnapshotT(GinkgoT(), rwxJSON)
+		})
+
+		It("maps nested subtests to the lineage", func() {
+			fixture, err := os.Open("../../test/fixtures/node-tap.tap")
+			Expect(err).ToNot(HaveOccurred())
+
+			testResults, err := parsing.TAPParser{}.Parse(fixture)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Summary.Tests).To(Equal(7))
+			Expect(testResults.Summary.Successful).To(Equal(3))
+			Expect(testResults.Summary.Failed).To(Equal(1))
+			Expect(testResults.Summary.Skipped).To(Equal(2))
+			Expect(testResults.Summary.Todo).To(Equal(1))
+
+			failedTest := testResults.Tests[1]
+			Expect(failedTest.Name).To(Equal("test/calculator.test.js addition adds negative numbers"))
+			Expect(failedTest.Lineage).To(Equal(
+				[]string{"test/calculator.test.js", "addition", "adds negative numbers"},
+			))
+			Expect(*failedTest.Attempt.Status.Message).To(Equal("should be equal"))
+			Expect(failedTest.Attempt.Status.Backtrace).To(Equal([]string{
+				"Test.<anonymous> (test/calculator.test.js:12:5)",
+				"Test.cb (node_modules/tap/lib/test.js:128:11)",
+			}))
+			Expect(failedTest.Location.File).To(Equal("test/calculator.test.js"))
+			Expect(*failedTest.Location.Line).To(Equal(12))
+
+			Expect(testResults.Tests[5].Name).To(Equal("test/calculator.test.js formats results with a # sign"))
+		})
+
+		It("reports the test point of a failed subtest without failed children", func() {
+			testResults, err := parsing.TAPParser{}.Parse(strings.NewReader(strings.Join([]string{
+				"TAP version 14",
+				"ok 1 - parent {",
+				"    1..2",
+				"    ok 1 - child",
+				"}",
+				"# Subtest: other parent",
+				"    ok 1 - other child",
+				"not ok 2 - other parent",
+				"1..2",
+			}, "\n")))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(3))
+			Expect(testResults.Tests[0].Name).To(Equal("parent child"))
+			Expect(testResults.Tests[1].Name).To(Equal("other parent other child"))
+			Expect(testResults.Tests[2].Name).To(Equal("other parent"))
+			Expect(testResults.Tests[2].Attempt.Status.Kind).To(Equal(v1.TestStatusFailed))
+		})
+
+		It("reports a bail out as an other error", func() {
+			testResults, err := parsing.TAPParser{}.Parse(strings.NewReader(strings.Join([]string{
+				"1..3",
+				"ok 1 - connects",
+				"Bail out! database is unavailable",
+				"ok 2 - never seen",
+			}, "\n")))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(testResults.Tests).To(HaveLen(1))
+			Expect(testResults.OtherErrors).To(Equal([]v1.OtherError{{Message: "Bail out! database is unavailable"}}))
+		})
+
+		It("errors on malformed YAML diagnostics", func() {
+			testResults, err := parsing.TAPParser{}.Parse(strings.NewReader(strings.Join([]string{
+				"not ok 1 - fails",
+				"  ---",
+				"  message: [unterminated",
+				"  ...",
+			}, "\n")))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unable to parse TAP YAML diagnostics"))
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors on TAP without any tests", func() {
+			testResults, err := parsing.TAPParser{}.Parse(strings.NewReader("TAP version 13\n1..0\n"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Did not see any tests, so we cannot be sure it is TAP"))
+			Expect(testResults).To(BeNil())
+		})
+
+		It("errors on files that don't look like TAP", func() {
+			for _, path := range []string{
+				"../../test/fixtures/rspec.json",
+				"../../test/fixtures/junit.xml",
+				"../../test/fixtures/go_test.jsonl",
+				"../../test/fixtures/nextest.jsonl",
+			} {
+				fixture, err := os.Open(path)
+				Expect(err).ToNot(HaveOccurred())
+
+				testResults, err := parsing.TAPParser{}.Parse(fixture)
+				Expect(err).To(HaveOccurred(), path)
+				Expect(err.Error()).To(ContainSubstring("Test results do not look like TAP"))
+				Expect(testResults).To(BeNil())
+			}
+		})
+	})
+
+	Describe("Sniff", func() {
+		It("looks at the first non-empty line of the header", func() {
+			Expect(parsing.TAPParser{}.Sniff([]byte("\n\nTAP version 14\nok 1 - works"))).To(BeTrue())
+			Expect(parsing.TAPParser{}.Sniff([]byte("1..3\n"))).To(BeTrue())
+			Expect(parsing.TAPParser{}.Sniff([]byte("not ok 1 - fails"))).To(BeTrue())
+			Expect(parsing.TAPParser{}.Sniff([]byte(`{"ok": true}`))).To(BeFalse())
+			Expect(parsing.TAPParser{}.Sniff([]byte("  ok 1 - indented"))).To(BeFalse())
+			Expect(parsing.TAPParser{}.Sniff([]byte("\n  \n"))).To(BeFalse())
+		})
+	})
+})
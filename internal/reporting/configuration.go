@@ -1,11 +1,24 @@
 package reporting
 
-import "github.com/rwx-research/captain-cli/internal/providers"
+import (
+	"github.com/rwx-research/captain-cli/internal/providers"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
 
 type Configuration struct {
-	CloudEnabled         bool
-	CloudHost            string
-	SuiteID              string
-	RetryCommandTemplate string
-	Provider             providers.Provider
+	CloudEnabled                     bool
+	CloudHost                        string
+	SuiteID                          string
+	RetryCommandTemplate             string
+	RetryCommandTemplatesByFramework map[v1.Framework]string
+	Provider                         providers.Provider
+}
+
+// RetryCommandTemplateFor returns the retry command template for the tests of the given framework
+func (c Configuration) RetryCommandTemplateFor(framework v1.Framework) string {
+	if retryCommandTemplate, ok := c.RetryCommandTemplatesByFramework[framework]; ok {
+		return retryCommandTemplate
+	}
+
+	return c.RetryCommandTemplate
 }
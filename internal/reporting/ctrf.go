@@ -0,0 +1,284 @@
+package reporting
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	"github.com/rwx-research/captain-cli/internal/fs"
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// WriteCTRFSummary writes the test results as a CTRF report. Since a CTRF report only describes a single tool, the
+// results of several frameworks are combined into one report that names all of them.
+func WriteCTRFSummary(file fs.File, allTestResults []v1.TestResults, _ Configuration) error {
+	reportFormat := "CTRF"
+	specVersion := "0.0.0"
+
+	var tool parsing.CTRFTool
+	if len(allTestResults) == 1 {
+		tool = newCTRFTool(allTestResults[0].Framework)
+	} else {
+		toolNames := make([]string, 0, len(allTestResults))
+		frameworks := make([]v1.Framework, 0, len(allTestResults))
+		for _, testResults := range allTestResults {
+			toolNames = append(toolNames, newCTRFTool(testResults.Framework).Name)
+			frameworks = append(frameworks, testResults.Framework)
+		}
+
+		tool = parsing.CTRFTool{
+			Name:  strings.Join(toolNames, ", "),
+			Extra: map[string]any{"frameworks": frameworks},
+		}
+	}
+
+	summary := parsing.CTRFSummary{}
+	tests := make([]parsing.CTRFTest, 0)
+	otherErrors := make([]v1.OtherError, 0)
+	for _, testResults := range allTestResults {
+		summary.Tests += len(testResults.Tests)
+		otherErrors = append(otherErrors, testResults.OtherErrors...)
+
+		for _, test := range testResults.Tests {
+			ctrfTest := newCTRFTest(test)
+
+			switch ctrfTest.Status {
+			case "passed":
+				summary.Passed++
+			case "failed":
+				summary.Failed++
+			case "pending":
+				summary.Pending++
+			case "skipped":
+				summary.Skipped++
+			default:
+				summary.Other++
+			}
+
+			for _, attempt := range append([]v1.TestAttempt{test.Attempt}, test.PastAttempts...) {
+				if attempt.StartedAt != nil && (summary.Start == 0 || ctrfTime(attempt.StartedAt) < summary.Start) {
+					summary.Start = ctrfTime(attempt.StartedAt)
+				}
+				if attempt.FinishedAt != nil && ctrfTime(attempt.FinishedAt) > summary.Stop {
+					summary.Stop = ctrfTime(attempt.FinishedAt)
+				}
+			}
+
+			tests = append(tests, ctrfTest)
+		}
+	}
+
+	results := parsing.CTRFResults{
+		Tool:    &tool,
+		Summary: &summary,
+		Tests:   tests,
+	}
+	if len(otherErrors) > 0 {
+		results.Extra = map[string]any{"otherErrors": otherErrors}
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(parsing.CTRFReport{
+		ReportFormat: &reportFormat,
+		SpecVersion:  &specVersion,
+		Results:      &results,
+	}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func newCTRFTool(framework v1.Framework) parsing.CTRFTool {
+	tool := parsing.CTRFTool{Name: string(framework.Kind)}
+	switch {
+	case !framework.IsOther():
+		tool.Extra = map[string]any{"language": string(framework.Language)}
+	case framework.IsProvided():
+		tool.Name = *framework.ProvidedKind
+		tool.Extra = map[string]any{"language": *framework.ProvidedLanguage}
+	}
+
+	return tool
+}
+
+func newCTRFTest(test v1.Test) parsing.CTRFTest {
+	status, rawStatus := ctrfStatus(test.Attempt.Status)
+	message, trace := ctrfMessageAndTrace(test.Attempt.Status)
+
+	ctrfTest := parsing.CTRFTest{
+		Name:      test.Name,
+		Status:    status,
+		Start:     ctrfTimePointer(test.Attempt.StartedAt),
+		Stop:      ctrfTimePointer(test.Attempt.FinishedAt),
+		Message:   message,
+		Trace:     trace,
+		RawStatus: &rawStatus,
+		Stdout:    ctrfOutput(test.Attempt.Stdout),
+		Stderr:    ctrfOutput(test.Attempt.Stderr),
+	}
+
+	if test.Attempt.Duration != nil {
+		ctrfTest.Duration = ctrfDuration(*test.Attempt.Duration)
+	}
+
+	if len(test.Lineage) > 1 {
+		ctrfTest.Suite = test.Lineage[:len(test.Lineage)-1]
+	}
+
+	extra := parsing.CTRFTestExtra{
+		Exception:      ctrfException(test.Attempt.Status),
+		ID:             test.ID,
+		Lineage:        test.Lineage,
+		Meta:           test.Attempt.Meta,
+		OriginalStatus: ctrfOriginalStatus(test.Attempt.Status),
+	}
+
+	if test.Location != nil {
+		filePath := test.Location.File
+		ctrfTest.FilePath = &filePath
+		ctrfTest.Line = test.Location.Line
+		extra.Column = test.Location.Column
+	}
+
+	if len(test.PastAttempts) > 0 {
+		retries := len(test.PastAttempts)
+		flaky := test.Flaky()
+		ctrfTest.Retries = &retries
+		ctrfTest.Flaky = &flaky
+
+		for i, pastAttempt := range test.PastAttempts {
+			ctrfTest.RetryAttempts = append(ctrfTest.RetryAttempts, newCTRFRetryAttempt(i+1, pastAttempt))
+		}
+	}
+
+	ctrfTest.Extra = ctrfExtra(extra)
+	return ctrfTest
+}
+
+func newCTRFRetryAttempt(number int, attempt v1.TestAttempt) parsing.CTRFRetryAttempt {
+	status, rawStatus := ctrfStatus(attempt.Status)
+	message, trace := ctrfMessageAndTrace(attempt.Status)
+
+	retryAttempt := parsing.CTRFRetryAttempt{
+		Attempt: number,
+		Status:  status,
+		Message: message,
+		Trace:   trace,
+		Stdout:  ctrfOutput(attempt.Stdout),
+		Stderr:  ctrfOutput(attempt.Stderr),
+		Start:   ctrfTimePointer(attempt.StartedAt),
+		Stop:    ctrfTimePointer(attempt.FinishedAt),
+		Extra: ctrfExtra(parsing.CTRFTestExtra{
+			Exception:      ctrfException(attempt.Status),
+			Meta:           attempt.Meta,
+			RawStatus:      &rawStatus,
+			OriginalStatus: ctrfOriginalStatus(attempt.Status),
+		}),
+	}
+
+	if attempt.Duration != nil {
+		duration := ctrfDuration(*attempt.Duration)
+		retryAttempt.Duration = &duration
+	}
+
+	return retryAttempt
+}
+
+// ctrfStatus returns the closest CTRF status together with the original status
+func ctrfStatus(status v1.TestStatus) (string, string) {
+	rawStatus := string(status.Kind)
+	if status.Kind == v1.TestStatusQuarantined && status.OriginalStatus != nil {
+		status = *status.OriginalStatus
+	}
+
+	switch status.Kind {
+	case v1.TestStatusSuccessful:
+		return "passed", rawStatus
+	case v1.TestStatusFailed, v1.TestStatusTimedOut:
+		return "failed", rawStatus
+	case v1.TestStatusPended, v1.TestStatusTodo:
+		return "pending", rawStatus
+	case v1.TestStatusSkipped:
+		return "skipped", rawStatus
+	case v1.TestStatusCanceled, v1.TestStatusQuarantined:
+		return "other", rawStatus
+	}
+
+	return "other", rawStatus
+}
+
+func ctrfOriginalStatus(status v1.TestStatus) *string {
+	if status.Kind != v1.TestStatusQuarantined || status.OriginalStatus == nil {
+		return nil
+	}
+
+	originalStatus := string(status.OriginalStatus.Kind)
+	return &originalStatus
+}
+
+func ctrfMessageAndTrace(status v1.TestStatus) (*string, *string) {
+	if status.Kind == v1.TestStatusQuarantined && status.OriginalStatus != nil {
+		status = *status.OriginalStatus
+	}
+
+	var trace *string
+	if len(status.Backtrace) > 0 {
+		joinedBacktrace := strings.Join(status.Backtrace, "\n")
+		trace = &joinedBacktrace
+	}
+
+	return status.Message, trace
+}
+
+func ctrfException(status v1.TestStatus) *string {
+	if status.Kind == v1.TestStatusQuarantined && status.OriginalStatus != nil {
+		return status.OriginalStatus.Exception
+	}
+
+	return status.Exception
+}
+
+func ctrfExtra(extra parsing.CTRFTestExtra) map[string]any {
+	buf, err := json.Marshal(extra)
+	if err != nil {
+		return nil
+	}
+
+	var encodedExtra map[string]any
+	if err := json.Unmarshal(buf, &encodedExtra); err != nil || len(encodedExtra) == 0 {
+		return nil
+	}
+
+	return encodedExtra
+}
+
+func ctrfOutput(output *string) []string {
+	if output == nil {
+		return nil
+	}
+
+	return []string{*output}
+}
+
+func ctrfDuration(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+func ctrfTime(t *time.Time) float64 {
+	// Splitting off whole milliseconds keeps the fraction from getting lost in float64 precision
+	return float64(t.UnixMilli()) + ctrfDuration(time.Duration(t.Nanosecond())%time.Millisecond)
+}
+
+func ctrfTimePointer(t *time.Time) *float64 {
+	if t == nil {
+		return nil
+	}
+
+	milliseconds := ctrfTime(t)
+	return &milliseconds
+}
@@ -0,0 +1,146 @@
+package reporting_test
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/mocks"
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	"github.com/rwx-research/captain-cli/internal/reporting"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CTRF Report", func() {
+	var (
+		mockFile    *mocks.File
+		testResults v1.TestResults
+	)
+
+	BeforeEach(func() {
+		mockFile = new(mocks.File)
+		mockFile.Builder = new(strings.Builder)
+
+		id := "spec/models/user_spec.rb[1:2]"
+		line := 12
+		column := 3
+		message := "expected true to equal false"
+		exception := "RSpec::Expectations::ExpectationNotMetError"
+		stdout := "some output\nover two lines"
+		stderr := "a warning"
+		startedAt := time.Date(2024, 3, 12, 10, 15, 2, 118000000, time.UTC)
+		finishedAt := startedAt.Add(1500 * time.Millisecond)
+		oneSecond := time.Second
+		twoSeconds := 2 * time.Second
+
+		testResults = *v1.NewTestResults(
+			v1.RubyRSpecFramework,
+			[]v1.Test{
+				{
+					ID:       &id,
+					Name:     "User validates the email",
+					Lineage:  []string{"User", "validates the email"},
+					Location: &v1.Location{File: "spec/models/user_spec.rb", Line: &line, Column: &column},
+					Attempt: v1.TestAttempt{
+						Duration:   &twoSeconds,
+						Meta:       map[string]any{"file": "./spec/models/user_spec.rb"},
+						Status:     v1.NewSuccessfulTestStatus(),
+						Stdout:     &stdout,
+						Stderr:     &stderr,
+						StartedAt:  &startedAt,
+						FinishedAt: &finishedAt,
+					},
+					PastAttempts: []v1.TestAttempt{
+						{
+							Duration: &oneSecond,
+							Status:   v1.NewFailedTestStatus(&message, &exception, []string{"user_spec.rb:14", "user.rb:3"}),
+						},
+					},
+				},
+				{
+					Name:    "User is quarantined",
+					Attempt: v1.TestAttempt{Duration: &oneSecond, Status: v1.NewQuarantinedTestStatus(v1.NewTimedOutTestStatus())},
+				},
+				{
+					Name:    "User is canceled",
+					Attempt: v1.TestAttempt{Duration: &oneSecond, Status: v1.NewCanceledTestStatus()},
+				},
+				{
+					Name:    "User is pended",
+					Attempt: v1.TestAttempt{Duration: &oneSecond, Status: v1.NewPendedTestStatus(&message)},
+				},
+				{
+					Name:    "User is todo",
+					Attempt: v1.TestAttempt{Duration: &oneSecond, Status: v1.NewTodoTestStatus(nil)},
+				},
+				{
+					Name:    "User is skipped",
+					Attempt: v1.TestAttempt{Duration: &oneSecond, Status: v1.NewSkippedTestStatus(nil)},
+				},
+			},
+			[]v1.OtherError{{Message: "an error outside of the tests"}},
+		)
+	})
+
+	It("produces a CTRF report", func() {
+		var report parsing.CTRFReport
+
+		Expect(reporting.WriteCTRFSummary(mockFile, []v1.TestResults{testResults}, reporting.Configuration{})).To(Succeed())
+		Expect(json.Unmarshal([]byte(mockFile.Builder.String()), &report)).To(Succeed())
+
+		Expect(*report.ReportFormat).To(Equal("CTRF"))
+		Expect(report.Results.Tool.Name).To(Equal("RSpec"))
+		Expect(*report.Results.Summary).To(Equal(parsing.CTRFSummary{
+			Tests:   6,
+			Passed:  1,
+			Failed:  1,
+			Pending: 2,
+			Skipped: 1,
+			Other:   1,
+			Start:   1710238502118,
+			Stop:    1710238503618,
+		}))
+
+		Expect(report.Results.Tests).To(HaveLen(6))
+		Expect(report.Results.Tests[0].Status).To(Equal("passed"))
+		Expect(report.Results.Tests[0].Duration).To(Equal(2000.0))
+		Expect(report.Results.Tests[0].Suite).To(Equal(parsing.CTRFSuite{"User"}))
+		Expect(*report.Results.Tests[0].FilePath).To(Equal("spec/models/user_spec.rb"))
+		Expect(*report.Results.Tests[0].Line).To(Equal(12))
+		Expect(*report.Results.Tests[0].Retries).To(Equal(1))
+		Expect(*report.Results.Tests[0].Flaky).To(BeTrue())
+		Expect(report.Results.Tests[0].Stdout).To(Equal([]string{"some output\nover two lines"}))
+		Expect(report.Results.Tests[0].RetryAttempts).To(HaveLen(1))
+		Expect(*report.Results.Tests[0].RetryAttempts[0].Trace).To(Equal("user_spec.rb:14\nuser.rb:3"))
+
+		Expect(report.Results.Tests[1].Status).To(Equal("failed"))
+		Expect(*report.Results.Tests[1].RawStatus).To(Equal("quarantined"))
+	})
+
+	It("round-trips through the CTRF parser", func() {
+		Expect(reporting.WriteCTRFSummary(mockFile, []v1.TestResults{testResults}, reporting.Configuration{})).To(Succeed())
+
+		parsedTestResults, err := parsing.CTRFParser{}.Parse(strings.NewReader(mockFile.Builder.String()))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(parsedTestResults.Framework).To(Equal(testResults.Framework))
+		Expect(parsedTestResults.Summary).To(Equal(testResults.Summary))
+		Expect(parsedTestResults.OtherErrors).To(Equal(testResults.OtherErrors))
+		Expect(parsedTestResults.Tests).To(Equal(testResults.Tests))
+	})
+
+	It("round-trips other frameworks", func() {
+		language := "Haskell"
+		kind := "HSpec"
+		testResults.Framework = v1.NewOtherFramework(&language, &kind)
+
+		Expect(reporting.WriteCTRFSummary(mockFile, []v1.TestResults{testResults}, reporting.Configuration{})).To(Succeed())
+
+		parsedTestResults, err := parsing.CTRFParser{}.Parse(strings.NewReader(mockFile.Builder.String()))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsedTestResults.Framework).To(Equal(testResults.Framework))
+	})
+})
@@ -0,0 +1,248 @@
+package reporting
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+var (
+	newlyFailingSection markdownTestSection = "❌ Newly Failing"
+	newlyPassingSection markdownTestSection = "✅ Newly Passing"
+	newlySkippedSection markdownTestSection = "⏭️ Newly Skipped"
+	slowerSection       markdownTestSection = "🐢 Slower"
+	addedSection        markdownTestSection = "🆕 Added"
+	removedSection      markdownTestSection = "🗑️ Removed"
+)
+
+// diffSection is a list of tests that changed in the same way, along with a note on how each of them changed
+type diffSection struct {
+	name  markdownTestSection
+	title string
+	tests []v1.Test
+	notes []string
+}
+
+func diffSections(diff v1.TestResultsDiff) []diffSection {
+	sections := []diffSection{
+		{name: newlyFailingSection, title: "Newly failing"},
+		{name: newlyPassingSection, title: "Newly passing"},
+		{name: newlySkippedSection, title: "Newly skipped"},
+		{name: slowerSection, title: "Slower"},
+		{name: addedSection, title: "Added"},
+		{name: removedSection, title: "Removed"},
+	}
+
+	wasNote := func(testDiff v1.TestDiff) string {
+		return fmt.Sprintf("Was %v", testDiff.Base.Attempt.Status.Kind)
+	}
+
+	for _, testDiff := range diff.NewlyFailing {
+		sections[0].tests = append(sections[0].tests, testDiff.Head)
+		sections[0].notes = append(sections[0].notes, wasNote(testDiff))
+	}
+
+	for _, testDiff := range diff.NewlyPassing {
+		sections[1].tests = append(sections[1].tests, testDiff.Head)
+		sections[1].notes = append(sections[1].notes, wasNote(testDiff))
+	}
+
+	for _, testDiff := range diff.NewlySkipped {
+		sections[2].tests = append(sections[2].tests, testDiff.Head)
+		sections[2].notes = append(sections[2].notes, wasNote(testDiff))
+	}
+
+	for _, testDiff := range diff.Slower {
+		base := *testDiff.Base.Attempt.Duration
+		head := *testDiff.Head.Attempt.Duration
+
+		sections[3].tests = append(sections[3].tests, testDiff.Head)
+		sections[3].notes = append(sections[3].notes, fmt.Sprintf(
+			"Took %v instead of %v, %.1fx as long",
+			head.Round(time.Millisecond),
+			base.Round(time.Millisecond),
+			float64(head)/float64(base),
+		))
+	}
+
+	sections[4].tests = diff.Added
+	sections[5].tests = diff.Removed
+
+	return sections
+}
+
+// WriteTextDiff writes a short, human-readable list of the tests that changed between two runs
+func WriteTextDiff(w io.Writer, diffs []v1.TestResultsDiff) error {
+	for _, diff := range diffs {
+		compared := fmt.Sprintf(
+			"\nCompared %d %s to %d %s.\n",
+			diff.BaseTests,
+			pluralize(diff.BaseTests, "base test", "base tests"),
+			diff.HeadTests,
+			pluralize(diff.HeadTests, "head test", "head tests"),
+		)
+		if len(diffs) > 1 {
+			compared = fmt.Sprintf("\n%v: %s", diff.Framework, strings.TrimPrefix(compared, "\n"))
+		}
+
+		if _, err := w.Write([]byte(compared)); err != nil {
+			return errors.WithStack(err)
+		}
+
+		if diff.Changes() == 0 {
+			if _, err := w.Write([]byte("No changes.\n")); err != nil {
+				return errors.WithStack(err)
+			}
+			continue
+		}
+
+		for _, section := range diffSections(diff) {
+			if len(section.tests) == 0 {
+				continue
+			}
+
+			if _, err := w.Write([]byte(fmt.Sprintf("\n%s (%d):\n", section.title, len(section.tests)))); err != nil {
+				return errors.WithStack(err)
+			}
+
+			for i, test := range section.tests {
+				line := fmt.Sprintf("- %s\n", test.Name)
+				if i < len(section.notes) {
+					line = fmt.Sprintf("- %s (%s)\n", test.Name, strings.ToLower(section.notes[i][:1])+section.notes[i][1:])
+				}
+
+				if _, err := w.Write([]byte(line)); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteMarkdownDiff writes the tests that changed between two runs in the same format as the markdown summary
+func WriteMarkdownDiff(w io.Writer, diffs []v1.TestResultsDiff) error {
+	markdown := new(strings.Builder)
+	if _, err := markdown.WriteString("# Test Results Diff\n\n"); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Results of several frameworks each get their own heading, which moves the sections one level down
+	sectionHeading := "##"
+	if len(diffs) > 1 {
+		sectionHeading = "###"
+	}
+
+	for i, diff := range diffs {
+		if len(diffs) > 1 {
+			frameworkHeading := fmt.Sprintf("## %v\n\n", diff.Framework)
+			if i > 0 {
+				frameworkHeading = "\n" + frameworkHeading
+			}
+
+			if _, err := markdown.WriteString(frameworkHeading); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+
+		shouldTruncate, err := writeMarkdownDiff(markdown, diff, sectionHeading)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if shouldTruncate {
+			if _, err := markdown.WriteString(markdownResultsTruncated); err != nil {
+				return errors.WithStack(err)
+			}
+			break
+		}
+	}
+
+	if _, err := w.Write([]byte(markdown.String())); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func writeMarkdownDiff(markdown *strings.Builder, diff v1.TestResultsDiff, sectionHeading string) (bool, error) {
+	if err := writeMarkdownDiffLine(markdown, diff); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	for _, section := range diffSections(diff) {
+		// Retry commands only make sense for tests that are failing now
+		framework := v1.Framework{}
+		if section.name == newlyFailingSection {
+			framework = diff.Framework
+		}
+
+		shouldTruncate, err := writeMarkdownSection(
+			markdown,
+			sectionHeading,
+			section.name,
+			framework,
+			section.tests,
+			func(test v1.Test) *v1.TestStatus {
+				if section.name != newlyFailingSection {
+					return nil
+				}
+
+				if test.Attempt.Status.Kind == v1.TestStatusQuarantined {
+					return test.Attempt.Status.OriginalStatus
+				}
+
+				return &test.Attempt.Status
+			},
+			section.notes,
+			Configuration{},
+		)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		if shouldTruncate {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func writeMarkdownDiffLine(markdown *strings.Builder, diff v1.TestResultsDiff) error {
+	if _, err := markdown.WriteString(fmt.Sprintf(
+		"%v → %v %v",
+		diff.BaseTests,
+		diff.HeadTests,
+		pluralize(diff.HeadTests, "test", "tests"),
+	)); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if diff.Changes() == 0 {
+		if _, err := markdown.WriteString(", no changes\n"); err != nil {
+			return errors.WithStack(err)
+		}
+		return nil
+	}
+
+	for _, section := range diffSections(diff) {
+		if err := writeMarkdownSummaryStatus(
+			markdown,
+			len(section.tests),
+			strings.ToLower(section.title),
+			strings.ToLower(section.title),
+		); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if _, err := markdown.WriteString("\n"); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
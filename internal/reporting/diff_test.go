@@ -0,0 +1,73 @@
+package reporting_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/reporting"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff Report", func() {
+	var diffs []v1.TestResultsDiff
+
+	BeforeEach(func() {
+		message := "expected true to equal false"
+		oneSecond := time.Second
+		threeSeconds := 3 * time.Second
+		id := "./spec/foo/bar.rb:12"
+		line := 12
+
+		failing := v1.Test{
+			ID:       &id,
+			Name:     "newly failing test",
+			Location: &v1.Location{File: "./spec/foo/bar.rb", Line: &line},
+			Attempt:  v1.TestAttempt{Status: v1.NewFailedTestStatus(&message, nil, []string{"./spec/foo/bar.rb:13"})},
+		}
+
+		diffs = v1.Diff(
+			[]v1.TestResults{*v1.NewTestResults(v1.RubyRSpecFramework, []v1.Test{
+				{ID: &id, Name: "newly failing test", Location: failing.Location, Attempt: v1.TestAttempt{
+					Status: v1.NewSuccessfulTestStatus(),
+				}},
+				{Name: "slower test", Attempt: v1.TestAttempt{Status: v1.NewSuccessfulTestStatus(), Duration: &oneSecond}},
+				{Name: "removed test", Attempt: v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()}},
+			}, nil)},
+			[]v1.TestResults{*v1.NewTestResults(v1.RubyRSpecFramework, []v1.Test{
+				failing,
+				{Name: "slower test", Attempt: v1.TestAttempt{Status: v1.NewSuccessfulTestStatus(), Duration: &threeSeconds}},
+				{Name: "added test", Attempt: v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()}},
+			}, nil)},
+			v1.DiffConfig{SlowdownFactor: 2, SlowdownThreshold: time.Second},
+		)
+	})
+
+	It("produces a readable text diff", func() {
+		output := new(strings.Builder)
+		Expect(reporting.WriteTextDiff(output, diffs)).To(Succeed())
+		cupaloy.SnapshotT(GinkgoT(), output.String())
+	})
+
+	It("produces a readable markdown diff", func() {
+		output := new(strings.Builder)
+		Expect(reporting.WriteMarkdownDiff(output, diffs)).To(Succeed())
+		cupaloy.SnapshotT(GinkgoT(), output.String())
+	})
+
+	It("reports when nothing changed", func() {
+		unchanged := []v1.TestResultsDiff{{Framework: v1.RubyRSpecFramework, BaseTests: 2, HeadTests: 2}}
+
+		output := new(strings.Builder)
+		Expect(reporting.WriteTextDiff(output, unchanged)).To(Succeed())
+		Expect(output.String()).To(Equal("\nCompared 2 base tests to 2 head tests.\nNo changes.\n"))
+
+		output = new(strings.Builder)
+		Expect(reporting.WriteMarkdownDiff(output, unchanged)).To(Succeed())
+		Expect(output.String()).To(Equal("# Test Results Diff\n\n2 → 2 tests, no changes\n"))
+	})
+})
@@ -12,11 +12,37 @@ import (
 	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
 )
 
-func WriteJUnitSummary(file fs.File, testResults v1.TestResults, _ Configuration) error {
+// WriteJUnitSummary writes the test results as JUnit XML with one test suite per framework
+func WriteJUnitSummary(file fs.File, allTestResults []v1.TestResults, _ Configuration) error {
 	result := parsing.JUnitTestResults{
 		TestSuites: make([]parsing.JUnitTestSuite, 0),
 	}
 
+	for _, testResults := range allTestResults {
+		suite := newJUnitTestSuite(testResults)
+		if len(allTestResults) > 1 {
+			suite.Name = testResults.Framework.String()
+		}
+
+		result.TestSuites = append(result.TestSuites, suite)
+	}
+
+	_, err := file.Write([]byte("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n"))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	encoder := xml.NewEncoder(file)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(result); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func newJUnitTestSuite(testResults v1.TestResults) parsing.JUnitTestSuite {
 	finishedAt := time.Time{}
 	startedAt := time.Time{}
 	suite := parsing.JUnitTestSuite{}
@@ -88,19 +114,5 @@ func WriteJUnitSummary(file fs.File, testResults v1.TestResults, _ Configuration
 	totalTests := len(suite.TestCases)
 	suite.Tests = &totalTests
 
-	result.TestSuites = append(result.TestSuites, suite)
-
-	_, err := file.Write([]byte("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n"))
-	if err != nil {
-		return errors.WithStack(err)
-	}
-
-	encoder := xml.NewEncoder(file)
-	encoder.Indent("", "  ")
-
-	if err := encoder.Encode(result); err != nil {
-		return errors.WithStack(err)
-	}
-
-	return nil
+	return suite
 }
@@ -90,9 +90,12 @@ var _ = Describe("JUnit Report", func() {
 	It("produces a parsable JUnit file", func() {
 		var result parsing.JUnitTestResults
 
-		Expect(reporting.WriteJUnitSummary(mockFile, testResults, reporting.Configuration{})).To(Succeed())
+		Expect(
+			reporting.WriteJUnitSummary(mockFile, []v1.TestResults{testResults}, reporting.Configuration{}),
+		).To(Succeed())
 		Expect(xml.Unmarshal([]byte(mockFile.Builder.String()), &result)).To(Succeed())
 		Expect(result.TestSuites).To(HaveLen(1))
+		Expect(result.TestSuites[0].Name).To(Equal(""))
 
 		Expect(result.TestSuites[0].Errors).To(Equal(12))
 		Expect(result.TestSuites[0].Failures).To(Equal(9))
@@ -114,4 +117,31 @@ var _ = Describe("JUnit Report", func() {
 
   (compared using ==)`))
 	})
+
+	It("produces one test suite per framework", func() {
+		var result parsing.JUnitTestResults
+
+		jestTestResults := *v1.NewTestResults(
+			v1.JavaScriptJestFramework,
+			[]v1.Test{{Name: "a jest test", Attempt: v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()}}},
+			nil,
+		)
+
+		Expect(
+			reporting.WriteJUnitSummary(
+				mockFile,
+				[]v1.TestResults{testResults, jestTestResults},
+				reporting.Configuration{},
+			),
+		).To(Succeed())
+		Expect(xml.Unmarshal([]byte(mockFile.Builder.String()), &result)).To(Succeed())
+		Expect(result.TestSuites).To(HaveLen(2))
+
+		Expect(result.TestSuites[0].Name).To(Equal("RSpec (Ruby)"))
+		Expect(result.TestSuites[0].TestCases).To(HaveLen(3))
+
+		Expect(result.TestSuites[1].Name).To(Equal("Jest (JavaScript)"))
+		Expect(result.TestSuites[1].TestCases).To(HaveLen(1))
+		Expect(result.TestSuites[1].TestCases[0].Name).To(Equal("a jest test"))
+	})
 })
@@ -26,6 +26,7 @@ var (
 
 type markdownTest struct {
 	Name      string
+	Note      string
 	Location  string
 	Command   string
 	Message   *string
@@ -40,7 +41,8 @@ const (
 <summary><strong>{{ .Name }}</strong></summary>
 
 <dl>
-{{ if .Retries }}<dd>Retried {{ .Retries}} time{{ if ne .Retries 1 }}s{{end}}</dd>{{ end }}
+{{ if .Note }}<dd>{{ .Note }}</dd>
+{{ end }}{{ if .Retries }}<dd>Retried {{ .Retries}} time{{ if ne .Retries 1 }}s{{end}}</dd>{{ end }}
 {{ if .Location }}<dd>Defined at <code>{{ .Location }}</code></dd>{{ end }}
 {{ if .Command }}<dd>Retry with <code>{{ .Command }}</code></dd>{{ end }}
 {{ if or .Message .Backtrace }}
@@ -62,7 +64,7 @@ const (
 `
 )
 
-func WriteMarkdownSummary(file fs.File, testResults v1.TestResults, cfg Configuration) error {
+func WriteMarkdownSummary(file fs.File, allTestResults []v1.TestResults, cfg Configuration) error {
 	markdown := new(strings.Builder)
 	if _, err := markdown.WriteString(fmt.Sprintf("# `%v` Summary\n\n", cfg.SuiteID)); err != nil {
 		return errors.WithStack(err)
@@ -82,13 +84,57 @@ func WriteMarkdownSummary(file fs.File, testResults v1.TestResults, cfg Configur
 		}
 	}
 
-	if err := writeMarkdownSummaryLine(markdown, testResults); err != nil {
+	// Results of several frameworks each get their own heading, which moves the sections one level down
+	sectionHeading := "##"
+	if len(allTestResults) > 1 {
+		sectionHeading = "###"
+	}
+
+	for i, testResults := range allTestResults {
+		if len(allTestResults) > 1 {
+			frameworkHeading := fmt.Sprintf("## %v\n\n", testResults.Framework)
+			if i > 0 {
+				frameworkHeading = "\n" + frameworkHeading
+			}
+
+			if _, err := markdown.WriteString(frameworkHeading); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+
+		shouldTruncate, err := writeMarkdownTestResults(markdown, testResults, sectionHeading, cfg)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if shouldTruncate {
+			if _, err := markdown.WriteString(markdownResultsTruncated); err != nil {
+				return errors.WithStack(err)
+			}
+			break
+		}
+	}
+
+	if _, err := file.Write([]byte(markdown.String())); err != nil {
 		return errors.WithStack(err)
 	}
 
+	return nil
+}
+
+func writeMarkdownTestResults(
+	markdown *strings.Builder,
+	testResults v1.TestResults,
+	sectionHeading string,
+	cfg Configuration,
+) (bool, error) {
+	if err := writeMarkdownSummaryLine(markdown, testResults); err != nil {
+		return false, errors.WithStack(err)
+	}
+
 	testsBySection := testsByMarkdownSection(testResults)
 	writersBySection := map[markdownTestSection]func(
 		*strings.Builder,
+		string,
 		v1.Framework,
 		[]v1.Test,
 		Configuration,
@@ -109,26 +155,22 @@ func WriteMarkdownSummary(file fs.File, testResults v1.TestResults, cfg Configur
 	}
 
 	for _, section := range orderedSections {
-		shouldTruncate, err := writersBySection[section](markdown, testResults.Framework, testsBySection[section], cfg)
+		shouldTruncate, err := writersBySection[section](
+			markdown,
+			sectionHeading,
+			testResults.Framework,
+			testsBySection[section],
+			cfg,
+		)
 		if err != nil {
-			return errors.WithStack(err)
+			return false, errors.WithStack(err)
 		}
 		if shouldTruncate {
-			if _, err := markdown.WriteString(markdownResultsTruncated); err != nil {
-				return errors.WithStack(err)
-			}
-			if _, err := file.Write([]byte(markdown.String())); err != nil {
-				return errors.WithStack(err)
-			}
-			return nil
+			return true, nil
 		}
 	}
 
-	if _, err := file.Write([]byte(markdown.String())); err != nil {
-		return errors.WithStack(err)
-	}
-
-	return nil
+	return false, nil
 }
 
 func writeMarkdownSummaryStatus(markdown *strings.Builder, value int, singular string, plural string) error {
@@ -245,12 +287,14 @@ func testsByMarkdownSection(testResults v1.TestResults) map[markdownTestSection]
 
 func writeMarkdownFlakySection(
 	markdown *strings.Builder,
+	heading string,
 	framework v1.Framework,
 	tests []v1.Test,
 	cfg Configuration,
 ) (bool, error) {
 	return writeMarkdownSection(
 		markdown,
+		heading,
 		flakySection,
 		framework,
 		tests,
@@ -267,95 +311,110 @@ func writeMarkdownFlakySection(
 
 			return nil
 		},
+		nil,
 		cfg,
 	)
 }
 
 func writeMarkdownFailedSection(
 	markdown *strings.Builder,
+	heading string,
 	framework v1.Framework,
 	tests []v1.Test,
 	cfg Configuration,
 ) (bool, error) {
 	return writeMarkdownSection(
 		markdown,
+		heading,
 		failedSection,
 		framework,
 		tests,
 		func(test v1.Test) *v1.TestStatus {
 			return &test.Attempt.Status
 		},
+		nil,
 		cfg,
 	)
 }
 
 func writeMarkdownTimedOutSection(
 	markdown *strings.Builder,
+	heading string,
 	framework v1.Framework,
 	tests []v1.Test,
 	cfg Configuration,
 ) (bool, error) {
 	return writeMarkdownSection(
 		markdown,
+		heading,
 		timedOutSection,
 		framework,
 		tests,
 		func(test v1.Test) *v1.TestStatus {
 			return &test.Attempt.Status
 		},
+		nil,
 		cfg,
 	)
 }
 
 func writeMarkdownQuarantinedSection(
 	markdown *strings.Builder,
+	heading string,
 	framework v1.Framework,
 	tests []v1.Test,
 	cfg Configuration,
 ) (bool, error) {
 	return writeMarkdownSection(
 		markdown,
+		heading,
 		quarantinedSection,
 		framework,
 		tests,
 		func(test v1.Test) *v1.TestStatus {
 			return test.Attempt.Status.OriginalStatus
 		},
+		nil,
 		cfg,
 	)
 }
 
 func writeMarkdownCanceledSection(
 	markdown *strings.Builder,
+	heading string,
 	framework v1.Framework,
 	tests []v1.Test,
 	cfg Configuration,
 ) (bool, error) {
 	return writeMarkdownSection(
 		markdown,
+		heading,
 		canceledSection,
 		framework,
 		tests,
 		func(test v1.Test) *v1.TestStatus {
 			return &test.Attempt.Status
 		},
+		nil,
 		cfg,
 	)
 }
 
 func writeMarkdownSection(
 	markdown *strings.Builder,
+	heading string,
 	section markdownTestSection,
 	framework v1.Framework,
 	tests []v1.Test,
 	findFailedStatus func(v1.Test) *v1.TestStatus,
+	notes []string,
 	cfg Configuration,
 ) (bool, error) {
 	if len(tests) == 0 {
 		return false, nil
 	}
 
-	if _, err := markdown.WriteString(fmt.Sprintf("\n## %v\n\n", section)); err != nil {
+	if _, err := markdown.WriteString(fmt.Sprintf("\n%v %v\n\n", heading, section)); err != nil {
 		return false, errors.WithStack(err)
 	}
 
@@ -364,9 +423,9 @@ func writeMarkdownSection(
 		return false, errors.WithStack(err)
 	}
 
-	retryTemplate, substitution := retryTemplateAndSubstitutionFor(framework, cfg.RetryCommandTemplate)
+	retryTemplate, substitution := retryTemplateAndSubstitutionFor(framework, cfg.RetryCommandTemplateFor(framework))
 
-	for _, test := range tests {
+	for i, test := range tests {
 		location := ""
 		if test.Location != nil {
 			location = test.Location.String()
@@ -391,6 +450,9 @@ func writeMarkdownSection(
 			Command:  retryCommand,
 			Retries:  len(test.PastAttempts),
 		}
+		if i < len(notes) {
+			markdownTest.Note = notes[i]
+		}
 		if failedStatus != nil {
 			markdownTest.Backtrace = stripansi.Strip(strings.Join(failedStatus.Backtrace, "\n"))
 			if failedStatus.Message != nil {
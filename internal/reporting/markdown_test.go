@@ -140,7 +140,7 @@ var _ = Describe("Markdown Report", func() {
 				CommitSha:  "abcdef113131",
 			},
 		}
-		Expect(reporting.WriteMarkdownSummary(mockFile, testResults, cfg)).To(Succeed())
+		Expect(reporting.WriteMarkdownSummary(mockFile, []v1.TestResults{testResults}, cfg)).To(Succeed())
 		summary := mockFile.Builder.String()
 		cupaloy.SnapshotT(GinkgoT(), summary)
 	})
@@ -153,11 +153,45 @@ var _ = Describe("Markdown Report", func() {
 			RetryCommandTemplate: "bin/rspec {{ tests }}",
 			Provider:             providers.Provider{},
 		}
-		Expect(reporting.WriteMarkdownSummary(mockFile, testResults, cfg)).To(Succeed())
+		Expect(reporting.WriteMarkdownSummary(mockFile, []v1.TestResults{testResults}, cfg)).To(Succeed())
 		summary := mockFile.Builder.String()
 		cupaloy.SnapshotT(GinkgoT(), summary)
 	})
 
+	It("produces a summary per framework", func() {
+		cfg := reporting.Configuration{
+			SuiteID:              "some-suite-id",
+			RetryCommandTemplate: "bin/rspec {{ tests }}",
+			RetryCommandTemplatesByFramework: map[v1.Framework]string{
+				v1.JavaScriptJestFramework: "yarn jest --testPathPattern '{{ testPathPattern }}' " +
+					"--testNamePattern '{{ testNamePattern }}'",
+			},
+		}
+
+		jestTestResults := *v1.NewTestResults(
+			v1.JavaScriptJestFramework,
+			[]v1.Test{
+				{
+					Name:     "a failing jest test",
+					Lineage:  []string{"a failing jest test"},
+					Location: &v1.Location{File: "src/app.test.js"},
+					Attempt:  v1.TestAttempt{Status: v1.NewFailedTestStatus(nil, nil, nil)},
+				},
+			},
+			nil,
+		)
+
+		Expect(
+			reporting.WriteMarkdownSummary(mockFile, []v1.TestResults{testResults, jestTestResults}, cfg),
+		).To(Succeed())
+		summary := mockFile.Builder.String()
+		Expect(summary).To(ContainSubstring("## RSpec (Ruby)\n\n9 tests"))
+		Expect(summary).To(ContainSubstring("\n## Jest (JavaScript)\n\n1 test, 1 failed\n"))
+		Expect(summary).To(ContainSubstring("\n### ❌ Failed\n"))
+		Expect(summary).NotTo(ContainSubstring("\n## ❌ Failed\n"))
+		cupaloy.SnapshotT(GinkgoT(), summary)
+	})
+
 	It("produces a readable summary when cloud is disabled", func() {
 		cfg := reporting.Configuration{
 			SuiteID:      "some-suite-id",
@@ -165,7 +199,7 @@ var _ = Describe("Markdown Report", func() {
 			CloudHost:    "",
 			Provider:     providers.Provider{},
 		}
-		Expect(reporting.WriteMarkdownSummary(mockFile, testResults, cfg)).To(Succeed())
+		Expect(reporting.WriteMarkdownSummary(mockFile, []v1.TestResults{testResults}, cfg)).To(Succeed())
 		summary := mockFile.Builder.String()
 		cupaloy.SnapshotT(GinkgoT(), summary)
 	})
@@ -197,7 +231,7 @@ var _ = Describe("Markdown Report", func() {
 		Expect(
 			reporting.WriteMarkdownSummary(
 				mockFile,
-				*v1.NewTestResults(v1.RubyRSpecFramework, tests, nil),
+				[]v1.TestResults{*v1.NewTestResults(v1.RubyRSpecFramework, tests, nil)},
 				cfg,
 			),
 		).To(Succeed())
@@ -6,12 +6,34 @@ import (
 	"github.com/rwx-research/captain-cli/internal/errors"
 	"github.com/rwx-research/captain-cli/internal/fs"
 	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+	v2 "github.com/rwx-research/captain-cli/internal/testingschema/v2"
 )
 
-func WriteJSONSummary(file fs.File, testResults v1.TestResults, _ Configuration) error {
+// WriteJSONSummary writes the test results as RWX v1 JSON. Results of several frameworks are written as an array.
+func WriteJSONSummary(file fs.File, allTestResults []v1.TestResults, _ Configuration) error {
+	return writeJSON(file, allTestResults)
+}
+
+// WriteV2JSONSummary writes the test results as RWX v2 JSON, which turns attachments, steps & tags that are kept in
+// the meta of V1 test results back into fields of their own.
+func WriteV2JSONSummary(file fs.File, allTestResults []v1.TestResults, _ Configuration) error {
+	v2TestResults := make([]v2.TestResults, len(allTestResults))
+	for i, testResults := range allTestResults {
+		v2TestResults[i] = v2.FromV1(testResults)
+	}
+
+	return writeJSON(file, v2TestResults)
+}
+
+func writeJSON[T any](file fs.File, allTestResults []T) error {
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
 
+	var testResults any = allTestResults
+	if len(allTestResults) == 1 {
+		testResults = allTestResults[0]
+	}
+
 	if err := encoder.Encode(testResults); err != nil {
 		return errors.WithStack(err)
 	}
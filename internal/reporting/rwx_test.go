@@ -0,0 +1,114 @@
+package reporting_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rwx-research/captain-cli/internal/fs"
+	"github.com/rwx-research/captain-cli/internal/mocks"
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	"github.com/rwx-research/captain-cli/internal/reporting"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+	v2 "github.com/rwx-research/captain-cli/internal/testingschema/v2"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RWX Report", func() {
+	var (
+		mockFile    *mocks.File
+		testResults v1.TestResults
+	)
+
+	BeforeEach(func() {
+		mockFile = new(mocks.File)
+		mockFile.Builder = new(strings.Builder)
+
+		fixture, err := os.Open("../../test/fixtures/rwx/v2.json")
+		Expect(err).ToNot(HaveOccurred())
+
+		parsedTestResults, err := parsing.RWXParser{}.Parse(fixture)
+		Expect(err).ToNot(HaveOccurred())
+		testResults = *parsedTestResults
+	})
+
+	It("writes RWX v1 JSON", func() {
+		Expect(reporting.WriteJSONSummary(mockFile, []v1.TestResults{testResults}, reporting.Configuration{})).To(Succeed())
+
+		var writtenTestResults v1.TestResults
+		Expect(json.Unmarshal([]byte(mockFile.Builder.String()), &writtenTestResults)).To(Succeed())
+		Expect(writtenTestResults.Tests[0].Attempt.Meta).To(HaveKey("tags"))
+	})
+
+	It("writes RWX v2 JSON", func() {
+		Expect(reporting.WriteV2JSONSummary(mockFile, []v1.TestResults{testResults}, reporting.Configuration{})).To(Succeed())
+
+		fixture, err := os.ReadFile("../../test/fixtures/rwx/v2.json")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mockFile.Builder.String()).To(MatchJSON(fixture))
+	})
+
+	It("writes an array of RWX v2 JSON for several frameworks", func() {
+		Expect(reporting.WriteV2JSONSummary(
+			mockFile,
+			[]v1.TestResults{testResults, *v1.NewTestResults(v1.RubyRSpecFramework, []v1.Test{}, nil)},
+			reporting.Configuration{},
+		)).To(Succeed())
+
+		var writtenTestResults []v2.TestResults
+		Expect(json.Unmarshal([]byte(mockFile.Builder.String()), &writtenTestResults)).To(Succeed())
+		Expect(writtenTestResults).To(HaveLen(2))
+		Expect(writtenTestResults[0].Tests[0].Tags).To(Equal([]string{"@smoke", "@auth"}))
+	})
+
+	Describe("reading the report back in", func() {
+		var allTestResults []v1.TestResults
+
+		BeforeEach(func() {
+			allTestResults = []v1.TestResults{
+				testResults,
+				*v1.NewTestResults(
+					v1.RubyRSpecFramework,
+					[]v1.Test{{Name: "passes", Attempt: v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()}}},
+					nil,
+				),
+			}
+		})
+
+		for _, reporter := range []struct {
+			name  string
+			write func(fs.File, []v1.TestResults, reporting.Configuration) error
+		}{
+			{name: "RWX v1 JSON", write: reporting.WriteJSONSummary},
+			{name: "RWX v2 JSON", write: reporting.WriteV2JSONSummary},
+		} {
+			reporter := reporter
+
+			It(fmt.Sprintf("parses the %s of several frameworks", reporter.name), func() {
+				Expect(reporter.write(mockFile, allTestResults, reporting.Configuration{})).To(Succeed())
+
+				reparsedTestResults, err := parsing.RWXParser{}.ParseAll(strings.NewReader(mockFile.Builder.String()))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reparsedTestResults).To(HaveLen(2))
+
+				for i, reparsed := range reparsedTestResults {
+					Expect(reparsed.Framework).To(Equal(allTestResults[i].Framework))
+					Expect(reparsed.Summary).To(Equal(allTestResults[i].Summary))
+					Expect(reparsed.Tests).To(HaveLen(len(allTestResults[i].Tests)))
+				}
+				Expect(reparsedTestResults[0].Tests[0].Attempt.Meta).To(HaveKey("tags"))
+			})
+		}
+
+		It("validates the RWX v1 JSON of several frameworks", func() {
+			Expect(reporting.WriteJSONSummary(mockFile, allTestResults, reporting.Configuration{})).To(Succeed())
+
+			validationErrors, err := v1.Validate([]byte(mockFile.Builder.String()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(validationErrors).To(BeEmpty())
+		})
+	})
+})
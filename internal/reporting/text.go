@@ -9,9 +9,24 @@ import (
 	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
 )
 
-func WriteTextSummary(file fs.File, testResults v1.TestResults, _ Configuration) error {
+// WriteTextSummary writes a short, human-readable summary of the test results, one after another for each framework
+func WriteTextSummary(file fs.File, allTestResults []v1.TestResults, _ Configuration) error {
+	for _, testResults := range allTestResults {
+		totalTests := fmt.Sprintf("%d", testResults.Summary.Tests)
+		if len(allTestResults) > 1 {
+			totalTests = fmt.Sprintf("%d %v", testResults.Summary.Tests, testResults.Framework)
+		}
+
+		if err := writeTextSummary(file, testResults, totalTests); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+func writeTextSummary(file fs.File, testResults v1.TestResults, totalTests string) error {
 	statuses := make(map[v1.TestStatusKind][]string)
-	totalTests := testResults.Summary.Tests
 
 	for _, test := range testResults.Tests {
 		if test.Attempt.Status.Kind == v1.TestStatusSuccessful {
@@ -27,7 +42,7 @@ func WriteTextSummary(file fs.File, testResults v1.TestResults, _ Configuration)
 		statuses[test.Attempt.Status.Kind] = tests
 	}
 
-	_, err := file.Write([]byte(fmt.Sprintf("\nCaptain detected a total of %d tests.\n", totalTests)))
+	_, err := file.Write([]byte(fmt.Sprintf("\nCaptain detected a total of %s tests.\n", totalTests)))
 	if err != nil {
 		return errors.WithStack(err)
 	}
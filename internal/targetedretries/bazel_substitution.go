@@ -0,0 +1,79 @@
+package targetedretries
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// BazelSubstitution retries entire targets since Bazel has no generic way of selecting individual tests
+type BazelSubstitution struct{}
+
+func (s BazelSubstitution) Example() string {
+	return "bazel test {{ targets }}"
+}
+
+func (s BazelSubstitution) ValidateTemplate(compiledTemplate templating.CompiledTemplate) error {
+	keywords := compiledTemplate.Keywords()
+
+	if len(keywords) == 0 {
+		return errors.NewInputError(
+			"Retrying Bazel requires a template with the 'targets' keyword; no keywords were found",
+		)
+	}
+
+	if len(keywords) > 1 {
+		return errors.NewInputError(
+			"Retrying Bazel requires a template with only the 'targets' keyword; these were found: %v",
+			strings.Join(keywords, ", "),
+		)
+	}
+
+	if keywords[0] != "targets" {
+		return errors.NewInputError(
+			"Retrying Bazel requires a template with only the 'targets' keyword; '%v' was found instead",
+			keywords[0],
+		)
+	}
+
+	return nil
+}
+
+func (s BazelSubstitution) SubstitutionsFor(
+	_ templating.CompiledTemplate,
+	testResults v1.TestResults,
+	filter func(v1.Test) bool,
+) ([]map[string]string, error) {
+	targets := make([]string, 0)
+	targetsSeen := map[string]struct{}{}
+
+	for _, test := range testResults.Tests {
+		if !test.Attempt.Status.ImpliesFailure() {
+			continue
+		}
+		if !filter(test) {
+			continue
+		}
+
+		target, ok := test.Attempt.Meta["target"].(string)
+		if !ok {
+			return nil, errors.NewInputError("Unable to determine the Bazel target of %q", test.Name)
+		}
+
+		if _, ok := targetsSeen[target]; ok {
+			continue
+		}
+
+		targets = append(targets, fmt.Sprintf("'%v'", templating.ShellEscape(target)))
+		targetsSeen[target] = struct{}{}
+	}
+
+	if len(targets) > 0 {
+		return []map[string]string{{"targets": strings.Join(targets, " ")}}, nil
+	}
+
+	return []map[string]string{}, nil
+}
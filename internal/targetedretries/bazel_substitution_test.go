@@ -0,0 +1,141 @@
+package targetedretries_test
+
+import (
+	"os"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	"github.com/rwx-research/captain-cli/internal/targetedretries"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BazelSubstitution", func() {
+	It("adheres to the Substitution interface", func() {
+		var substitution targetedretries.Substitution = targetedretries.BazelSubstitution{}
+		Expect(substitution).NotTo(BeNil())
+	})
+
+	It("works with a real file", func() {
+		substitution := targetedretries.BazelSubstitution{}
+		compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+		Expect(compileErr).NotTo(HaveOccurred())
+
+		err := substitution.ValidateTemplate(compiledTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		fixture, err := os.Open("../../test/fixtures/bazel-testlogs/app/models/user_test/test.xml")
+		Expect(err).ToNot(HaveOccurred())
+
+		testResults, err := parsing.BazelParser{Target: "//app/models:user_test"}.Parse(fixture)
+		Expect(err).ToNot(HaveOccurred())
+
+		substitutions, err := substitution.SubstitutionsFor(
+			compiledTemplate,
+			*testResults,
+			func(_ v1.Test) bool { return true },
+		)
+		Expect(err).NotTo(HaveOccurred())
+		cupaloy.SnapshotT(GinkgoT(), substitutions)
+	})
+
+	Describe("Example", func() {
+		It("compiles and is valid", func() {
+			substitution := targetedretries.BazelSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("ValidateTemplate", func() {
+		It("is invalid for a template without placeholders", func() {
+			substitution := targetedretries.BazelSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("bazel test //...")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template with too many placeholders", func() {
+			substitution := targetedretries.BazelSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("bazel test {{ config }} {{ targets }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template without a targets placeholder", func() {
+			substitution := targetedretries.BazelSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("bazel test {{ tests }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is valid for a template with only a targets placeholder", func() {
+			substitution := targetedretries.BazelSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("bazel test --config=ci {{ targets }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("Substitutions", func() {
+		It("returns the unique failed targets", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("bazel test {{ targets }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			newTest := func(target string, name string, status v1.TestStatus) v1.Test {
+				return v1.Test{
+					Scope:   &target,
+					Name:    name,
+					Attempt: v1.TestAttempt{Status: status, Meta: map[string]any{"target": target}},
+				}
+			}
+
+			testResults := v1.TestResults{
+				Tests: []v1.Test{
+					newTest("//app:a_test", "a1", v1.NewFailedTestStatus(nil, nil, nil)),
+					newTest("//app:a_test", "a2", v1.NewFailedTestStatus(nil, nil, nil)),
+					newTest("//app:b_test", "b1", v1.NewSuccessfulTestStatus()),
+					newTest("@ext//lib:c_test", "c1", v1.NewTimedOutTestStatus()),
+					newTest("//app:d_test", "d1", v1.NewFailedTestStatus(nil, nil, nil)),
+				},
+			}
+
+			substitution := targetedretries.BazelSubstitution{}
+			Expect(substitution.SubstitutionsFor(
+				compiledTemplate,
+				testResults,
+				func(test v1.Test) bool { return test.Name != "d1" },
+			)).To(Equal([]map[string]string{{"targets": "'//app:a_test' '@ext//lib:c_test'"}}))
+		})
+
+		It("errors when the target is unknown", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("bazel test {{ targets }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			testResults := v1.TestResults{
+				Tests: []v1.Test{
+					{Name: "a1", Attempt: v1.TestAttempt{Status: v1.NewFailedTestStatus(nil, nil, nil)}},
+				},
+			}
+
+			substitution := targetedretries.BazelSubstitution{}
+			_, err := substitution.SubstitutionsFor(compiledTemplate, testResults, func(_ v1.Test) bool { return true })
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
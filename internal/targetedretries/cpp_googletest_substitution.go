@@ -0,0 +1,73 @@
+package targetedretries
+
+import (
+	"strings"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+type CppGoogleTestSubstitution struct{}
+
+func (s CppGoogleTestSubstitution) Example() string {
+	return "./build/tests --gtest_filter='{{ filter }}'"
+}
+
+func (s CppGoogleTestSubstitution) ValidateTemplate(compiledTemplate templating.CompiledTemplate) error {
+	keywords := compiledTemplate.Keywords()
+
+	if len(keywords) == 0 {
+		return errors.NewInputError(
+			"Retrying googletest requires a template with the 'filter' keyword; no keywords were found",
+		)
+	}
+
+	if len(keywords) > 1 {
+		return errors.NewInputError(
+			"Retrying googletest requires a template with only the 'filter' keyword; these were found: %v",
+			strings.Join(keywords, ", "),
+		)
+	}
+
+	if keywords[0] != "filter" {
+		return errors.NewInputError(
+			"Retrying googletest requires a template with only the 'filter' keyword; '%v' was found instead",
+			keywords[0],
+		)
+	}
+
+	return nil
+}
+
+// https://google.github.io/googletest/advanced.html#running-a-subset-of-the-tests
+func (s CppGoogleTestSubstitution) SubstitutionsFor(
+	_ templating.CompiledTemplate,
+	testResults v1.TestResults,
+	filter func(v1.Test) bool,
+) ([]map[string]string, error) {
+	patterns := make([]string, 0)
+	patternsSeen := map[string]struct{}{}
+
+	for _, test := range testResults.Tests {
+		if !test.Attempt.Status.ImpliesFailure() {
+			continue
+		}
+		if !filter(test) {
+			continue
+		}
+
+		if _, ok := patternsSeen[test.Name]; ok {
+			continue
+		}
+
+		patterns = append(patterns, templating.ShellEscape(test.Name))
+		patternsSeen[test.Name] = struct{}{}
+	}
+
+	if len(patterns) > 0 {
+		return []map[string]string{{"filter": strings.Join(patterns, ":")}}, nil
+	}
+
+	return []map[string]string{}, nil
+}
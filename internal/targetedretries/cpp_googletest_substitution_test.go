@@ -0,0 +1,166 @@
+package targetedretries_test
+
+import (
+	"os"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	"github.com/rwx-research/captain-cli/internal/targetedretries"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CppGoogleTestSubstitution", func() {
+	It("adheres to the Substitution interface", func() {
+		var substitution targetedretries.Substitution = targetedretries.CppGoogleTestSubstitution{}
+		Expect(substitution).NotTo(BeNil())
+	})
+
+	It("works with a real file", func() {
+		substitution := targetedretries.CppGoogleTestSubstitution{}
+		compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+		Expect(compileErr).NotTo(HaveOccurred())
+
+		err := substitution.ValidateTemplate(compiledTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		fixture, err := os.Open("../../test/fixtures/googletest.json")
+		Expect(err).ToNot(HaveOccurred())
+
+		testResults, err := parsing.CppGoogleTestParser{}.Parse(fixture)
+		Expect(err).ToNot(HaveOccurred())
+
+		substitutions, err := substitution.SubstitutionsFor(
+			compiledTemplate,
+			*testResults,
+			func(_ v1.Test) bool { return true },
+		)
+		Expect(err).NotTo(HaveOccurred())
+		cupaloy.SnapshotT(GinkgoT(), substitutions)
+	})
+
+	Describe("Example", func() {
+		It("compiles and is valid", func() {
+			substitution := targetedretries.CppGoogleTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("ValidateTemplate", func() {
+		It("is invalid for a template without placeholders", func() {
+			substitution := targetedretries.CppGoogleTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("./tests")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template with too many placeholders", func() {
+			substitution := targetedretries.CppGoogleTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate(
+				"./tests --gtest_repeat={{ repeat }} --gtest_filter='{{ filter }}'",
+			)
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template without a filter placeholder", func() {
+			substitution := targetedretries.CppGoogleTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("./tests --gtest_filter='{{ tests }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is valid for a template with only a filter placeholder", func() {
+			substitution := targetedretries.CppGoogleTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("./tests --gtest_filter='{{ filter }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("Substitutions", func() {
+		It("returns a filter of the failed tests", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("./tests --gtest_filter='{{ filter }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			testResults := v1.TestResults{
+				Tests: []v1.Test{
+					{
+						Name:    "CalculatorTest.AddsNumbers",
+						Attempt: v1.TestAttempt{Status: v1.NewFailedTestStatus(nil, nil, nil)},
+					},
+					{
+						Name:    "Primes/PrimeTest.IsPrime/1",
+						Attempt: v1.TestAttempt{Status: v1.NewTimedOutTestStatus()},
+					},
+					{
+						Name:    "CalculatorTest.SubtractsNumbers",
+						Attempt: v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()},
+					},
+					{
+						Name:    "CalculatorTest.DividesByZero",
+						Attempt: v1.TestAttempt{Status: v1.NewSkippedTestStatus(nil)},
+					},
+					{
+						Name:    "CalculatorTest.AddsNumbers",
+						Attempt: v1.TestAttempt{Status: v1.NewFailedTestStatus(nil, nil, nil)},
+					},
+				},
+			}
+
+			substitution := targetedretries.CppGoogleTestSubstitution{}
+			Expect(substitution.SubstitutionsFor(
+				compiledTemplate,
+				testResults,
+				func(_ v1.Test) bool { return true },
+			)).To(Equal(
+				[]map[string]string{
+					{"filter": "CalculatorTest.AddsNumbers:Primes/PrimeTest.IsPrime/1"},
+				},
+			))
+		})
+
+		It("filters the tests with the provided function", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("./tests --gtest_filter='{{ filter }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			testResults := v1.TestResults{
+				Tests: []v1.Test{
+					{
+						Name:    "CalculatorTest.AddsNumbers",
+						Attempt: v1.TestAttempt{Status: v1.NewFailedTestStatus(nil, nil, nil)},
+					},
+					{
+						Name:    "CalculatorTest.SubtractsNumbers",
+						Attempt: v1.TestAttempt{Status: v1.NewTimedOutTestStatus()},
+					},
+				},
+			}
+
+			substitution := targetedretries.CppGoogleTestSubstitution{}
+			substitutions, err := substitution.SubstitutionsFor(
+				compiledTemplate,
+				testResults,
+				func(test v1.Test) bool { return test.Attempt.Status.Kind == v1.TestStatusFailed },
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(substitutions).To(Equal([]map[string]string{{"filter": "CalculatorTest.AddsNumbers"}}))
+		})
+	})
+})
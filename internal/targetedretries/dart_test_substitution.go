@@ -0,0 +1,82 @@
+package targetedretries
+
+import (
+	"strings"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+type DartTestSubstitution struct{}
+
+func (s DartTestSubstitution) Example() string {
+	return "flutter test '{{ file }}' --plain-name '{{ name }}'"
+}
+
+func (s DartTestSubstitution) ValidateTemplate(compiledTemplate templating.CompiledTemplate) error {
+	keywords := compiledTemplate.Keywords()
+
+	if len(keywords) == 0 {
+		return errors.NewInputError(
+			"Retrying dart test requires a template with the 'file' and 'name' keywords; no keywords were found",
+		)
+	}
+
+	if len(keywords) != 2 {
+		return errors.NewInputError(
+			"Retrying dart test requires a template with the 'file' and 'name' keywords; these were found: %v",
+			strings.Join(keywords, ", "),
+		)
+	}
+
+	if (keywords[0] == "file" && keywords[1] == "name") || (keywords[0] == "name" && keywords[1] == "file") {
+		return nil
+	}
+
+	return errors.NewInputError(
+		"Retrying dart test requires a template with the 'file' and 'name' keywords; '%v' and '%v' were found instead",
+		keywords[0],
+		keywords[1],
+	)
+}
+
+// When passed multiple times, `--plain-name` only selects tests matching every name, so each test is retried with a
+// separate command
+func (s DartTestSubstitution) SubstitutionsFor(
+	_ templating.CompiledTemplate,
+	testResults v1.TestResults,
+	filter func(v1.Test) bool,
+) ([]map[string]string, error) {
+	substitutions := make([]map[string]string, 0)
+	testsSeenByFile := map[string]map[string]struct{}{}
+
+	for _, test := range testResults.Tests {
+		if !test.Attempt.Status.ImpliesFailure() {
+			continue
+		}
+		if !filter(test) {
+			continue
+		}
+
+		file, ok := test.Attempt.Meta["path"].(string)
+		if !ok {
+			return nil, errors.NewInputError("Unable to determine the file of %q", test.Name)
+		}
+
+		if _, ok := testsSeenByFile[file]; !ok {
+			testsSeenByFile[file] = map[string]struct{}{}
+		}
+		if _, ok := testsSeenByFile[file][test.Name]; ok {
+			continue
+		}
+
+		substitutions = append(substitutions, map[string]string{
+			"file": templating.ShellEscape(file),
+			"name": templating.ShellEscape(test.Name),
+		})
+		testsSeenByFile[file][test.Name] = struct{}{}
+	}
+
+	return substitutions, nil
+}
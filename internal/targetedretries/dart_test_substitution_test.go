@@ -0,0 +1,146 @@
+package targetedretries_test
+
+import (
+	"os"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	"github.com/rwx-research/captain-cli/internal/targetedretries"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DartTestSubstitution", func() {
+	It("adheres to the Substitution interface", func() {
+		var substitution targetedretries.Substitution = targetedretries.DartTestSubstitution{}
+		Expect(substitution).NotTo(BeNil())
+	})
+
+	It("works with a real file", func() {
+		substitution := targetedretries.DartTestSubstitution{}
+		compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+		Expect(compileErr).NotTo(HaveOccurred())
+
+		err := substitution.ValidateTemplate(compiledTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		fixture, err := os.Open("../../test/fixtures/dart_test.jsonl")
+		Expect(err).ToNot(HaveOccurred())
+
+		testResults, err := parsing.DartTestParser{}.Parse(fixture)
+		Expect(err).ToNot(HaveOccurred())
+
+		substitutions, err := substitution.SubstitutionsFor(
+			compiledTemplate,
+			*testResults,
+			func(_ v1.Test) bool { return true },
+		)
+		Expect(err).NotTo(HaveOccurred())
+		cupaloy.SnapshotT(GinkgoT(), substitutions)
+	})
+
+	Describe("Example", func() {
+		It("compiles and is valid", func() {
+			substitution := targetedretries.DartTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("ValidateTemplate", func() {
+		It("is invalid for a template without placeholders", func() {
+			substitution := targetedretries.DartTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("dart test")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template with only one placeholder", func() {
+			substitution := targetedretries.DartTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("dart test --plain-name '{{ name }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template with the wrong placeholders", func() {
+			substitution := targetedretries.DartTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("dart test '{{ file }}' --name '{{ regex }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is valid for a template with file and name placeholders", func() {
+			substitution := targetedretries.DartTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("dart test '{{ file }}' --plain-name '{{ name }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("Substitutions", func() {
+		It("returns a substitution per failed test", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("dart test '{{ file }}' --plain-name '{{ name }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			testResults := v1.TestResults{
+				Tests: []v1.Test{
+					{
+						Name: "Calculator subtracts numbers",
+						Attempt: v1.TestAttempt{
+							Meta:   map[string]any{"path": "test/calculator_test.dart"},
+							Status: v1.NewFailedTestStatus(nil, nil, nil),
+						},
+					},
+					{
+						Name: "it's formatted",
+						Attempt: v1.TestAttempt{
+							Meta:   map[string]any{"path": "test/format_test.dart"},
+							Status: v1.NewTimedOutTestStatus(),
+						},
+					},
+					{
+						Name: "Calculator adds numbers",
+						Attempt: v1.TestAttempt{
+							Meta:   map[string]any{"path": "test/calculator_test.dart"},
+							Status: v1.NewSuccessfulTestStatus(),
+						},
+					},
+					{
+						Name: "Calculator subtracts numbers",
+						Attempt: v1.TestAttempt{
+							Meta:   map[string]any{"path": "test/calculator_test.dart"},
+							Status: v1.NewFailedTestStatus(nil, nil, nil),
+						},
+					},
+				},
+			}
+
+			substitution := targetedretries.DartTestSubstitution{}
+			Expect(substitution.SubstitutionsFor(
+				compiledTemplate,
+				testResults,
+				func(_ v1.Test) bool { return true },
+			)).To(Equal(
+				[]map[string]string{
+					{"file": "test/calculator_test.dart", "name": "Calculator subtracts numbers"},
+					{"file": "test/format_test.dart", "name": `it'"'"'s formatted`},
+				},
+			))
+		})
+	})
+})
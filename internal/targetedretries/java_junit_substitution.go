@@ -0,0 +1,142 @@
+package targetedretries
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// JavaJUnitSubstitution supports both Maven (`-Dtest='{{ tests }}'`) and Gradle (`{{ gradle_tests }}`) retries
+type JavaJUnitSubstitution struct{}
+
+// Matches `method`, `method()` and `method(int, String)[1]`, but not display names like `[1] 1, 2` or `adds numbers`
+var javaJUnitMethodRegexp = regexp.MustCompile(`^([\p{L}_$][\p{L}\p{N}_$]*)(?:\(.*\))?(?:\[\d+\])?$`)
+
+func (s JavaJUnitSubstitution) Example() string {
+	return "mvn test -Dsurefire.failIfNoSpecifiedTests=false -Dtest='{{ tests }}'"
+}
+
+func (s JavaJUnitSubstitution) ValidateTemplate(compiledTemplate templating.CompiledTemplate) error {
+	keywords := compiledTemplate.Keywords()
+
+	if len(keywords) == 0 {
+		return errors.NewInputError(
+			"Retrying JUnit requires a template with either the 'tests' (Maven) or the 'gradle_tests' (Gradle) " +
+				"keyword; no keywords were found",
+		)
+	}
+
+	if len(keywords) > 1 {
+		return errors.NewInputError(
+			"Retrying JUnit requires a template with either the 'tests' (Maven) or the 'gradle_tests' (Gradle) "+
+				"keyword; these were found: %v",
+			strings.Join(keywords, ", "),
+		)
+	}
+
+	if keywords[0] != "tests" && keywords[0] != "gradle_tests" {
+		return errors.NewInputError(
+			"Retrying JUnit requires a template with either the 'tests' (Maven) or the 'gradle_tests' (Gradle) "+
+				"keyword; '%v' was found instead",
+			keywords[0],
+		)
+	}
+
+	return nil
+}
+
+func (s JavaJUnitSubstitution) SubstitutionsFor(
+	compiledTemplate templating.CompiledTemplate,
+	testResults v1.TestResults,
+	filter func(v1.Test) bool,
+) ([]map[string]string, error) {
+	classNames := make([]string, 0)
+	methodsByClassName := map[string][]string{}
+	methodsSeenByClassName := map[string]map[string]struct{}{}
+	entireClassNames := map[string]struct{}{}
+
+	for _, test := range testResults.Tests {
+		if !test.Attempt.Status.ImpliesFailure() {
+			continue
+		}
+		if !filter(test) {
+			continue
+		}
+
+		className, ok := test.Attempt.Meta["classname"].(string)
+		if !ok || len(test.Lineage) == 0 {
+			return nil, errors.NewInputError("Unable to determine the class of %q", test.Name)
+		}
+
+		if _, ok := methodsSeenByClassName[className]; !ok {
+			classNames = append(classNames, className)
+			methodsSeenByClassName[className] = map[string]struct{}{}
+		}
+
+		// Display names and parameterized invocations cannot be mapped to a method, so we retry the entire class
+		match := javaJUnitMethodRegexp.FindStringSubmatch(test.Lineage[len(test.Lineage)-1])
+		if match == nil {
+			entireClassNames[className] = struct{}{}
+			continue
+		}
+
+		method := match[1]
+		if _, ok := methodsSeenByClassName[className][method]; ok {
+			continue
+		}
+
+		methodsByClassName[className] = append(methodsByClassName[className], method)
+		methodsSeenByClassName[className][method] = struct{}{}
+	}
+
+	if len(classNames) == 0 {
+		return []map[string]string{}, nil
+	}
+
+	if s.usesGradle(compiledTemplate) {
+		filters := make([]string, 0)
+		for _, className := range classNames {
+			if _, ok := entireClassNames[className]; ok {
+				filters = append(filters, fmt.Sprintf("--tests '%v'", templating.ShellEscape(className)))
+				continue
+			}
+
+			for _, method := range methodsByClassName[className] {
+				filters = append(filters, fmt.Sprintf("--tests '%v.%v'", templating.ShellEscape(className), method))
+			}
+		}
+
+		return []map[string]string{{"gradle_tests": strings.Join(filters, " ")}}, nil
+	}
+
+	// https://maven.apache.org/surefire/maven-surefire-plugin/examples/single-test.html
+	filters := make([]string, 0)
+	for _, className := range classNames {
+		if _, ok := entireClassNames[className]; ok {
+			filters = append(filters, templating.ShellEscape(className))
+			continue
+		}
+
+		filters = append(filters, fmt.Sprintf(
+			"%v#%v",
+			templating.ShellEscape(className),
+			strings.Join(methodsByClassName[className], "+"),
+		))
+	}
+
+	return []map[string]string{{"tests": strings.Join(filters, ",")}}, nil
+}
+
+func (s JavaJUnitSubstitution) usesGradle(compiledTemplate templating.CompiledTemplate) bool {
+	for _, keyword := range compiledTemplate.Keywords() {
+		if keyword == "gradle_tests" {
+			return true
+		}
+	}
+
+	return false
+}
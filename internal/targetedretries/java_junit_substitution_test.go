@@ -0,0 +1,193 @@
+package targetedretries_test
+
+import (
+	"os"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	"github.com/rwx-research/captain-cli/internal/targetedretries"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JavaJUnitSubstitution", func() {
+	newTest := func(className string, name string, status v1.TestStatus) v1.Test {
+		return v1.Test{
+			Name:    className + " " + name,
+			Lineage: []string{className, name},
+			Attempt: v1.TestAttempt{
+				Meta:   map[string]any{"classname": className},
+				Status: status,
+			},
+		}
+	}
+
+	It("adheres to the Substitution interface", func() {
+		var substitution targetedretries.Substitution = targetedretries.JavaJUnitSubstitution{}
+		Expect(substitution).NotTo(BeNil())
+	})
+
+	It("works with a real file", func() {
+		substitution := targetedretries.JavaJUnitSubstitution{}
+		compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+		Expect(compileErr).NotTo(HaveOccurred())
+
+		err := substitution.ValidateTemplate(compiledTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		fixture, err := os.Open("../../test/fixtures/surefire.xml")
+		Expect(err).ToNot(HaveOccurred())
+
+		testResults, err := parsing.JavaJUnitParser{}.Parse(fixture)
+		Expect(err).ToNot(HaveOccurred())
+
+		substitutions, err := substitution.SubstitutionsFor(
+			compiledTemplate,
+			*testResults,
+			func(_ v1.Test) bool { return true },
+		)
+		Expect(err).NotTo(HaveOccurred())
+		cupaloy.SnapshotT(GinkgoT(), substitutions)
+	})
+
+	Describe("Example", func() {
+		It("compiles and is valid", func() {
+			substitution := targetedretries.JavaJUnitSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("ValidateTemplate", func() {
+		It("is invalid for a template without placeholders", func() {
+			substitution := targetedretries.JavaJUnitSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("mvn test")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template with both placeholders", func() {
+			substitution := targetedretries.JavaJUnitSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate(
+				"mvn test -Dtest='{{ tests }}' {{ gradle_tests }}",
+			)
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template with an unknown placeholder", func() {
+			substitution := targetedretries.JavaJUnitSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("mvn test -Dtest='{{ test }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is valid for a Maven template", func() {
+			substitution := targetedretries.JavaJUnitSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("mvn test -Dtest='{{ tests }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("is valid for a Gradle template", func() {
+			substitution := targetedretries.JavaJUnitSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("./gradlew test {{ gradle_tests }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("Substitutions", func() {
+		testResults := v1.TestResults{
+			Tests: []v1.Test{
+				newTest("com.example.FooTest", "one()", v1.NewFailedTestStatus(nil, nil, nil)),
+				newTest("com.example.BarTest", "two", v1.NewFailedTestStatus(nil, nil, nil)),
+				newTest("com.example.FooTest", "three(int)[2]", v1.NewFailedTestStatus(nil, nil, nil)),
+				newTest("com.example.FooTest", "three(int)[3]", v1.NewFailedTestStatus(nil, nil, nil)),
+				newTest("com.example.FooTest", "four()", v1.NewSuccessfulTestStatus()),
+				newTest("com.example.BazTest", "[1] it's parameterized", v1.NewFailedTestStatus(nil, nil, nil)),
+				newTest("com.example.BazTest", "five()", v1.NewFailedTestStatus(nil, nil, nil)),
+			},
+		}
+
+		It("returns the failed tests for Maven", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("mvn test -Dtest='{{ tests }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			substitution := targetedretries.JavaJUnitSubstitution{}
+			Expect(substitution.SubstitutionsFor(
+				compiledTemplate,
+				testResults,
+				func(_ v1.Test) bool { return true },
+			)).To(Equal(
+				[]map[string]string{
+					{"tests": "com.example.FooTest#one+three,com.example.BarTest#two,com.example.BazTest"},
+				},
+			))
+		})
+
+		It("returns the failed tests for Gradle", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("./gradlew test {{ gradle_tests }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			substitution := targetedretries.JavaJUnitSubstitution{}
+			Expect(substitution.SubstitutionsFor(
+				compiledTemplate,
+				testResults,
+				func(_ v1.Test) bool { return true },
+			)).To(Equal(
+				[]map[string]string{
+					{
+						"gradle_tests": "--tests 'com.example.FooTest.one' --tests 'com.example.FooTest.three' " +
+							"--tests 'com.example.BarTest.two' --tests 'com.example.BazTest'",
+					},
+				},
+			))
+		})
+
+		It("filters the tests with the provided function", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("mvn test -Dtest='{{ tests }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			substitution := targetedretries.JavaJUnitSubstitution{}
+			substitutions, err := substitution.SubstitutionsFor(
+				compiledTemplate,
+				testResults,
+				func(test v1.Test) bool { return test.Attempt.Meta["classname"] == "com.example.BarTest" },
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(substitutions).To(Equal([]map[string]string{{"tests": "com.example.BarTest#two"}}))
+		})
+
+		It("returns no substitutions when nothing failed", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("mvn test -Dtest='{{ tests }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			substitution := targetedretries.JavaJUnitSubstitution{}
+			substitutions, err := substitution.SubstitutionsFor(
+				compiledTemplate,
+				v1.TestResults{Tests: []v1.Test{newTest("com.example.FooTest", "one()", v1.NewSuccessfulTestStatus())}},
+				func(_ v1.Test) bool { return true },
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(substitutions).To(Equal([]map[string]string{}))
+		})
+	})
+})
@@ -0,0 +1,85 @@
+package targetedretries
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+type RustNextestSubstitution struct{}
+
+// https://nexte.st/docs/filtersets/reference/
+var rustNextestFiltersetEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `)`, `\)`)
+
+func (s RustNextestSubstitution) Example() string {
+	return "cargo nextest run --no-fail-fast -E '{{ filter }}'"
+}
+
+func (s RustNextestSubstitution) ValidateTemplate(compiledTemplate templating.CompiledTemplate) error {
+	keywords := compiledTemplate.Keywords()
+
+	if len(keywords) == 0 {
+		return errors.NewInputError(
+			"Retrying nextest requires a template with the 'filter' keyword; no keywords were found",
+		)
+	}
+
+	if len(keywords) > 1 {
+		return errors.NewInputError(
+			"Retrying nextest requires a template with only the 'filter' keyword; these were found: %v",
+			strings.Join(keywords, ", "),
+		)
+	}
+
+	if keywords[0] != "filter" {
+		return errors.NewInputError(
+			"Retrying nextest requires a template with only the 'filter' keyword; '%v' was found instead",
+			keywords[0],
+		)
+	}
+
+	return nil
+}
+
+func (s RustNextestSubstitution) SubstitutionsFor(
+	_ templating.CompiledTemplate,
+	testResults v1.TestResults,
+	filter func(v1.Test) bool,
+) ([]map[string]string, error) {
+	expressions := make([]string, 0)
+	expressionsSeen := map[string]struct{}{}
+
+	for _, test := range testResults.Tests {
+		if !test.Attempt.Status.ImpliesFailure() {
+			continue
+		}
+		if !filter(test) {
+			continue
+		}
+
+		expression := fmt.Sprintf("test(=%v)", rustNextestFiltersetEscaper.Replace(test.Name))
+		if binaryID, ok := test.Attempt.Meta["binary_id"].(string); ok {
+			expression = fmt.Sprintf(
+				"(binary_id(=%v) & %v)",
+				rustNextestFiltersetEscaper.Replace(binaryID),
+				expression,
+			)
+		}
+
+		if _, ok := expressionsSeen[expression]; ok {
+			continue
+		}
+
+		expressions = append(expressions, templating.ShellEscape(expression))
+		expressionsSeen[expression] = struct{}{}
+	}
+
+	if len(expressions) > 0 {
+		return []map[string]string{{"filter": strings.Join(expressions, " | ")}}, nil
+	}
+
+	return []map[string]string{}, nil
+}
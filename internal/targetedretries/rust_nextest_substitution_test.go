@@ -0,0 +1,190 @@
+package targetedretries_test
+
+import (
+	"os"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	"github.com/rwx-research/captain-cli/internal/targetedretries"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RustNextestSubstitution", func() {
+	It("adheres to the Substitution interface", func() {
+		var substitution targetedretries.Substitution = targetedretries.RustNextestSubstitution{}
+		Expect(substitution).NotTo(BeNil())
+	})
+
+	It("works with a real file", func() {
+		substitution := targetedretries.RustNextestSubstitution{}
+		compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+		Expect(compileErr).NotTo(HaveOccurred())
+
+		err := substitution.ValidateTemplate(compiledTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		fixture, err := os.Open("../../test/fixtures/nextest.jsonl")
+		Expect(err).ToNot(HaveOccurred())
+
+		testResults, err := parsing.RustNextestParser{}.Parse(fixture)
+		Expect(err).ToNot(HaveOccurred())
+
+		substitutions, err := substitution.SubstitutionsFor(
+			compiledTemplate,
+			*testResults,
+			func(_ v1.Test) bool { return true },
+		)
+		Expect(err).NotTo(HaveOccurred())
+		cupaloy.SnapshotT(GinkgoT(), substitutions)
+	})
+
+	Describe("Example", func() {
+		It("compiles and is valid", func() {
+			substitution := targetedretries.RustNextestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("ValidateTemplate", func() {
+		It("is invalid for a template without placeholders", func() {
+			substitution := targetedretries.RustNextestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("cargo nextest run")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template with too many placeholders", func() {
+			substitution := targetedretries.RustNextestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate(
+				"cargo nextest run -p {{ package }} -E '{{ filter }}'",
+			)
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template without a filter placeholder", func() {
+			substitution := targetedretries.RustNextestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("cargo nextest run {{ tests }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is valid for a template with only a filter placeholder", func() {
+			substitution := targetedretries.RustNextestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("cargo nextest run -E '{{ filter }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("Substitutions", func() {
+		It("returns a filterset of the failed tests", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("cargo nextest run -E '{{ filter }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			testResults := v1.TestResults{
+				Tests: []v1.Test{
+					{
+						Name: "tests::one",
+						Attempt: v1.TestAttempt{
+							Meta:   map[string]any{"binary_id": "my-crate"},
+							Status: v1.NewFailedTestStatus(nil, nil, nil),
+						},
+					},
+					{
+						Name:    "tests::two",
+						Attempt: v1.TestAttempt{Status: v1.NewTimedOutTestStatus()},
+					},
+					{
+						Name:    "tests::three",
+						Attempt: v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()},
+					},
+					{
+						Name:    "tests::four",
+						Attempt: v1.TestAttempt{Status: v1.NewSkippedTestStatus(nil)},
+					},
+				},
+			}
+
+			substitution := targetedretries.RustNextestSubstitution{}
+			Expect(substitution.SubstitutionsFor(
+				compiledTemplate,
+				testResults,
+				func(_ v1.Test) bool { return true },
+			)).To(Equal(
+				[]map[string]string{
+					{"filter": "(binary_id(=my-crate) & test(=tests::one)) | test(=tests::two)"},
+				},
+			))
+		})
+
+		It("escapes the filterset and the shell", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("cargo nextest run -E '{{ filter }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			testResults := v1.TestResults{
+				Tests: []v1.Test{
+					{
+						Name:    "tests::it's_(a,b)",
+						Attempt: v1.TestAttempt{Status: v1.NewFailedTestStatus(nil, nil, nil)},
+					},
+				},
+			}
+
+			substitution := targetedretries.RustNextestSubstitution{}
+			Expect(substitution.SubstitutionsFor(
+				compiledTemplate,
+				testResults,
+				func(_ v1.Test) bool { return true },
+			)).To(Equal(
+				[]map[string]string{
+					{"filter": `test(=tests::it'"'"'s_(a\,b\))`},
+				},
+			))
+		})
+
+		It("filters the tests with the provided function", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("cargo nextest run -E '{{ filter }}'")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			testResults := v1.TestResults{
+				Tests: []v1.Test{
+					{
+						Name:    "tests::one",
+						Attempt: v1.TestAttempt{Status: v1.NewFailedTestStatus(nil, nil, nil)},
+					},
+					{
+						Name:    "tests::two",
+						Attempt: v1.TestAttempt{Status: v1.NewTimedOutTestStatus()},
+					},
+				},
+			}
+
+			substitution := targetedretries.RustNextestSubstitution{}
+			substitutions, err := substitution.SubstitutionsFor(
+				compiledTemplate,
+				testResults,
+				func(test v1.Test) bool { return test.Attempt.Status.Kind == v1.TestStatusFailed },
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(substitutions).To(Equal([]map[string]string{{"filter": "test(=tests::one)"}}))
+		})
+	})
+})
@@ -16,10 +16,14 @@ type Substitution interface {
 }
 
 var SubstitutionsByFramework = map[v1.Framework]Substitution{
+	v1.BazelFramework:                new(BazelSubstitution),
+	v1.CppGoogleTestFramework:        new(CppGoogleTestSubstitution),
+	v1.DartTestFramework:             new(DartTestSubstitution),
 	v1.DotNetxUnitFramework:          new(DotNetxUnitSubstitution),
 	v1.ElixirExUnitFramework:         new(ElixirExUnitSubstitution),
 	v1.GoGinkgoFramework:             new(GoGinkgoSubstitution),
 	v1.GoTestFramework:               new(GoTestSubstitution),
+	v1.JavaJUnitFramework:            new(JavaJUnitSubstitution),
 	v1.JavaScriptCucumberFramework:   new(JavaScriptCucumberSubstitution),
 	v1.JavaScriptCypressFramework:    new(JavaScriptCypressSubstitution),
 	v1.JavaScriptJestFramework:       new(JavaScriptJestSubstitution),
@@ -32,4 +36,6 @@ var SubstitutionsByFramework = map[v1.Framework]Substitution{
 	v1.RubyCucumberFramework:         new(RubyCucumberSubstitution),
 	v1.RubyMinitestFramework:         new(RubyMinitestSubstitution),
 	v1.RubyRSpecFramework:            new(RubyRSpecSubstitution),
+	v1.RustNextestFramework:          new(RustNextestSubstitution),
+	v1.SwiftXCTestFramework:          new(SwiftXCTestSubstitution),
 }
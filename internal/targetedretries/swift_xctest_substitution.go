@@ -0,0 +1,85 @@
+package targetedretries
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+type SwiftXCTestSubstitution struct{}
+
+func (s SwiftXCTestSubstitution) Example() string {
+	return "xcodebuild test -scheme MyApp -destination 'platform=iOS Simulator,name=iPhone 15' {{ tests }}"
+}
+
+func (s SwiftXCTestSubstitution) ValidateTemplate(compiledTemplate templating.CompiledTemplate) error {
+	keywords := compiledTemplate.Keywords()
+
+	if len(keywords) == 0 {
+		return errors.NewInputError(
+			"Retrying XCTest requires a template with the 'tests' keyword; no keywords were found",
+		)
+	}
+
+	if len(keywords) > 1 {
+		return errors.NewInputError(
+			"Retrying XCTest requires a template with only the 'tests' keyword; these were found: %v",
+			strings.Join(keywords, ", "),
+		)
+	}
+
+	if keywords[0] != "tests" {
+		return errors.NewInputError(
+			"Retrying XCTest requires a template with only the 'tests' keyword; '%v' was found instead",
+			keywords[0],
+		)
+	}
+
+	return nil
+}
+
+func (s SwiftXCTestSubstitution) SubstitutionsFor(
+	_ templating.CompiledTemplate,
+	testResults v1.TestResults,
+	filter func(v1.Test) bool,
+) ([]map[string]string, error) {
+	arguments := make([]string, 0)
+	argumentsSeen := map[string]struct{}{}
+
+	for _, test := range testResults.Tests {
+		if !test.Attempt.Status.ImpliesFailure() {
+			continue
+		}
+		if !filter(test) {
+			continue
+		}
+
+		target, ok := test.Attempt.Meta["target"].(string)
+		if !ok {
+			return nil, errors.NewInputError("Unable to determine the test target of %q", test.Name)
+		}
+
+		identifier, ok := test.Attempt.Meta["identifier"].(string)
+		if !ok {
+			identifier = test.Name
+		}
+
+		// -only-testing:Target/Class/method, the identifier is `Class/method()`
+		argument := fmt.Sprintf("-only-testing:%v/%v", target, strings.TrimSuffix(identifier, "()"))
+		if _, ok := argumentsSeen[argument]; ok {
+			continue
+		}
+
+		arguments = append(arguments, fmt.Sprintf("'%v'", templating.ShellEscape(argument)))
+		argumentsSeen[argument] = struct{}{}
+	}
+
+	if len(arguments) > 0 {
+		return []map[string]string{{"tests": strings.Join(arguments, " ")}}, nil
+	}
+
+	return []map[string]string{}, nil
+}
@@ -0,0 +1,173 @@
+package targetedretries_test
+
+import (
+	"os"
+
+	"github.com/bradleyjkemp/cupaloy"
+
+	"github.com/rwx-research/captain-cli/internal/parsing"
+	"github.com/rwx-research/captain-cli/internal/targetedretries"
+	"github.com/rwx-research/captain-cli/internal/templating"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SwiftXCTestSubstitution", func() {
+	It("adheres to the Substitution interface", func() {
+		var substitution targetedretries.Substitution = targetedretries.SwiftXCTestSubstitution{}
+		Expect(substitution).NotTo(BeNil())
+	})
+
+	It("works with a real file", func() {
+		substitution := targetedretries.SwiftXCTestSubstitution{}
+		compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+		Expect(compileErr).NotTo(HaveOccurred())
+
+		err := substitution.ValidateTemplate(compiledTemplate)
+		Expect(err).NotTo(HaveOccurred())
+
+		fixture, err := os.Open("../../test/fixtures/xcresult.json")
+		Expect(err).ToNot(HaveOccurred())
+
+		testResults, err := parsing.SwiftXCTestParser{}.Parse(fixture)
+		Expect(err).ToNot(HaveOccurred())
+
+		substitutions, err := substitution.SubstitutionsFor(
+			compiledTemplate,
+			*testResults,
+			func(_ v1.Test) bool { return true },
+		)
+		Expect(err).NotTo(HaveOccurred())
+		cupaloy.SnapshotT(GinkgoT(), substitutions)
+	})
+
+	Describe("Example", func() {
+		It("compiles and is valid", func() {
+			substitution := targetedretries.SwiftXCTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate(substitution.Example())
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("ValidateTemplate", func() {
+		It("is invalid for a template without placeholders", func() {
+			substitution := targetedretries.SwiftXCTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("xcodebuild test")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template with too many placeholders", func() {
+			substitution := targetedretries.SwiftXCTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate(
+				"xcodebuild test -scheme {{ scheme }} {{ tests }}",
+			)
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is invalid for a template without a tests placeholder", func() {
+			substitution := targetedretries.SwiftXCTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("xcodebuild test {{ scheme }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("is valid for a template with only a tests placeholder", func() {
+			substitution := targetedretries.SwiftXCTestSubstitution{}
+			compiledTemplate, compileErr := templating.CompileTemplate("xcodebuild test {{ tests }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			err := substitution.ValidateTemplate(compiledTemplate)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("Substitutions", func() {
+		It("returns -only-testing arguments for the failed tests", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("xcodebuild test {{ tests }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			testResults := v1.TestResults{
+				Tests: []v1.Test{
+					{
+						Name: "CalculatorTests/testSubtraction()",
+						Attempt: v1.TestAttempt{
+							Meta: map[string]any{
+								"target":     "CalculatorTests",
+								"identifier": "CalculatorTests/testSubtraction()",
+							},
+							Status: v1.NewFailedTestStatus(nil, nil, nil),
+						},
+					},
+					{
+						Name: "LaunchTests/testLaunch()",
+						Attempt: v1.TestAttempt{
+							Meta: map[string]any{
+								"target":     "CalculatorUITests",
+								"identifier": "LaunchTests/testLaunch()",
+							},
+							Status: v1.NewTimedOutTestStatus(),
+						},
+					},
+					{
+						Name: "CalculatorTests/testAddition()",
+						Attempt: v1.TestAttempt{
+							Meta: map[string]any{
+								"target":     "CalculatorTests",
+								"identifier": "CalculatorTests/testAddition()",
+							},
+							Status: v1.NewSuccessfulTestStatus(),
+						},
+					},
+				},
+			}
+
+			substitution := targetedretries.SwiftXCTestSubstitution{}
+			Expect(substitution.SubstitutionsFor(
+				compiledTemplate,
+				testResults,
+				func(_ v1.Test) bool { return true },
+			)).To(Equal(
+				[]map[string]string{
+					{
+						"tests": "'-only-testing:CalculatorTests/CalculatorTests/testSubtraction' " +
+							"'-only-testing:CalculatorUITests/LaunchTests/testLaunch'",
+					},
+				},
+			))
+		})
+
+		It("errors when the test target is unknown", func() {
+			compiledTemplate, compileErr := templating.CompileTemplate("xcodebuild test {{ tests }}")
+			Expect(compileErr).NotTo(HaveOccurred())
+
+			substitution := targetedretries.SwiftXCTestSubstitution{}
+			_, err := substitution.SubstitutionsFor(
+				compiledTemplate,
+				v1.TestResults{
+					Tests: []v1.Test{
+						{
+							Name:    "CalculatorTests/testSubtraction()",
+							Attempt: v1.TestAttempt{Status: v1.NewFailedTestStatus(nil, nil, nil)},
+						},
+					},
+				},
+				func(_ v1.Test) bool { return true },
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Unable to determine the test target"))
+		})
+	})
+})
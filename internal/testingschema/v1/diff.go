@@ -0,0 +1,169 @@
+package v1
+
+import (
+	"time"
+)
+
+type DiffConfig struct {
+	// Tests are matched with `Identify` (leniently) if set, with `Matches` otherwise
+	IdentityComponents []string
+
+	// A test is significantly slower if it took at least `SlowdownFactor` times as long as before, and at least
+	// `SlowdownThreshold` longer
+	SlowdownFactor    float64
+	SlowdownThreshold time.Duration
+}
+
+// TestDiff is a test that's part of both the base and the head test results
+type TestDiff struct {
+	Base Test
+	Head Test
+}
+
+// TestResultsDiff holds how the tests of a single framework changed between two runs
+type TestResultsDiff struct {
+	Framework    Framework
+	BaseTests    int
+	HeadTests    int
+	NewlyFailing []TestDiff
+	NewlyPassing []TestDiff
+	NewlySkipped []TestDiff
+	Slower       []TestDiff
+	Added        []Test
+	Removed      []Test
+}
+
+func (d TestResultsDiff) Changes() int {
+	return len(d.NewlyFailing) + len(d.NewlyPassing) + len(d.NewlySkipped) + len(d.Slower) + len(d.Added) +
+		len(d.Removed)
+}
+
+// Diff compares the test results of two runs, framework by framework. Both sides are expected to hold at most one set
+// of test results per framework, as returned by parsing. Frameworks are ordered by their appearance in the head test
+// results, followed by the ones that only appear in the base test results.
+func Diff(base []TestResults, head []TestResults, cfg DiffConfig) []TestResultsDiff {
+	baseByFramework := make(map[string]TestResults, len(base))
+	for _, testResults := range base {
+		baseByFramework[testResults.Framework.String()] = testResults
+	}
+
+	diffs := make([]TestResultsDiff, 0, len(head))
+	headFrameworks := make(map[string]struct{}, len(head))
+	for _, testResults := range head {
+		framework := testResults.Framework.String()
+		headFrameworks[framework] = struct{}{}
+
+		baseTestResults, ok := baseByFramework[framework]
+		if !ok {
+			baseTestResults = TestResults{Framework: testResults.Framework}
+		}
+
+		diffs = append(diffs, diffTestResults(baseTestResults, testResults, cfg))
+	}
+
+	for _, testResults := range base {
+		if _, ok := headFrameworks[testResults.Framework.String()]; ok {
+			continue
+		}
+
+		diffs = append(diffs, diffTestResults(testResults, TestResults{Framework: testResults.Framework}, cfg))
+	}
+
+	return diffs
+}
+
+func diffTestResults(base TestResults, head TestResults, cfg DiffConfig) TestResultsDiff {
+	diff := TestResultsDiff{
+		Framework:    head.Framework,
+		BaseTests:    len(base.Tests),
+		HeadTests:    len(head.Tests),
+		NewlyFailing: make([]TestDiff, 0),
+		NewlyPassing: make([]TestDiff, 0),
+		NewlySkipped: make([]TestDiff, 0),
+		Slower:       make([]TestDiff, 0),
+		Added:        make([]Test, 0),
+		Removed:      make([]Test, 0),
+	}
+
+	// The first of several tests with the same identity wins, the same as when merging
+	baseIndexesByKey := make(map[string]int, len(base.Tests))
+	for i, test := range base.Tests {
+		key := cfg.keyFor(test)
+		if _, ok := baseIndexesByKey[key]; !ok {
+			baseIndexesByKey[key] = i
+		}
+	}
+
+	matchedBaseIndexes := make(map[int]struct{}, len(base.Tests))
+	for _, headTest := range head.Tests {
+		i, ok := baseIndexesByKey[cfg.keyFor(headTest)]
+		if !ok {
+			diff.Added = append(diff.Added, headTest)
+			continue
+		}
+		matchedBaseIndexes[i] = struct{}{}
+
+		testDiff := TestDiff{Base: base.Tests[i], Head: headTest}
+		baseStatus := testDiff.Base.Attempt.Status
+		headStatus := testDiff.Head.Attempt.Status
+
+		switch {
+		case diffFailing(headStatus) && !diffFailing(baseStatus):
+			diff.NewlyFailing = append(diff.NewlyFailing, testDiff)
+		case headStatus.Kind == TestStatusSuccessful && baseStatus.Kind != TestStatusSuccessful:
+			diff.NewlyPassing = append(diff.NewlyPassing, testDiff)
+		case headStatus.ImpliesSkipped() && !baseStatus.ImpliesSkipped():
+			diff.NewlySkipped = append(diff.NewlySkipped, testDiff)
+		case headStatus.Kind == TestStatusSuccessful && cfg.isSignificantlySlower(testDiff):
+			diff.Slower = append(diff.Slower, testDiff)
+		}
+	}
+
+	for i, baseTest := range base.Tests {
+		if _, ok := matchedBaseIndexes[i]; ok {
+			continue
+		}
+
+		// Only report one of several tests with the same identity
+		if baseIndexesByKey[cfg.keyFor(baseTest)] == i {
+			diff.Removed = append(diff.Removed, baseTest)
+		}
+	}
+
+	return diff
+}
+
+// Quarantined tests still failed, they just don't fail the build
+func diffFailing(status TestStatus) bool {
+	return status.ImpliesFailure() || status.Kind == TestStatusQuarantined
+}
+
+func (cfg DiffConfig) keyFor(test Test) string {
+	if len(cfg.IdentityComponents) == 0 {
+		return test.identityKey()
+	}
+
+	// Identifying leniently never errors
+	key, _ := test.Identify(cfg.IdentityComponents, false)
+	return key
+}
+
+// isSignificantlySlower compares the durations of successful attempts; failures tend to take a different amount of
+// time anyway.
+func (cfg DiffConfig) isSignificantlySlower(testDiff TestDiff) bool {
+	if testDiff.Base.Attempt.Status.Kind != TestStatusSuccessful {
+		return false
+	}
+
+	baseDuration := testDiff.Base.Attempt.Duration
+	headDuration := testDiff.Head.Attempt.Duration
+	if baseDuration == nil || headDuration == nil || *baseDuration <= 0 {
+		return false
+	}
+
+	if *headDuration-*baseDuration < cfg.SlowdownThreshold {
+		return false
+	}
+
+	return float64(*headDuration)/float64(*baseDuration) >= cfg.SlowdownFactor
+}
@@ -0,0 +1,121 @@
+package v1_test
+
+import (
+	"time"
+
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	test := func(name string, status v1.TestStatus, duration time.Duration) v1.Test {
+		return v1.Test{
+			Name:     name,
+			Location: &v1.Location{File: "spec/" + name + "_spec.rb"},
+			Attempt:  v1.TestAttempt{Status: status, Duration: &duration},
+		}
+	}
+
+	names := func(tests []v1.Test) []string {
+		names := make([]string, len(tests))
+		for i, test := range tests {
+			names[i] = test.Name
+		}
+		return names
+	}
+
+	headNames := func(testDiffs []v1.TestDiff) []string {
+		names := make([]string, len(testDiffs))
+		for i, testDiff := range testDiffs {
+			names[i] = testDiff.Head.Name
+		}
+		return names
+	}
+
+	cfg := v1.DiffConfig{SlowdownFactor: 2, SlowdownThreshold: time.Second}
+
+	It("reports how the tests changed", func() {
+		base := *v1.NewTestResults(v1.RubyRSpecFramework, []v1.Test{
+			test("breaks", v1.NewSuccessfulTestStatus(), time.Second),
+			test("gets fixed", v1.NewFailedTestStatus(nil, nil, nil), time.Second),
+			test("gets skipped", v1.NewSuccessfulTestStatus(), time.Second),
+			test("gets quarantined", v1.NewSuccessfulTestStatus(), time.Second),
+			test("slows down", v1.NewSuccessfulTestStatus(), time.Second),
+			test("slows down a bit", v1.NewSuccessfulTestStatus(), 10*time.Second),
+			test("stays failing", v1.NewFailedTestStatus(nil, nil, nil), time.Second),
+			test("gets removed", v1.NewSuccessfulTestStatus(), time.Second),
+		}, nil)
+		head := *v1.NewTestResults(v1.RubyRSpecFramework, []v1.Test{
+			test("gets added", v1.NewSuccessfulTestStatus(), time.Second),
+			test("breaks", v1.NewFailedTestStatus(nil, nil, nil), time.Second),
+			test("gets fixed", v1.NewSuccessfulTestStatus(), time.Second),
+			test("gets skipped", v1.NewSkippedTestStatus(nil), 0),
+			test("gets quarantined", v1.NewQuarantinedTestStatus(v1.NewFailedTestStatus(nil, nil, nil)), time.Second),
+			test("slows down", v1.NewSuccessfulTestStatus(), 3*time.Second),
+			test("slows down a bit", v1.NewSuccessfulTestStatus(), 15*time.Second),
+			test("stays failing", v1.NewFailedTestStatus(nil, nil, nil), 5*time.Second),
+		}, nil)
+
+		diffs := v1.Diff([]v1.TestResults{base}, []v1.TestResults{head}, cfg)
+		Expect(diffs).To(HaveLen(1))
+
+		diff := diffs[0]
+		Expect(diff.Framework).To(Equal(v1.RubyRSpecFramework))
+		Expect(diff.BaseTests).To(Equal(8))
+		Expect(diff.HeadTests).To(Equal(8))
+		Expect(headNames(diff.NewlyFailing)).To(Equal([]string{"breaks", "gets quarantined"}))
+		Expect(headNames(diff.NewlyPassing)).To(Equal([]string{"gets fixed"}))
+		Expect(headNames(diff.NewlySkipped)).To(Equal([]string{"gets skipped"}))
+		Expect(headNames(diff.Slower)).To(Equal([]string{"slows down"}))
+		Expect(names(diff.Added)).To(Equal([]string{"gets added"}))
+		Expect(names(diff.Removed)).To(Equal([]string{"gets removed"}))
+		Expect(diff.Changes()).To(Equal(7))
+	})
+
+	It("matches tests by their identity components if given", func() {
+		moved := test("moves", v1.NewFailedTestStatus(nil, nil, nil), time.Second)
+		moved.Location = &v1.Location{File: "spec/elsewhere_spec.rb"}
+
+		base := *v1.NewTestResults(v1.RubyRSpecFramework, []v1.Test{
+			test("moves", v1.NewSuccessfulTestStatus(), time.Second),
+		}, nil)
+		head := *v1.NewTestResults(v1.RubyRSpecFramework, []v1.Test{moved}, nil)
+
+		diff := v1.Diff([]v1.TestResults{base}, []v1.TestResults{head}, cfg)[0]
+		Expect(names(diff.Added)).To(Equal([]string{"moves"}))
+		Expect(names(diff.Removed)).To(Equal([]string{"moves"}))
+		Expect(diff.NewlyFailing).To(BeEmpty())
+
+		identifiedCfg := cfg
+		identifiedCfg.IdentityComponents = []string{"description"}
+		diff = v1.Diff([]v1.TestResults{base}, []v1.TestResults{head}, identifiedCfg)[0]
+		Expect(diff.Added).To(BeEmpty())
+		Expect(diff.Removed).To(BeEmpty())
+		Expect(headNames(diff.NewlyFailing)).To(Equal([]string{"moves"}))
+	})
+
+	It("diffs every framework on its own", func() {
+		base := []v1.TestResults{
+			*v1.NewTestResults(v1.RubyRSpecFramework, []v1.Test{test("a", v1.NewSuccessfulTestStatus(), 0)}, nil),
+			*v1.NewTestResults(v1.JavaScriptJestFramework, []v1.Test{test("a", v1.NewSuccessfulTestStatus(), 0)}, nil),
+		}
+		head := []v1.TestResults{
+			*v1.NewTestResults(v1.JavaScriptCypressFramework, []v1.Test{test("a", v1.NewSuccessfulTestStatus(), 0)}, nil),
+			*v1.NewTestResults(v1.RubyRSpecFramework, []v1.Test{test("a", v1.NewSuccessfulTestStatus(), 0)}, nil),
+		}
+
+		diffs := v1.Diff(base, head, cfg)
+		Expect(diffs).To(HaveLen(3))
+
+		Expect(diffs[0].Framework).To(Equal(v1.JavaScriptCypressFramework))
+		Expect(names(diffs[0].Added)).To(Equal([]string{"a"}))
+
+		Expect(diffs[1].Framework).To(Equal(v1.RubyRSpecFramework))
+		Expect(diffs[1].Changes()).To(Equal(0))
+
+		Expect(diffs[2].Framework).To(Equal(v1.JavaScriptJestFramework))
+		Expect(names(diffs[2].Removed)).To(Equal([]string{"a"}))
+	})
+})
@@ -10,30 +10,41 @@ type FrameworkLanguage string
 type FrameworkKind string
 
 const (
+	FrameworkKindBazel      FrameworkKind = "Bazel"
 	FrameworkKindCucumber   FrameworkKind = "Cucumber"
 	FrameworkKindCypress    FrameworkKind = "Cypress"
+	FrameworkKindDartTest   FrameworkKind = "dart test"
 	FrameworkKindExUnit     FrameworkKind = "ExUnit"
 	FrameworkKindGinkgo     FrameworkKind = "Ginkgo"
 	FrameworkKindGoTest     FrameworkKind = "go test"
+	FrameworkKindGoogleTest FrameworkKind = "googletest"
 	FrameworkKindJest       FrameworkKind = "Jest"
+	FrameworkKindJUnit      FrameworkKind = "JUnit"
 	FrameworkKindKarma      FrameworkKind = "Karma"
 	FrameworkKindMinitest   FrameworkKind = "minitest"
 	FrameworkKindMocha      FrameworkKind = "Mocha"
+	FrameworkKindNextest    FrameworkKind = "nextest"
 	FrameworkKindPHPUnit    FrameworkKind = "PHPUnit"
 	FrameworkKindPlaywright FrameworkKind = "Playwright"
 	FrameworkKindPytest     FrameworkKind = "pytest"
 	FrameworkKindUnitTest   FrameworkKind = "unittest"
 	FrameworkKindRSpec      FrameworkKind = "RSpec"
 	FrameworkKindxUnit      FrameworkKind = "xUnit"
+	FrameworkKindXCTest     FrameworkKind = "XCTest"
 	FrameworkKindVitest     FrameworkKind = "Vitest"
 
+	FrameworkLanguageCpp        FrameworkLanguage = "C++"
+	FrameworkLanguageDart       FrameworkLanguage = "Dart"
 	FrameworkLanguageDotNet     FrameworkLanguage = ".NET"
 	FrameworkLanguageElixir     FrameworkLanguage = "Elixir"
 	FrameworkLanguageGo         FrameworkLanguage = "Go"
+	FrameworkLanguageJava       FrameworkLanguage = "Java"
 	FrameworkLanguageJavaScript FrameworkLanguage = "JavaScript"
 	FrameworkLanguagePHP        FrameworkLanguage = "PHP"
 	FrameworkLanguagePython     FrameworkLanguage = "Python"
 	FrameworkLanguageRuby       FrameworkLanguage = "Ruby"
+	FrameworkLanguageRust       FrameworkLanguage = "Rust"
+	FrameworkLanguageSwift      FrameworkLanguage = "Swift"
 
 	FrameworkKindOther     FrameworkKind     = "other"
 	FrameworkLanguageOther FrameworkLanguage = "other"
@@ -54,6 +65,12 @@ func registerFramework(framework Framework) Framework {
 }
 
 var (
+	CppGoogleTestFramework = registerFramework(
+		Framework{Language: FrameworkLanguageCpp, Kind: FrameworkKindGoogleTest},
+	)
+	DartTestFramework = registerFramework(
+		Framework{Language: FrameworkLanguageDart, Kind: FrameworkKindDartTest},
+	)
 	DotNetxUnitFramework = registerFramework(
 		Framework{Language: FrameworkLanguageDotNet, Kind: FrameworkKindxUnit},
 	)
@@ -66,6 +83,9 @@ var (
 	GoTestFramework = registerFramework(
 		Framework{Language: FrameworkLanguageGo, Kind: FrameworkKindGoTest},
 	)
+	JavaJUnitFramework = registerFramework(
+		Framework{Language: FrameworkLanguageJava, Kind: FrameworkKindJUnit},
+	)
 	JavaScriptCucumberFramework = registerFramework(
 		Framework{Language: FrameworkLanguageJavaScript, Kind: FrameworkKindCucumber},
 	)
@@ -105,8 +125,18 @@ var (
 	RubyRSpecFramework = registerFramework(
 		Framework{Language: FrameworkLanguageRuby, Kind: FrameworkKindRSpec},
 	)
+	RustNextestFramework = registerFramework(
+		Framework{Language: FrameworkLanguageRust, Kind: FrameworkKindNextest},
+	)
+	SwiftXCTestFramework = registerFramework(
+		Framework{Language: FrameworkLanguageSwift, Kind: FrameworkKindXCTest},
+	)
 )
 
+// Bazel runs tests of any language, so it is not selectable with `--language` & `--framework`. Instead, it's
+// enabled by pointing Captain at the `bazel-testlogs` directory
+var BazelFramework = Framework{Language: FrameworkLanguageOther, Kind: FrameworkKindBazel}
+
 func NewOtherFramework(providedLanguage *string, providedKind *string) Framework {
 	return Framework{
 		Language:         FrameworkLanguageOther,
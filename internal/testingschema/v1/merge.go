@@ -30,47 +30,52 @@ func union(separateTestResults []TestResults) *TestResults {
 
 func flatten(unionedTestResults []TestResults) TestResults {
 	flattened, rest := unionedTestResults[0], unionedTestResults[1:]
+
+	// Incoming tests are merged into the first matching test, so only the first index of every identity is kept
+	indexesByIdentity := make(map[string]int, len(flattened.Tests))
+	for i, test := range flattened.Tests {
+		if _, ok := indexesByIdentity[test.identityKey()]; !ok {
+			indexesByIdentity[test.identityKey()] = i
+		}
+	}
+
 	for _, testResults := range rest {
 		flattened.DerivedFrom = append(flattened.DerivedFrom, testResults.DerivedFrom...)
 		flattened.OtherErrors = append(flattened.OtherErrors, testResults.OtherErrors...)
 
 		for _, incomingTest := range testResults.Tests {
-			matchedWithBaseTest := false
+			identity := incomingTest.identityKey()
 
-			for i, baseTest := range flattened.Tests {
-				if !baseTest.Matches(incomingTest) {
-					continue
-				}
-				matchedWithBaseTest = true
-
-				newAttempt := incomingTest.Attempt
-				newPastAttempt := baseTest.Attempt
-				if newAttempt.Status.ImpliesSkipped() {
-					// do not flatten skipped statuses into existing tests because they didn't actually run again
-					break
-				}
-				if newAttempt.Status.ImpliesFailure() && !newPastAttempt.Status.ImpliesFailure() {
-					newAttempt, newPastAttempt = newPastAttempt, newAttempt
-				}
-
-				pastAttempts := make([]TestAttempt, len(baseTest.PastAttempts)+1)
-				copy(pastAttempts, baseTest.PastAttempts)
-				pastAttempts[len(pastAttempts)-1] = newPastAttempt
+			i, matchedWithBaseTest := indexesByIdentity[identity]
+			if !matchedWithBaseTest {
+				indexesByIdentity[identity] = len(flattened.Tests)
+				flattened.Tests = append(flattened.Tests, incomingTest.Tag("missingInPreviousBatchOfResults", true))
+				continue
+			}
 
-				flattened.Tests[i] = Test{
-					Scope:        baseTest.Scope,
-					ID:           baseTest.ID,
-					Name:         baseTest.Name,
-					Lineage:      baseTest.Lineage,
-					Location:     baseTest.Location,
-					Attempt:      newAttempt,
-					PastAttempts: pastAttempts,
-				}
-				break
+			baseTest := flattened.Tests[i]
+			newAttempt := incomingTest.Attempt
+			newPastAttempt := baseTest.Attempt
+			if newAttempt.Status.ImpliesSkipped() {
+				// do not flatten skipped statuses into existing tests because they didn't actually run again
+				continue
+			}
+			if newAttempt.Status.ImpliesFailure() && !newPastAttempt.Status.ImpliesFailure() {
+				newAttempt, newPastAttempt = newPastAttempt, newAttempt
 			}
 
-			if !matchedWithBaseTest {
-				flattened.Tests = append(flattened.Tests, incomingTest.Tag("missingInPreviousBatchOfResults", true))
+			pastAttempts := make([]TestAttempt, len(baseTest.PastAttempts)+1)
+			copy(pastAttempts, baseTest.PastAttempts)
+			pastAttempts[len(pastAttempts)-1] = newPastAttempt
+
+			flattened.Tests[i] = Test{
+				Scope:        baseTest.Scope,
+				ID:           baseTest.ID,
+				Name:         baseTest.Name,
+				Lineage:      baseTest.Lineage,
+				Location:     baseTest.Location,
+				Attempt:      newAttempt,
+				PastAttempts: pastAttempts,
 			}
 		}
 	}
@@ -0,0 +1,60 @@
+package v1_test
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// newBenchmarkTestResults builds RSpec-like results where every `failEvery`th test failed
+func newBenchmarkTestResults(size int, failEvery int) v1.TestResults {
+	tests := make([]v1.Test, size)
+	for i := range tests {
+		id := fmt.Sprintf("./spec/models/model_%d_spec.rb[1:%d]", i/100, i%100)
+		line := i % 100
+		status := v1.NewSuccessfulTestStatus()
+		if failEvery > 0 && i%failEvery == 0 {
+			status = v1.NewFailedTestStatus(nil, nil, nil)
+		}
+
+		tests[i] = v1.Test{
+			ID:       &id,
+			Name:     fmt.Sprintf("Model%d does thing %d", i/100, i%100),
+			Lineage:  []string{fmt.Sprintf("Model%d", i/100), fmt.Sprintf("does thing %d", i%100)},
+			Location: &v1.Location{File: fmt.Sprintf("./spec/models/model_%d_spec.rb", i/100), Line: &line},
+			Attempt:  v1.TestAttempt{Status: status},
+		}
+	}
+
+	return *v1.NewTestResults(v1.RubyRSpecFramework, tests, nil)
+}
+
+// newBenchmarkRetry builds the results of retrying the failed tests, all of which pass this time
+func newBenchmarkRetry(testResults v1.TestResults) v1.TestResults {
+	tests := make([]v1.Test, 0)
+	for _, test := range testResults.Tests {
+		if test.Attempt.Status.ImpliesFailure() {
+			test.Attempt = v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()}
+			tests = append(tests, test)
+		}
+	}
+
+	return *v1.NewTestResults(testResults.Framework, tests, nil)
+}
+
+func benchmarkMerge(b *testing.B, size int, failEvery int) {
+	testResults := newBenchmarkTestResults(size, failEvery)
+	retry := newBenchmarkRetry(testResults)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v1.Merge([]v1.TestResults{testResults}, []v1.TestResults{retry})
+	}
+}
+
+func BenchmarkMerge1kTests(b *testing.B)  { benchmarkMerge(b, 1_000, 10) }
+func BenchmarkMerge10kTests(b *testing.B) { benchmarkMerge(b, 10_000, 10) }
+func BenchmarkMerge60kTests(b *testing.B) { benchmarkMerge(b, 60_000, 10) }
+
+func BenchmarkMerge60kTestsFewFailures(b *testing.B) { benchmarkMerge(b, 60_000, 1_000) }
@@ -673,4 +673,55 @@ var _ = Describe("Merge", func() {
 			},
 		))
 	})
+
+	It("only flattens tests whose identity matches exactly", func() {
+		int1 := 1
+		scope := "scope"
+		name := "name"
+
+		baseTests := []v1.Test{
+			{Name: name, Lineage: []string{"a b", "c"}},
+			{Name: name, Location: &v1.Location{File: "file.rb"}},
+			{Name: name, Scope: &scope},
+		}
+		incomingTests := []v1.Test{
+			{Name: name, Lineage: []string{"a", "b c"}},
+			{Name: name, Location: &v1.Location{File: "file.rb", Line: &int1}},
+			{Name: name},
+		}
+		for i := range baseTests {
+			baseTests[i].Attempt = v1.TestAttempt{Status: v1.NewFailedTestStatus(nil, nil, nil)}
+			incomingTests[i].Attempt = v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()}
+		}
+
+		merged := v1.Merge(
+			[]v1.TestResults{{Framework: v1.RubyRSpecFramework, Tests: baseTests}},
+			[]v1.TestResults{{Framework: v1.RubyRSpecFramework, Tests: incomingTests}},
+		)
+
+		Expect(merged.Tests).To(HaveLen(6))
+		Expect(merged.Summary.Retries).To(Equal(0))
+		for _, test := range merged.Tests[3:] {
+			Expect(test.Attempt.Meta).To(HaveKeyWithValue("__rwx", map[string]any{"missingInPreviousBatchOfResults": true}))
+		}
+	})
+
+	It("flattens tests that were missing in a previous batch", func() {
+		name := "name"
+
+		merged := v1.Merge(
+			[]v1.TestResults{{Framework: v1.RubyRSpecFramework, Tests: []v1.Test{}}},
+			[]v1.TestResults{{Framework: v1.RubyRSpecFramework, Tests: []v1.Test{
+				{Name: name, Attempt: v1.TestAttempt{Status: v1.NewFailedTestStatus(nil, nil, nil)}},
+			}}},
+			[]v1.TestResults{{Framework: v1.RubyRSpecFramework, Tests: []v1.Test{
+				{Name: name, Attempt: v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()}},
+			}}},
+		)
+
+		Expect(merged.Tests).To(HaveLen(1))
+		Expect(merged.Tests[0].Attempt.Status.Kind).To(Equal(v1.TestStatusSuccessful))
+		Expect(merged.Tests[0].PastAttempts).To(HaveLen(1))
+		Expect(merged.Tests[0].Flaky()).To(BeTrue())
+	})
 })
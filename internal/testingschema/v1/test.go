@@ -2,6 +2,7 @@ package v1
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -190,6 +191,55 @@ func (t Test) Matches(other Test) bool {
 	return lineageMatches
 }
 
+// identityKey encodes everything `Matches` compares, such that two tests match if and only if their keys are equal.
+// Every component is length-prefixed (or marked as missing) so that no two different tests share a key.
+func (t Test) identityKey() string {
+	var key []byte
+
+	appendString := func(value string) {
+		key = strconv.AppendInt(key, int64(len(value)), 10)
+		key = append(key, ':')
+		key = append(key, value...)
+	}
+	appendStringPointer := func(value *string) {
+		if value == nil {
+			key = append(key, '-')
+			return
+		}
+		appendString(*value)
+	}
+	appendIntPointer := func(value *int) {
+		if value == nil {
+			key = append(key, '-')
+			return
+		}
+		key = append(key, '+')
+		key = strconv.AppendInt(key, int64(*value), 10)
+		key = append(key, ';')
+	}
+
+	appendStringPointer(t.Scope)
+	appendStringPointer(t.ID)
+	appendString(t.Name)
+
+	if t.Location == nil {
+		key = append(key, '-')
+	} else {
+		key = append(key, '+')
+		appendString(t.Location.File)
+		appendIntPointer(t.Location.Line)
+		appendIntPointer(t.Location.Column)
+	}
+
+	key = strconv.AppendInt(key, int64(len(t.Lineage)), 10)
+	key = append(key, ';')
+	for _, component := range t.Lineage {
+		appendString(component)
+	}
+
+	return string(key)
+}
+
 // Calculates the composite identifier of a Test given the components which determine it
 func (t Test) Identify(withComponents []string, strictly bool) (string, error) {
 	foundComponents := make([]string, 0)
@@ -0,0 +1,207 @@
+package v1
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"unicode"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+)
+
+// An embedded copy of https://raw.githubusercontent.com/rwx-research/test-results-schema/main/v1.json
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+// ValidationError describes a single value in a document that doesn't match the schema
+type ValidationError struct {
+	// Pointer is the JSON pointer (RFC 6901) to the offending value, the empty string being the document itself
+	Pointer string
+	Message string
+}
+
+func (e ValidationError) String() string {
+	if e.Pointer == "" {
+		return fmt.Sprintf("(document): %s", e.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Pointer, e.Message)
+}
+
+// Validate checks a JSON document against the v1 schema. Documents matching the schema are also checked for whether
+// their summary matches their tests. An array is validated as the results of several frameworks, which is how captain
+// reports them. An error is only returned if the document can't be validated at all.
+func Validate(document []byte) ([]ValidationError, error) {
+	schema, err := compileSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(bytes.TrimLeftFunc(document, unicode.IsSpace), []byte("[")) {
+		return validateTestResults(schema, document)
+	}
+
+	var documents []json.RawMessage
+	if err := json.Unmarshal(document, &documents); err != nil {
+		return nil, errors.NewInputError("Unable to parse document as JSON: %s", err)
+	}
+
+	if len(documents) == 0 {
+		return []ValidationError{{Message: "expected the test results of at least one framework"}}, nil
+	}
+
+	validationErrors := make([]ValidationError, 0)
+	for i, document := range documents {
+		documentErrors, err := validateTestResults(schema, document)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, documentError := range documentErrors {
+			documentError.Pointer = fmt.Sprintf("/%d%s", i, documentError.Pointer)
+			validationErrors = append(validationErrors, documentError)
+		}
+	}
+
+	return validationErrors, nil
+}
+
+// compileSchema compiles the embedded schema. It never reaches out to the network, the draft 7 meta-schema is built
+// into the validator.
+func compileSchema() (*jsonschema.Schema, error) {
+	const schemaURL = "https://raw.githubusercontent.com/rwx-research/test-results-schema/main/v1.json"
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft7
+	compiler.AssertFormat = true
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, errors.NewInternalError("Unable to load %q, only the embedded schema is available", url)
+	}
+
+	if err := compiler.AddResource(schemaURL, bytes.NewReader(schemaJSON)); err != nil {
+		return nil, errors.NewInternalError("Unable to load the embedded JSON schema: %s", err)
+	}
+
+	schema, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, errors.NewInternalError("Unable to compile the embedded JSON schema: %s", err)
+	}
+
+	return schema, nil
+}
+
+// validateSchema reports the innermost errors of the validation, since those point at the offending values. They are
+// ordered by their position in the document.
+func validateSchema(schema *jsonschema.Schema, document []byte) ([]ValidationError, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, errors.NewInputError("Unable to parse document as JSON: %s", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.NewInputError("Unable to parse document as JSON: unexpected data after the top-level value")
+	}
+
+	err := schema.Validate(value)
+	if err == nil {
+		return nil, nil
+	}
+
+	var validationError *jsonschema.ValidationError
+	if !errors.As(err, &validationError) {
+		return nil, errors.NewInternalError("Unable to validate document: %s", err)
+	}
+
+	validationErrors := make([]ValidationError, 0)
+	seen := make(map[ValidationError]struct{})
+	var collect func(*jsonschema.ValidationError)
+	collect = func(validationError *jsonschema.ValidationError) {
+		if len(validationError.Causes) > 0 {
+			for _, cause := range validationError.Causes {
+				collect(cause)
+			}
+			return
+		}
+
+		leaf := ValidationError{Pointer: validationError.InstanceLocation, Message: validationError.Message}
+		if _, ok := seen[leaf]; !ok {
+			seen[leaf] = struct{}{}
+			validationErrors = append(validationErrors, leaf)
+		}
+	}
+	collect(validationError)
+
+	sort.SliceStable(validationErrors, func(i, j int) bool {
+		return validationErrors[i].Pointer < validationErrors[j].Pointer
+	})
+
+	return validationErrors, nil
+}
+
+func validateTestResults(schema *jsonschema.Schema, document []byte) ([]ValidationError, error) {
+	validationErrors, err := validateSchema(schema, document)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(validationErrors) > 0 {
+		return validationErrors, nil
+	}
+
+	var testResults TestResults
+	if err := json.Unmarshal(document, &testResults); err != nil {
+		return nil, errors.NewInputError("Unable to parse document as RWX v1 JSON: %s", err)
+	}
+
+	return testResults.validateSummary(), nil
+}
+
+func (tr TestResults) validateSummary() []ValidationError {
+	expected := NewSummary(tr.Tests, tr.OtherErrors)
+	validationErrors := make([]ValidationError, 0)
+
+	mismatch := func(pointer string, expected any, actual any) {
+		validationErrors = append(validationErrors, ValidationError{
+			Pointer: pointer,
+			Message: fmt.Sprintf("expected %v based on the tests & other errors but found %v", expected, actual),
+		})
+	}
+
+	if expected.Status != tr.Summary.Status {
+		mismatch("/summary/status/kind", expected.Status, tr.Summary.Status)
+	}
+
+	counts := []struct {
+		name     string
+		expected int
+		actual   int
+	}{
+		{"tests", expected.Tests, tr.Summary.Tests},
+		{"otherErrors", expected.OtherErrors, tr.Summary.OtherErrors},
+		{"retries", expected.Retries, tr.Summary.Retries},
+		{"canceled", expected.Canceled, tr.Summary.Canceled},
+		{"failed", expected.Failed, tr.Summary.Failed},
+		{"pended", expected.Pended, tr.Summary.Pended},
+		{"quarantined", expected.Quarantined, tr.Summary.Quarantined},
+		{"skipped", expected.Skipped, tr.Summary.Skipped},
+		{"successful", expected.Successful, tr.Summary.Successful},
+		{"timedOut", expected.TimedOut, tr.Summary.TimedOut},
+		{"todo", expected.Todo, tr.Summary.Todo},
+	}
+
+	for _, count := range counts {
+		if count.expected != count.actual {
+			mismatch("/summary/"+count.name, count.expected, count.actual)
+		}
+	}
+
+	return validationErrors
+}
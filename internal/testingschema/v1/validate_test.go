@@ -0,0 +1,97 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"os"
+
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	It("accepts test results written by captain", func() {
+		message := "oh no"
+		testResults := v1.NewTestResults(
+			v1.RubyRSpecFramework,
+			[]v1.Test{
+				{Name: "passes", Attempt: v1.TestAttempt{Status: v1.NewSuccessfulTestStatus()}},
+				{
+					Name:    "fails",
+					Attempt: v1.TestAttempt{Status: v1.NewQuarantinedTestStatus(v1.NewFailedTestStatus(&message, nil, nil))},
+				},
+			},
+			[]v1.OtherError{{Message: message}},
+		)
+
+		document, err := json.Marshal(testResults)
+		Expect(err).NotTo(HaveOccurred())
+
+		validationErrors, err := v1.Validate(document)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validationErrors).To(BeEmpty())
+	})
+
+	It("reports where the fixture doesn't match the schema", func() {
+		validationErrors, err := v1.Validate([]byte(`{
+			"$schema": "https://raw.githubusercontent.com/rwx-research/test-results-schema/main/v1.json",
+			"framework": { "language": "Ruby", "kind": "RSpec" },
+			"summary": {
+				"status": { "kind": "successful" },
+				"tests": 1, "otherErrors": 0, "retries": 0, "canceled": 0, "failed": 0, "pended": 0,
+				"quarantined": 0, "skipped": 0, "successful": 1, "timedOut": 0, "todo": 0
+			},
+			"tests": [
+				{
+					"name": "passes",
+					"location": { "file": "foo.rb", "lines": 1 },
+					"attempt": { "durationInNanoseconds": "1s", "status": { "kind": "quarantined" } }
+				}
+			]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validationErrors).To(Equal([]v1.ValidationError{
+			{Pointer: "/tests/0/attempt/durationInNanoseconds", Message: "expected integer or null, but got string"},
+			{Pointer: "/tests/0/attempt/status", Message: "missing properties: 'originalStatus'"},
+			{Pointer: "/tests/0/location", Message: "additionalProperties 'lines' not allowed"},
+		}))
+	})
+
+	It("reports summaries that don't match the tests", func() {
+		fixture, err := os.ReadFile("../../../test/fixtures/rwx/v1.json")
+		Expect(err).NotTo(HaveOccurred())
+
+		validationErrors, err := v1.Validate(fixture)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validationErrors).To(Equal([]v1.ValidationError{
+			{Pointer: "/summary/otherErrors", Message: "expected 2 based on the tests & other errors but found 3"},
+			{Pointer: "/summary/failed", Message: "expected 0 based on the tests & other errors but found 1"},
+			{Pointer: "/summary/successful", Message: "expected 2 based on the tests & other errors but found 1"},
+		}))
+	})
+
+	It("validates every element of an array as the test results of a framework", func() {
+		fixture, err := os.ReadFile("../../../test/fixtures/rwx/v1.json")
+		Expect(err).NotTo(HaveOccurred())
+
+		document, err := json.Marshal([]any{
+			v1.NewTestResults(v1.RubyRSpecFramework, []v1.Test{}, nil),
+			json.RawMessage(fixture),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		validationErrors, err := v1.Validate(document)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validationErrors).To(Equal([]v1.ValidationError{
+			{Pointer: "/1/summary/otherErrors", Message: "expected 2 based on the tests & other errors but found 3"},
+			{Pointer: "/1/summary/failed", Message: "expected 0 based on the tests & other errors but found 1"},
+			{Pointer: "/1/summary/successful", Message: "expected 2 based on the tests & other errors but found 1"},
+		}))
+	})
+
+	It("errors on files that aren't JSON", func() {
+		_, err := v1.Validate([]byte(`<testsuites></testsuites>`))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,213 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+// V1 has no place for attachments, steps & tags, so they are kept in the meta of the (current) attempt under these
+// keys. Converting back to V2 lifts them out of the meta again, which makes the conversion lossless in both directions.
+// Tags belong to the test rather than to an attempt. Merging a retry turns the current attempt into a past one, so the
+// tags of past attempts are lifted as well and combined with the ones of the current attempt.
+const (
+	attachmentsMetaKey = "attachments"
+	stepsMetaKey       = "steps"
+	tagsMetaKey        = "tags"
+)
+
+// FromV1 converts V1 test results to V2
+func FromV1(testResults v1.TestResults) TestResults {
+	tests := make([]Test, len(testResults.Tests))
+	for i, test := range testResults.Tests {
+		attempt := attemptFromV1(test.Attempt)
+
+		var tags []string
+		tags, attempt.Meta = liftFromMeta[string](attempt.Meta, tagsMetaKey)
+
+		var pastAttempts []TestAttempt
+		if test.PastAttempts != nil {
+			pastAttempts = make([]TestAttempt, len(test.PastAttempts))
+			for j, pastAttempt := range test.PastAttempts {
+				pastAttempts[j] = attemptFromV1(pastAttempt)
+
+				var pastTags []string
+				pastTags, pastAttempts[j].Meta = liftFromMeta[string](pastAttempts[j].Meta, tagsMetaKey)
+				tags = appendMissing(tags, pastTags)
+			}
+		}
+
+		tests[i] = Test{
+			Scope:        test.Scope,
+			ID:           test.ID,
+			Name:         test.Name,
+			Lineage:      test.Lineage,
+			Location:     test.Location,
+			Tags:         tags,
+			Attempt:      attempt,
+			PastAttempts: pastAttempts,
+		}
+	}
+
+	return TestResults{
+		Framework:   testResults.Framework,
+		Summary:     testResults.Summary,
+		Tests:       tests,
+		OtherErrors: testResults.OtherErrors,
+		DerivedFrom: testResults.DerivedFrom,
+	}
+}
+
+// ToV1 converts V2 test results to V1
+func (tr TestResults) ToV1() v1.TestResults {
+	tests := make([]v1.Test, len(tr.Tests))
+	for i, test := range tr.Tests {
+		attempt := test.Attempt.toV1()
+		attempt.Meta = storeInMeta(attempt.Meta, tagsMetaKey, test.Tags)
+
+		var pastAttempts []v1.TestAttempt
+		if test.PastAttempts != nil {
+			pastAttempts = make([]v1.TestAttempt, len(test.PastAttempts))
+			for j, pastAttempt := range test.PastAttempts {
+				pastAttempts[j] = pastAttempt.toV1()
+			}
+		}
+
+		tests[i] = v1.Test{
+			Scope:        test.Scope,
+			ID:           test.ID,
+			Name:         test.Name,
+			Lineage:      test.Lineage,
+			Location:     test.Location,
+			Attempt:      attempt,
+			PastAttempts: pastAttempts,
+		}
+	}
+
+	return v1.TestResults{
+		Framework:   tr.Framework,
+		Summary:     tr.Summary,
+		Tests:       tests,
+		OtherErrors: tr.OtherErrors,
+		DerivedFrom: tr.DerivedFrom,
+	}
+}
+
+func attemptFromV1(attempt v1.TestAttempt) TestAttempt {
+	converted := TestAttempt{
+		Duration:   attempt.Duration,
+		Meta:       attempt.Meta,
+		Status:     attempt.Status,
+		Stderr:     attempt.Stderr,
+		Stdout:     attempt.Stdout,
+		StartedAt:  attempt.StartedAt,
+		FinishedAt: attempt.FinishedAt,
+	}
+
+	converted.Attachments, converted.Meta = liftFromMeta[Attachment](converted.Meta, attachmentsMetaKey)
+	converted.Steps, converted.Meta = liftFromMeta[Step](converted.Meta, stepsMetaKey)
+
+	return converted
+}
+
+func (a TestAttempt) toV1() v1.TestAttempt {
+	meta := storeInMeta(a.Meta, attachmentsMetaKey, a.Attachments)
+	meta = storeInMeta(meta, stepsMetaKey, a.Steps)
+
+	return v1.TestAttempt{
+		Duration:   a.Duration,
+		Meta:       meta,
+		Status:     a.Status,
+		Stderr:     a.Stderr,
+		Stdout:     a.Stdout,
+		StartedAt:  a.StartedAt,
+		FinishedAt: a.FinishedAt,
+	}
+}
+
+// liftFromMeta removes `key` from a copy of the meta if its value can be represented as a `[]T` without changing its
+// JSON encoding. Anything else stays in the meta as is.
+func liftFromMeta[T any](meta map[string]any, key string) ([]T, map[string]any) {
+	value, ok := meta[key]
+	if !ok {
+		return nil, meta
+	}
+
+	encodedValue, err := json.Marshal(value)
+	if err != nil {
+		return nil, meta
+	}
+
+	var lifted []T
+	decoder := json.NewDecoder(bytes.NewReader(encodedValue))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&lifted); err != nil || len(lifted) == 0 {
+		return nil, meta
+	}
+
+	encodedLifted, err := json.Marshal(lifted)
+	if err != nil || !equalJSON(encodedValue, encodedLifted) {
+		return nil, meta
+	}
+
+	remainingMeta := make(map[string]any, len(meta)-1)
+	for name, value := range meta {
+		if name != key {
+			remainingMeta[name] = value
+		}
+	}
+	if len(remainingMeta) == 0 {
+		remainingMeta = nil
+	}
+
+	return lifted, remainingMeta
+}
+
+// equalJSON compares two JSON documents regardless of the order of their keys
+func equalJSON(a []byte, b []byte) bool {
+	var decodedA, decodedB any
+	if err := json.Unmarshal(a, &decodedA); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &decodedB); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(decodedA, decodedB)
+}
+
+// appendMissing appends the values that aren't in `values` yet, keeping their order
+func appendMissing[T comparable](values []T, others []T) []T {
+	for _, other := range others {
+		found := false
+		for _, value := range values {
+			if value == other {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			values = append(values, other)
+		}
+	}
+
+	return values
+}
+
+// storeInMeta adds the values to a copy of the meta under `key`
+func storeInMeta[T any](meta map[string]any, key string, values []T) map[string]any {
+	if len(values) == 0 {
+		return meta
+	}
+
+	extendedMeta := make(map[string]any, len(meta)+1)
+	for name, value := range meta {
+		extendedMeta[name] = value
+	}
+	extendedMeta[key] = values
+
+	return extendedMeta
+}
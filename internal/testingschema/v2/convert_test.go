@@ -0,0 +1,125 @@
+package v2_test
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+	v2 "github.com/rwx-research/captain-cli/internal/testingschema/v2"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Converting between V1 and V2", func() {
+	var v2TestResults v2.TestResults
+
+	BeforeEach(func() {
+		fixture, err := os.ReadFile("../../../test/fixtures/rwx/v2.json")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Unmarshal(fixture, &v2TestResults)).To(Succeed())
+	})
+
+	It("keeps attachments, steps & tags in the meta of V1 attempts", func() {
+		v1TestResults := v2TestResults.ToV1()
+		Expect(v1TestResults.Framework).To(Equal(v1.JavaScriptPlaywrightFramework))
+		Expect(v1TestResults.Tests).To(HaveLen(2))
+
+		test := v1TestResults.Tests[0]
+		Expect(test.Attempt.Meta).To(HaveKeyWithValue("tags", []string{"@smoke", "@auth"}))
+		Expect(test.Attempt.Meta).To(HaveKeyWithValue("project", "chromium"))
+		Expect(test.Attempt.Meta).To(HaveKey("steps"))
+		Expect(test.Attempt.Meta).NotTo(HaveKey("attachments"))
+		Expect(test.PastAttempts[0].Meta).To(HaveKey("attachments"))
+		Expect(test.PastAttempts[0].Meta).NotTo(HaveKey("tags"))
+
+		Expect(v2TestResults.Tests[0].Attempt.Meta).NotTo(HaveKey("tags"))
+	})
+
+	It("round trips V2 through V1 without losing anything", func() {
+		expected, err := json.Marshal(v2TestResults)
+		Expect(err).ToNot(HaveOccurred())
+
+		actual, err := json.Marshal(v2.FromV1(v2TestResults.ToV1()))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(MatchJSON(expected))
+
+		// Also after the V1 results went through JSON themselves
+		v1JSON, err := json.Marshal(v2TestResults.ToV1())
+		Expect(err).ToNot(HaveOccurred())
+		var v1TestResults v1.TestResults
+		Expect(json.Unmarshal(v1JSON, &v1TestResults)).To(Succeed())
+
+		actual, err = json.Marshal(v2.FromV1(v1TestResults))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(MatchJSON(expected))
+	})
+
+	It("round trips V1 through V2 without losing anything", func() {
+		duration := time.Second
+		v1TestResults := *v1.NewTestResults(
+			v1.JavaScriptPlaywrightFramework,
+			[]v1.Test{
+				{
+					Name: "with meta that looks like V2 data",
+					Attempt: v1.TestAttempt{
+						Duration: &duration,
+						Status:   v1.NewSuccessfulTestStatus(),
+						Meta: map[string]any{
+							"tags":        []string{"@smoke"},
+							"attachments": []map[string]any{{"name": "screenshot", "path": "a.png"}},
+							"steps":       []map[string]any{{"title": "click", "unknown": true}},
+						},
+					},
+				},
+				{
+					Name: "with meta that doesn't",
+					Attempt: v1.TestAttempt{
+						Duration: &duration,
+						Status:   v1.NewSuccessfulTestStatus(),
+						Meta:     map[string]any{"tags": nil, "steps": []string{}},
+					},
+				},
+			},
+			nil,
+		)
+
+		converted := v2.FromV1(v1TestResults)
+		Expect(converted.Tests[0].Tags).To(Equal([]string{"@smoke"}))
+		Expect(converted.Tests[0].Attempt.Attachments).To(Equal([]v2.Attachment{{Name: "screenshot", Path: "a.png"}}))
+		Expect(converted.Tests[0].Attempt.Steps).To(BeNil())
+		Expect(converted.Tests[0].Attempt.Meta).To(HaveKey("steps"))
+		Expect(converted.Tests[1].Tags).To(BeNil())
+		Expect(converted.Tests[1].Attempt.Meta).To(HaveLen(2))
+
+		expected, err := json.Marshal(v1TestResults)
+		Expect(err).ToNot(HaveOccurred())
+
+		actual, err := json.Marshal(converted.ToV1())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actual).To(MatchJSON(expected))
+	})
+
+	It("keeps the tags of tests that were retried", func() {
+		original := v2TestResults.ToV1()
+
+		retried := v2TestResults
+		retried.Tests = []v2.Test{v2TestResults.Tests[0]}
+		retried.Tests[0].Tags = []string{"@auth", "@retried"}
+		retried.Tests[0].PastAttempts = nil
+
+		merged := v1.Merge([]v1.TestResults{original}, []v1.TestResults{retried.ToV1()})
+		Expect(merged.Tests[0].PastAttempts).To(HaveLen(len(v2TestResults.Tests[0].PastAttempts) + 1))
+
+		converted := v2.FromV1(merged)
+		Expect(converted.Tests[0].Tags).To(Equal([]string{"@auth", "@retried", "@smoke"}))
+		Expect(converted.Tests[0].Attempt.Meta).NotTo(HaveKey("tags"))
+		for _, pastAttempt := range converted.Tests[0].PastAttempts {
+			Expect(pastAttempt.Meta).NotTo(HaveKey("tags"))
+		}
+
+		// The tags survive another round trip through V1
+		Expect(v2.FromV1(converted.ToV1()).Tests[0].Tags).To(Equal(converted.Tests[0].Tags))
+	})
+})
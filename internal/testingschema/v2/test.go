@@ -0,0 +1,48 @@
+package v2
+
+import (
+	"time"
+)
+
+// Attachment is a file produced by a test attempt, e.g. a screenshot, a video or a trace
+type Attachment struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// Step is one of the (nested) steps a test attempt went through, e.g. a `test.step` in Playwright or a command in
+// Cypress
+type Step struct {
+	Title     string         `json:"title"`
+	Category  *string        `json:"category,omitempty"`
+	Duration  *time.Duration `json:"durationInNanoseconds,omitempty"`
+	StartedAt *time.Time     `json:"startedAt,omitempty"`
+	Error     *string        `json:"error,omitempty"`
+	Steps     []Step         `json:"steps,omitempty"`
+}
+
+type TestAttempt struct {
+	Duration    *time.Duration `json:"durationInNanoseconds"`
+	Meta        map[string]any `json:"meta,omitempty"`
+	Status      TestStatus     `json:"status"`
+	Stderr      *string        `json:"stderr,omitempty"`
+	Stdout      *string        `json:"stdout,omitempty"`
+	StartedAt   *time.Time     `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time     `json:"finishedAt,omitempty"`
+	Attachments []Attachment   `json:"attachments,omitempty"`
+	Steps       []Step         `json:"steps,omitempty"`
+}
+
+type Test struct {
+	// Same as the scope of a v1 test: not included in the JSON output nor the schema.
+	Scope *string `json:"-"`
+
+	ID           *string       `json:"id,omitempty"`
+	Name         string        `json:"name"`
+	Lineage      []string      `json:"lineage,omitempty"`
+	Location     *Location     `json:"location,omitempty"`
+	Tags         []string      `json:"tags,omitempty"`
+	Attempt      TestAttempt   `json:"attempt"`
+	PastAttempts []TestAttempt `json:"pastAttempts,omitempty"`
+}
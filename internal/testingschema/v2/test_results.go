@@ -0,0 +1,68 @@
+// testingschema/v2 holds the implementation of V2 of RWX's test results schema. It extends V1 with attachments, steps
+// and tags; everything else is shared with V1.
+package v2
+
+import (
+	"encoding/json"
+
+	"github.com/rwx-research/captain-cli/internal/errors"
+	v1 "github.com/rwx-research/captain-cli/internal/testingschema/v1"
+)
+
+const SchemaURL = "https://raw.githubusercontent.com/rwx-research/test-results-schema/main/v2.json"
+
+type (
+	Framework           = v1.Framework
+	Location            = v1.Location
+	OriginalTestResults = v1.OriginalTestResults
+	OtherError          = v1.OtherError
+	Summary             = v1.Summary
+	TestStatus          = v1.TestStatus
+)
+
+type TestResults struct {
+	Framework   Framework             `json:"framework"`
+	Summary     Summary               `json:"summary"`
+	Tests       []Test                `json:"tests"`
+	OtherErrors []OtherError          `json:"otherErrors,omitempty"`
+	DerivedFrom []OriginalTestResults `json:"derivedFrom,omitempty"`
+}
+
+func (tr TestResults) MarshalJSON() ([]byte, error) {
+	type Alias TestResults
+
+	json, err := json.Marshal(&struct {
+		Schema string `json:"$schema"`
+		Alias
+	}{
+		Schema: SchemaURL,
+		Alias:  (Alias)(tr),
+	})
+
+	return json, errors.WithStack(err)
+}
+
+func (tr *TestResults) UnmarshalJSON(b []byte) error {
+	type Alias TestResults
+	var a struct {
+		Schema string `json:"$schema"`
+		Alias
+	}
+
+	if err := json.Unmarshal(b, &a); err != nil {
+		return errors.WithStack(err)
+	}
+	if a.Schema != SchemaURL {
+		return errors.NewInputError("The parsed JSON is not the v2 RWX test results schema")
+	}
+
+	*tr = TestResults{
+		Framework:   a.Framework,
+		Summary:     a.Summary,
+		Tests:       a.Tests,
+		OtherErrors: a.OtherErrors,
+		DerivedFrom: a.DerivedFrom,
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package v2_test
+
+import (
+	"encoding/json"
+
+	v2 "github.com/rwx-research/captain-cli/internal/testingschema/v2"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TestResults", func() {
+	Describe("Marshal/UnmarshalJSON", func() {
+		It("round trips", func() {
+			testResults := v2.TestResults{
+				Framework: v2.Framework{
+					Language: "JavaScript",
+					Kind:     "Playwright",
+				},
+				Tests: []v2.Test{
+					{
+						Name: "name of the test",
+						Tags: []string{"@smoke"},
+						Attempt: v2.TestAttempt{
+							Status:      v2.TestStatus{Kind: "successful"},
+							Attachments: []v2.Attachment{{Name: "video", Path: "video.webm", ContentType: "video/webm"}},
+							Steps:       []v2.Step{{Title: "open the page", Steps: []v2.Step{{Title: "page.goto"}}}},
+						},
+					},
+				},
+			}
+
+			buf, err := json.Marshal(testResults)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(buf)).To(ContainSubstring(`"$schema":"` + v2.SchemaURL + `"`))
+
+			var roundTripped v2.TestResults
+			Expect(json.Unmarshal(buf, &roundTripped)).To(Succeed())
+			Expect(roundTripped).To(Equal(testResults))
+		})
+
+		It("errors on other schemas", func() {
+			var testResults v2.TestResults
+			err := json.Unmarshal(
+				[]byte(`{"$schema": "https://raw.githubusercontent.com/rwx-research/test-results-schema/main/v1.json"}`),
+				&testResults,
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not the v2 RWX test results schema"))
+		})
+	})
+})
@@ -508,6 +508,64 @@ var _ = Describe(versionedPrefixForQuarantining()+"OSS mode Integration Tests",
 			})
 		})
 
+		It("produces rwx-v2-json reports via config file", func() {
+			tmp, err := os.MkdirTemp("", "*")
+			Expect(err).NotTo(HaveOccurred())
+
+			outputPath := filepath.Join(tmp, "rwx-v2.json")
+			os.Remove(outputPath)
+
+			cfg := loadCaptainConfig("fixtures/integration-tests/captain-configs/rwx-v2-json-reporter.printf-yaml", outputPath)
+
+			withCaptainConfig(cfg, tmp, func(configPath string) {
+				result := runCaptain(captainArgs{
+					args: []string{
+						"run",
+						"captain-cli-functional-tests",
+						"--config-file", configPath,
+					},
+					env: make(map[string]string),
+				})
+
+				_, err = os.Stat(outputPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.exitCode).To(Equal(123))
+
+				withoutBackwardsCompatibility(func() {
+					Expect(result.stderr).To(ContainSubstring("Error: test suite exited with non-zero exit code"))
+				})
+			})
+		})
+
+		It("produces rwx-v2-json reports via CLI flag", func() {
+			tmp, err := os.MkdirTemp("", "*")
+			Expect(err).NotTo(HaveOccurred())
+
+			os.Remove(filepath.Join(tmp, "rwx-v2.json"))
+
+			result := runCaptain(captainArgs{
+				args: []string{
+					"run",
+					"captain-cli-functional-tests",
+					"--test-results", "fixtures/integration-tests/rspec-failed-not-quarantined.json",
+					"--fail-on-upload-error",
+					"--reporter", fmt.Sprintf("rwx-v2-json=%v", filepath.Join(tmp, "rwx-v2.json")),
+					"-c", "bash -c 'exit 123'",
+				},
+				env: make(map[string]string),
+			})
+
+			contents, err := os.ReadFile(filepath.Join(tmp, "rwx-v2.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("test-results-schema/main/v2.json"))
+
+			Expect(result.exitCode).To(Equal(123))
+
+			withoutBackwardsCompatibility(func() {
+				Expect(result.stderr).To(ContainSubstring("Error: test suite exited with non-zero exit code"))
+			})
+		})
+
 		It("produces junit-xml reports via config file", func() {
 			tmp, err := os.MkdirTemp("", "*")
 			Expect(err).NotTo(HaveOccurred())
@@ -565,6 +623,63 @@ var _ = Describe(versionedPrefixForQuarantining()+"OSS mode Integration Tests",
 			})
 		})
 
+		It("produces ctrf-json reports via config file", func() {
+			tmp, err := os.MkdirTemp("", "*")
+			Expect(err).NotTo(HaveOccurred())
+
+			outputPath := filepath.Join(tmp, "ctrf.json")
+			os.Remove(outputPath)
+
+			cfg := loadCaptainConfig("fixtures/integration-tests/captain-configs/ctrf-json-reporter.printf-yaml", outputPath)
+
+			withCaptainConfig(cfg, tmp, func(configPath string) {
+				result := runCaptain(captainArgs{
+					args: []string{
+						"run",
+						"captain-cli-functional-tests",
+						"--config-file", configPath,
+					},
+					env: make(map[string]string),
+				})
+
+				_, err = os.Stat(outputPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.exitCode).To(Equal(123))
+
+				withoutBackwardsCompatibility(func() {
+					Expect(result.stderr).To(ContainSubstring("Error: test suite exited with non-zero exit code"))
+				})
+			})
+		})
+
+		It("produces ctrf-json reports via CLI flag", func() {
+			tmp, err := os.MkdirTemp("", "*")
+			Expect(err).NotTo(HaveOccurred())
+
+			os.Remove(filepath.Join(tmp, "ctrf.json"))
+
+			result := runCaptain(captainArgs{
+				args: []string{
+					"run",
+					"captain-cli-functional-tests",
+					"--test-results", "fixtures/integration-tests/rspec-failed-not-quarantined.json",
+					"--fail-on-upload-error",
+					"--reporter", fmt.Sprintf("ctrf-json=%v", filepath.Join(tmp, "ctrf.json")),
+					"-c", "bash -c 'exit 123'",
+				},
+				env: make(map[string]string),
+			})
+
+			_, err = os.Stat(filepath.Join(tmp, "ctrf.json"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.exitCode).To(Equal(123))
+			withoutBackwardsCompatibility(func() {
+				Expect(result.stderr).To(ContainSubstring("Error: test suite exited with non-zero exit code"))
+			})
+		})
+
 		It("accepts the language and framework CLI flags and parses with their parser", func() {
 			tmp, err := os.MkdirTemp("", "*")
 			Expect(err).NotTo(HaveOccurred())
@@ -953,6 +1068,179 @@ var _ = Describe(versionedPrefixForQuarantining()+"OSS mode Integration Tests",
 		})
 	})
 
+	Describe("captain validate", func() {
+		It("accepts RWX v1 JSON written by captain", func() {
+			parseResult := runCaptain(captainArgs{
+				args: []string{"parse", "results", "fixtures/rspec.json"},
+				env:  make(map[string]string),
+			})
+			Expect(parseResult.exitCode).To(Equal(0))
+
+			tmp, err := os.MkdirTemp("", "*")
+			Expect(err).NotTo(HaveOccurred())
+
+			resultsPath := filepath.Join(tmp, "rwx-v1.json")
+			err = os.WriteFile(resultsPath, []byte(parseResult.stdout), 0o600)
+			Expect(err).NotTo(HaveOccurred())
+
+			result := runCaptain(captainArgs{
+				args: []string{"validate", resultsPath},
+				env:  make(map[string]string),
+			})
+
+			Expect(result.exitCode).To(Equal(0))
+			Expect(result.stdout).To(ContainSubstring(fmt.Sprintf("%s: valid", resultsPath)))
+		})
+
+		It("reports every problem with a pointer to the offending value", func() {
+			result := runCaptain(captainArgs{
+				args: []string{"validate", "fixtures/rwx/v1.json", "fixtures/rspec.json"},
+				env:  make(map[string]string),
+			})
+
+			Expect(result.exitCode).To(Equal(1))
+			Expect(result.stdout).To(ContainSubstring(
+				"fixtures/rwx/v1.json: /summary/successful: expected 2 based on the tests & other errors but found 1",
+			))
+			Expect(result.stdout).To(ContainSubstring("fixtures/rspec.json: (document): missing properties: '$schema'"))
+			Expect(result.stderr).To(ContainSubstring("Error: 2 of 2 files are not valid RWX v1 JSON"))
+		})
+
+		It("reports files that aren't JSON", func() {
+			result := runCaptain(captainArgs{
+				args: []string{"validate", "fixtures/junit.xml"},
+				env:  make(map[string]string),
+			})
+
+			Expect(result.exitCode).To(Equal(1))
+			Expect(result.stdout).To(ContainSubstring("fixtures/junit.xml: Unable to parse document as JSON"))
+			Expect(result.stderr).To(ContainSubstring("Error: 1 of 1 files are not valid RWX v1 JSON"))
+		})
+	})
+
+	Describe("captain diff", func() {
+		It("reports newly failing tests", func() {
+			result := runCaptain(captainArgs{
+				args: []string{
+					"diff",
+					"fixtures/integration-tests/rspec-passed.json",
+					"fixtures/integration-tests/rspec-quarantine.json",
+				},
+				env: make(map[string]string),
+			})
+
+			Expect(result.exitCode).To(Equal(0))
+			Expect(result.stdout).To(ContainSubstring("Compared 1 base test to 1 head test."))
+			Expect(result.stdout).To(ContainSubstring("Newly failing (1):\n- is flaky (was successful)"))
+		})
+
+		It("reports newly passing tests", func() {
+			result := runCaptain(captainArgs{
+				args: []string{
+					"diff",
+					"fixtures/integration-tests/rspec-quarantine.json",
+					"fixtures/integration-tests/rspec-passed.json",
+				},
+				env: make(map[string]string),
+			})
+
+			Expect(result.exitCode).To(Equal(0))
+			Expect(result.stdout).To(ContainSubstring("Newly passing (1):\n- is flaky (was failed)"))
+		})
+
+		It("reports added & removed tests", func() {
+			result := runCaptain(captainArgs{
+				args: []string{
+					"diff",
+					"fixtures/integration-tests/rspec-passed.json",
+					"fixtures/integration-tests/rspec-failed-not-quarantined.json",
+				},
+				env: make(map[string]string),
+			})
+
+			Expect(result.exitCode).To(Equal(0))
+			Expect(result.stdout).To(ContainSubstring("Added (1):\n- is failing"))
+			Expect(result.stdout).To(ContainSubstring("Removed (1):\n- is flaky"))
+		})
+
+		It("reports that nothing changed", func() {
+			result := runCaptain(captainArgs{
+				args: []string{
+					"diff",
+					"fixtures/integration-tests/rspec-passed.json",
+					"fixtures/integration-tests/rspec-passed.json",
+				},
+				env: make(map[string]string),
+			})
+
+			Expect(result.exitCode).To(Equal(0))
+			Expect(result.stdout).To(ContainSubstring("No changes."))
+		})
+
+		It("expands globs to several test results files", func() {
+			result := runCaptain(captainArgs{
+				args: []string{
+					"diff",
+					"fixtures/integration-tests/rspec-passed.json",
+					"fixtures/integration-tests/retries/oss-rspec-*.json",
+				},
+				env: make(map[string]string),
+			})
+
+			Expect(result.exitCode).To(Equal(0))
+			Expect(result.stdout).To(ContainSubstring("Compared 1 base test to 2 head tests."))
+			Expect(result.stdout).To(ContainSubstring("Newly failing (1):\n- is flaky (was successful)"))
+			Expect(result.stdout).To(ContainSubstring("Added (1):\n- is failing"))
+		})
+
+		It("writes markdown", func() {
+			result := runCaptain(captainArgs{
+				args: []string{
+					"diff",
+					"--format", "markdown",
+					"fixtures/integration-tests/rspec-passed.json",
+					"fixtures/integration-tests/rspec-quarantine.json",
+				},
+				env: make(map[string]string),
+			})
+
+			Expect(result.exitCode).To(Equal(0))
+			Expect(result.stdout).To(ContainSubstring("# Test Results Diff"))
+			Expect(result.stdout).To(ContainSubstring("1 → 1 test, 1 newly failing"))
+		})
+
+		It("errors when no test results match", func() {
+			result := runCaptain(captainArgs{
+				args: []string{
+					"diff",
+					"fixtures/integration-tests/does-not-exist.json",
+					"fixtures/integration-tests/rspec-passed.json",
+				},
+				env: make(map[string]string),
+			})
+
+			Expect(result.exitCode).To(Equal(1))
+			Expect(result.stderr).To(ContainSubstring(
+				`Error: No test results files match "fixtures/integration-tests/does-not-exist.json"`,
+			))
+		})
+
+		It("errors on unsupported formats", func() {
+			result := runCaptain(captainArgs{
+				args: []string{
+					"diff",
+					"--format", "html",
+					"fixtures/integration-tests/rspec-passed.json",
+					"fixtures/integration-tests/rspec-quarantine.json",
+				},
+				env: make(map[string]string),
+			})
+
+			Expect(result.exitCode).To(Equal(1))
+			Expect(result.stderr).To(ContainSubstring("Error: Unsupported diff format"))
+		})
+	})
+
 	Describe("captain [add|remove]", func() {
 		actionBuilder := func(resource string, suiteID string) func(string) (captainResult, string) {
 			read := func(path string) string {